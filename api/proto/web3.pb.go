@@ -70,7 +70,13 @@ func (x *GetBlockHeightRequest) GetChainId() int64 {
 type GetBlockHeightResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ChainId       int64                  `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
-	Height        int64                  `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+	Height        int64                  `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"` // 最新高度 (与 latest 相同，保留兼容)
+	Latest        uint64                 `protobuf:"varint,3,opt,name=latest,proto3" json:"latest,omitempty"`
+	Safe          uint64                 `protobuf:"varint,4,opt,name=safe,proto3" json:"safe,omitempty"`
+	Finalized     uint64                 `protobuf:"varint,5,opt,name=finalized,proto3" json:"finalized,omitempty"`
+	Confirmed     uint64                 `protobuf:"varint,6,opt,name=confirmed,proto3" json:"confirmed,omitempty"`         // latest - confirmations
+	Confirmations uint64                 `protobuf:"varint,7,opt,name=confirmations,proto3" json:"confirmations,omitempty"` // 该链配置的确认数
+	Source        string                 `protobuf:"bytes,8,opt,name=source,proto3" json:"source,omitempty"`                // safe / finalized 的来源: tag / confirmations
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -119,18 +125,1458 @@ func (x *GetBlockHeightResponse) GetHeight() int64 {
 	return 0
 }
 
+func (x *GetBlockHeightResponse) GetLatest() uint64 {
+	if x != nil {
+		return x.Latest
+	}
+	return 0
+}
+
+func (x *GetBlockHeightResponse) GetSafe() uint64 {
+	if x != nil {
+		return x.Safe
+	}
+	return 0
+}
+
+func (x *GetBlockHeightResponse) GetFinalized() uint64 {
+	if x != nil {
+		return x.Finalized
+	}
+	return 0
+}
+
+func (x *GetBlockHeightResponse) GetConfirmed() uint64 {
+	if x != nil {
+		return x.Confirmed
+	}
+	return 0
+}
+
+func (x *GetBlockHeightResponse) GetConfirmations() uint64 {
+	if x != nil {
+		return x.Confirmations
+	}
+	return 0
+}
+
+func (x *GetBlockHeightResponse) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+// 余额查询参数
+type GetBalanceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainId       int64                  `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Tokens        []string               `protobuf:"bytes,3,rep,name=tokens,proto3" json:"tokens,omitempty"`  // ERC-20 合约地址，为空只查原生币 (最多 100 个)
+	Block         string                 `protobuf:"bytes,4,opt,name=block,proto3" json:"block,omitempty"`    // 区块号 (十进制 / 0x 十六进制) 或 latest / pending / safe / finalized / earliest，默认 latest
+	Quorum        bool                   `protobuf:"varint,5,opt,name=quorum,proto3" json:"quorum,omitempty"` // 仲裁读：多个独立服务商一致才返回，否则 UNAVAILABLE
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalanceRequest) Reset() {
+	*x = GetBalanceRequest{}
+	mi := &file_api_proto_web3_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceRequest) ProtoMessage() {}
+
+func (x *GetBalanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_web3_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceRequest.ProtoReflect.Descriptor instead.
+func (*GetBalanceRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_web3_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetBalanceRequest) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *GetBalanceRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *GetBalanceRequest) GetTokens() []string {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+func (x *GetBalanceRequest) GetBlock() string {
+	if x != nil {
+		return x.Block
+	}
+	return ""
+}
+
+func (x *GetBalanceRequest) GetQuorum() bool {
+	if x != nil {
+		return x.Quorum
+	}
+	return false
+}
+
+// 一种资产的余额
+type Balance struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"` // ERC-20 合约地址，原生币为空
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Symbol        string                 `protobuf:"bytes,3,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Decimals      uint32                 `protobuf:"varint,4,opt,name=decimals,proto3" json:"decimals,omitempty"`
+	Raw           string                 `protobuf:"bytes,5,opt,name=raw,proto3" json:"raw,omitempty"`             // 最小单位余额 (十进制字符串)
+	Formatted     string                 `protobuf:"bytes,6,opt,name=formatted,proto3" json:"formatted,omitempty"` // 按 decimals 换算后的余额
+	Error         string                 `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`         // 该 Token 查询失败的原因
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Balance) Reset() {
+	*x = Balance{}
+	mi := &file_api_proto_web3_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Balance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Balance) ProtoMessage() {}
+
+func (x *Balance) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_web3_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Balance.ProtoReflect.Descriptor instead.
+func (*Balance) Descriptor() ([]byte, []int) {
+	return file_api_proto_web3_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Balance) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *Balance) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Balance) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Balance) GetDecimals() uint32 {
+	if x != nil {
+		return x.Decimals
+	}
+	return 0
+}
+
+func (x *Balance) GetRaw() string {
+	if x != nil {
+		return x.Raw
+	}
+	return ""
+}
+
+func (x *Balance) GetFormatted() string {
+	if x != nil {
+		return x.Formatted
+	}
+	return ""
+}
+
+func (x *Balance) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// 余额查询结果
+type GetBalanceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainId       int64                  `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Block         string                 `protobuf:"bytes,3,opt,name=block,proto3" json:"block,omitempty"`
+	Native        *Balance               `protobuf:"bytes,4,opt,name=native,proto3" json:"native,omitempty"`
+	Tokens        []*Balance             `protobuf:"bytes,5,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalanceResponse) Reset() {
+	*x = GetBalanceResponse{}
+	mi := &file_api_proto_web3_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceResponse) ProtoMessage() {}
+
+func (x *GetBalanceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_web3_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceResponse.ProtoReflect.Descriptor instead.
+func (*GetBalanceResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_web3_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetBalanceResponse) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *GetBalanceResponse) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *GetBalanceResponse) GetBlock() string {
+	if x != nil {
+		return x.Block
+	}
+	return ""
+}
+
+func (x *GetBalanceResponse) GetNative() *Balance {
+	if x != nil {
+		return x.Native
+	}
+	return nil
+}
+
+func (x *GetBalanceResponse) GetTokens() []*Balance {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+// 交易查询参数
+type GetTransactionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainId       int64                  `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Hash          string                 `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTransactionRequest) Reset() {
+	*x = GetTransactionRequest{}
+	mi := &file_api_proto_web3_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTransactionRequest) ProtoMessage() {}
+
+func (x *GetTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_web3_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTransactionRequest.ProtoReflect.Descriptor instead.
+func (*GetTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_web3_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetTransactionRequest) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *GetTransactionRequest) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+// 交易
+type Transaction struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	ChainId              int64                  `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Hash                 string                 `protobuf:"bytes,2,opt,name=hash,proto3" json:"hash,omitempty"`
+	Type                 uint32                 `protobuf:"varint,3,opt,name=type,proto3" json:"type,omitempty"`
+	From                 string                 `protobuf:"bytes,4,opt,name=from,proto3" json:"from,omitempty"`
+	To                   string                 `protobuf:"bytes,5,opt,name=to,proto3" json:"to,omitempty"` // 创建合约时为空
+	Nonce                uint64                 `protobuf:"varint,6,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	Value                string                 `protobuf:"bytes,7,opt,name=value,proto3" json:"value,omitempty"` // wei (十进制字符串)
+	Input                string                 `protobuf:"bytes,8,opt,name=input,proto3" json:"input,omitempty"` // 0x 十六进制
+	Gas                  uint64                 `protobuf:"varint,9,opt,name=gas,proto3" json:"gas,omitempty"`
+	GasPrice             string                 `protobuf:"bytes,10,opt,name=gas_price,json=gasPrice,proto3" json:"gas_price,omitempty"`                                           // legacy / access list 交易
+	MaxFeePerGas         string                 `protobuf:"bytes,11,opt,name=max_fee_per_gas,json=maxFeePerGas,proto3" json:"max_fee_per_gas,omitempty"`                           // EIP-1559 及之后的交易
+	MaxPriorityFeePerGas string                 `protobuf:"bytes,12,opt,name=max_priority_fee_per_gas,json=maxPriorityFeePerGas,proto3" json:"max_priority_fee_per_gas,omitempty"` // EIP-1559 及之后的交易
+	Pending              bool                   `protobuf:"varint,13,opt,name=pending,proto3" json:"pending,omitempty"`
+	Receipt              *Receipt               `protobuf:"bytes,14,opt,name=receipt,proto3" json:"receipt,omitempty"` // 未上链时为空
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Transaction) Reset() {
+	*x = Transaction{}
+	mi := &file_api_proto_web3_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Transaction) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Transaction) ProtoMessage() {}
+
+func (x *Transaction) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_web3_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Transaction.ProtoReflect.Descriptor instead.
+func (*Transaction) Descriptor() ([]byte, []int) {
+	return file_api_proto_web3_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Transaction) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *Transaction) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Transaction) GetType() uint32 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+func (x *Transaction) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *Transaction) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *Transaction) GetNonce() uint64 {
+	if x != nil {
+		return x.Nonce
+	}
+	return 0
+}
+
+func (x *Transaction) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Transaction) GetInput() string {
+	if x != nil {
+		return x.Input
+	}
+	return ""
+}
+
+func (x *Transaction) GetGas() uint64 {
+	if x != nil {
+		return x.Gas
+	}
+	return 0
+}
+
+func (x *Transaction) GetGasPrice() string {
+	if x != nil {
+		return x.GasPrice
+	}
+	return ""
+}
+
+func (x *Transaction) GetMaxFeePerGas() string {
+	if x != nil {
+		return x.MaxFeePerGas
+	}
+	return ""
+}
+
+func (x *Transaction) GetMaxPriorityFeePerGas() string {
+	if x != nil {
+		return x.MaxPriorityFeePerGas
+	}
+	return ""
+}
+
+func (x *Transaction) GetPending() bool {
+	if x != nil {
+		return x.Pending
+	}
+	return false
+}
+
+func (x *Transaction) GetReceipt() *Receipt {
+	if x != nil {
+		return x.Receipt
+	}
+	return nil
+}
+
+// 交易回执
+type Receipt struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	TxHash            string                 `protobuf:"bytes,1,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	Status            uint64                 `protobuf:"varint,2,opt,name=status,proto3" json:"status,omitempty"` // 1 成功，0 失败
+	Success           bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	BlockNumber       uint64                 `protobuf:"varint,4,opt,name=block_number,json=blockNumber,proto3" json:"block_number,omitempty"`
+	BlockHash         string                 `protobuf:"bytes,5,opt,name=block_hash,json=blockHash,proto3" json:"block_hash,omitempty"`
+	TransactionIndex  uint32                 `protobuf:"varint,6,opt,name=transaction_index,json=transactionIndex,proto3" json:"transaction_index,omitempty"`
+	GasUsed           uint64                 `protobuf:"varint,7,opt,name=gas_used,json=gasUsed,proto3" json:"gas_used,omitempty"`
+	CumulativeGasUsed uint64                 `protobuf:"varint,8,opt,name=cumulative_gas_used,json=cumulativeGasUsed,proto3" json:"cumulative_gas_used,omitempty"`
+	EffectiveGasPrice string                 `protobuf:"bytes,9,opt,name=effective_gas_price,json=effectiveGasPrice,proto3" json:"effective_gas_price,omitempty"`
+	BlobGasUsed       uint64                 `protobuf:"varint,10,opt,name=blob_gas_used,json=blobGasUsed,proto3" json:"blob_gas_used,omitempty"`
+	BlobGasPrice      string                 `protobuf:"bytes,11,opt,name=blob_gas_price,json=blobGasPrice,proto3" json:"blob_gas_price,omitempty"`
+	Fee               string                 `protobuf:"bytes,12,opt,name=fee,proto3" json:"fee,omitempty"` // wei (十进制字符串)
+	ContractAddress   string                 `protobuf:"bytes,13,opt,name=contract_address,json=contractAddress,proto3" json:"contract_address,omitempty"`
+	Logs              []*Log                 `protobuf:"bytes,14,rep,name=logs,proto3" json:"logs,omitempty"`
+	Confirmations     uint64                 `protobuf:"varint,15,opt,name=confirmations,proto3" json:"confirmations,omitempty"` // 含所在区块，刚上链为 1
+	Finalized         bool                   `protobuf:"varint,16,opt,name=finalized,proto3" json:"finalized,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Receipt) Reset() {
+	*x = Receipt{}
+	mi := &file_api_proto_web3_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Receipt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Receipt) ProtoMessage() {}
+
+func (x *Receipt) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_web3_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Receipt.ProtoReflect.Descriptor instead.
+func (*Receipt) Descriptor() ([]byte, []int) {
+	return file_api_proto_web3_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Receipt) GetTxHash() string {
+	if x != nil {
+		return x.TxHash
+	}
+	return ""
+}
+
+func (x *Receipt) GetStatus() uint64 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+func (x *Receipt) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *Receipt) GetBlockNumber() uint64 {
+	if x != nil {
+		return x.BlockNumber
+	}
+	return 0
+}
+
+func (x *Receipt) GetBlockHash() string {
+	if x != nil {
+		return x.BlockHash
+	}
+	return ""
+}
+
+func (x *Receipt) GetTransactionIndex() uint32 {
+	if x != nil {
+		return x.TransactionIndex
+	}
+	return 0
+}
+
+func (x *Receipt) GetGasUsed() uint64 {
+	if x != nil {
+		return x.GasUsed
+	}
+	return 0
+}
+
+func (x *Receipt) GetCumulativeGasUsed() uint64 {
+	if x != nil {
+		return x.CumulativeGasUsed
+	}
+	return 0
+}
+
+func (x *Receipt) GetEffectiveGasPrice() string {
+	if x != nil {
+		return x.EffectiveGasPrice
+	}
+	return ""
+}
+
+func (x *Receipt) GetBlobGasUsed() uint64 {
+	if x != nil {
+		return x.BlobGasUsed
+	}
+	return 0
+}
+
+func (x *Receipt) GetBlobGasPrice() string {
+	if x != nil {
+		return x.BlobGasPrice
+	}
+	return ""
+}
+
+func (x *Receipt) GetFee() string {
+	if x != nil {
+		return x.Fee
+	}
+	return ""
+}
+
+func (x *Receipt) GetContractAddress() string {
+	if x != nil {
+		return x.ContractAddress
+	}
+	return ""
+}
+
+func (x *Receipt) GetLogs() []*Log {
+	if x != nil {
+		return x.Logs
+	}
+	return nil
+}
+
+func (x *Receipt) GetConfirmations() uint64 {
+	if x != nil {
+		return x.Confirmations
+	}
+	return 0
+}
+
+func (x *Receipt) GetFinalized() bool {
+	if x != nil {
+		return x.Finalized
+	}
+	return false
+}
+
+// 事件日志
+type Log struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         uint32                 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Topics        []string               `protobuf:"bytes,3,rep,name=topics,proto3" json:"topics,omitempty"`
+	Data          string                 `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	Removed       bool                   `protobuf:"varint,5,opt,name=removed,proto3" json:"removed,omitempty"`
+	Decoded       *DecodedLog            `protobuf:"bytes,6,opt,name=decoded,proto3" json:"decoded,omitempty"` // 没有匹配的 ABI 时为空
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Log) Reset() {
+	*x = Log{}
+	mi := &file_api_proto_web3_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Log) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Log) ProtoMessage() {}
+
+func (x *Log) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_web3_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Log.ProtoReflect.Descriptor instead.
+func (*Log) Descriptor() ([]byte, []int) {
+	return file_api_proto_web3_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Log) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *Log) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Log) GetTopics() []string {
+	if x != nil {
+		return x.Topics
+	}
+	return nil
+}
+
+func (x *Log) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *Log) GetRemoved() bool {
+	if x != nil {
+		return x.Removed
+	}
+	return false
+}
+
+func (x *Log) GetDecoded() *DecodedLog {
+	if x != nil {
+		return x.Decoded
+	}
+	return nil
+}
+
+// 按 contracts 配置中的 ABI 解码的事件
+type DecodedLog struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Contract      string                 `protobuf:"bytes,1,opt,name=contract,proto3" json:"contract,omitempty"`
+	Event         string                 `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"`
+	Signature     string                 `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+	ArgsJson      string                 `protobuf:"bytes,4,opt,name=args_json,json=argsJson,proto3" json:"args_json,omitempty"` // 参数名 -> 值 的 JSON 对象
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DecodedLog) Reset() {
+	*x = DecodedLog{}
+	mi := &file_api_proto_web3_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecodedLog) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecodedLog) ProtoMessage() {}
+
+func (x *DecodedLog) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_web3_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecodedLog.ProtoReflect.Descriptor instead.
+func (*DecodedLog) Descriptor() ([]byte, []int) {
+	return file_api_proto_web3_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DecodedLog) GetContract() string {
+	if x != nil {
+		return x.Contract
+	}
+	return ""
+}
+
+func (x *DecodedLog) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *DecodedLog) GetSignature() string {
+	if x != nil {
+		return x.Signature
+	}
+	return ""
+}
+
+func (x *DecodedLog) GetArgsJson() string {
+	if x != nil {
+		return x.ArgsJson
+	}
+	return ""
+}
+
+// 区块查询参数
+type GetBlockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainId       int64                  `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Block         string                 `protobuf:"bytes,2,opt,name=block,proto3" json:"block,omitempty"`                        // 区块号 (十进制 / 0x 十六进制)、区块哈希或 latest / pending / safe / finalized / earliest，默认 latest
+	FullTx        bool                   `protobuf:"varint,3,opt,name=full_tx,json=fullTx,proto3" json:"full_tx,omitempty"`       // 返回完整交易，否则只返回交易哈希
+	Page          int32                  `protobuf:"varint,4,opt,name=page,proto3" json:"page,omitempty"`                         // 页码，从 1 开始
+	PageSize      int32                  `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"` // 每页条数 (最多 1000)，0 表示不分页
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBlockRequest) Reset() {
+	*x = GetBlockRequest{}
+	mi := &file_api_proto_web3_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlockRequest) ProtoMessage() {}
+
+func (x *GetBlockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_web3_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlockRequest.ProtoReflect.Descriptor instead.
+func (*GetBlockRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_web3_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetBlockRequest) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *GetBlockRequest) GetBlock() string {
+	if x != nil {
+		return x.Block
+	}
+	return ""
+}
+
+func (x *GetBlockRequest) GetFullTx() bool {
+	if x != nil {
+		return x.FullTx
+	}
+	return false
+}
+
+func (x *GetBlockRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *GetBlockRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+// 区块
+type Block struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainId       int64                  `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Number        uint64                 `protobuf:"varint,2,opt,name=number,proto3" json:"number,omitempty"`
+	Hash          string                 `protobuf:"bytes,3,opt,name=hash,proto3" json:"hash,omitempty"`
+	ParentHash    string                 `protobuf:"bytes,4,opt,name=parent_hash,json=parentHash,proto3" json:"parent_hash,omitempty"`
+	Timestamp     uint64                 `protobuf:"varint,5,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Miner         string                 `protobuf:"bytes,6,opt,name=miner,proto3" json:"miner,omitempty"`
+	GasUsed       uint64                 `protobuf:"varint,7,opt,name=gas_used,json=gasUsed,proto3" json:"gas_used,omitempty"`
+	GasLimit      uint64                 `protobuf:"varint,8,opt,name=gas_limit,json=gasLimit,proto3" json:"gas_limit,omitempty"`
+	BaseFeePerGas string                 `protobuf:"bytes,9,opt,name=base_fee_per_gas,json=baseFeePerGas,proto3" json:"base_fee_per_gas,omitempty"`       // London 之后
+	BlobGasUsed   *uint64                `protobuf:"varint,10,opt,name=blob_gas_used,json=blobGasUsed,proto3,oneof" json:"blob_gas_used,omitempty"`       // Cancun 之后
+	ExcessBlobGas *uint64                `protobuf:"varint,11,opt,name=excess_blob_gas,json=excessBlobGas,proto3,oneof" json:"excess_blob_gas,omitempty"` // Cancun 之后
+	Size          uint64                 `protobuf:"varint,12,opt,name=size,proto3" json:"size,omitempty"`
+	TxCount       int32                  `protobuf:"varint,13,opt,name=tx_count,json=txCount,proto3" json:"tx_count,omitempty"`
+	Confirmations uint64                 `protobuf:"varint,14,opt,name=confirmations,proto3" json:"confirmations,omitempty"`
+	Finalized     bool                   `protobuf:"varint,15,opt,name=finalized,proto3" json:"finalized,omitempty"`
+	Page          int32                  `protobuf:"varint,16,opt,name=page,proto3" json:"page,omitempty"`
+	PageSize      int32                  `protobuf:"varint,17,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	TxHashes      []string               `protobuf:"bytes,18,rep,name=tx_hashes,json=txHashes,proto3" json:"tx_hashes,omitempty"` // full_tx 为 false 时
+	Transactions  []*Transaction         `protobuf:"bytes,19,rep,name=transactions,proto3" json:"transactions,omitempty"`         // full_tx 为 true 时
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Block) Reset() {
+	*x = Block{}
+	mi := &file_api_proto_web3_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Block) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Block) ProtoMessage() {}
+
+func (x *Block) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_web3_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Block.ProtoReflect.Descriptor instead.
+func (*Block) Descriptor() ([]byte, []int) {
+	return file_api_proto_web3_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Block) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *Block) GetNumber() uint64 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *Block) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *Block) GetParentHash() string {
+	if x != nil {
+		return x.ParentHash
+	}
+	return ""
+}
+
+func (x *Block) GetTimestamp() uint64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *Block) GetMiner() string {
+	if x != nil {
+		return x.Miner
+	}
+	return ""
+}
+
+func (x *Block) GetGasUsed() uint64 {
+	if x != nil {
+		return x.GasUsed
+	}
+	return 0
+}
+
+func (x *Block) GetGasLimit() uint64 {
+	if x != nil {
+		return x.GasLimit
+	}
+	return 0
+}
+
+func (x *Block) GetBaseFeePerGas() string {
+	if x != nil {
+		return x.BaseFeePerGas
+	}
+	return ""
+}
+
+func (x *Block) GetBlobGasUsed() uint64 {
+	if x != nil && x.BlobGasUsed != nil {
+		return *x.BlobGasUsed
+	}
+	return 0
+}
+
+func (x *Block) GetExcessBlobGas() uint64 {
+	if x != nil && x.ExcessBlobGas != nil {
+		return *x.ExcessBlobGas
+	}
+	return 0
+}
+
+func (x *Block) GetSize() uint64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Block) GetTxCount() int32 {
+	if x != nil {
+		return x.TxCount
+	}
+	return 0
+}
+
+func (x *Block) GetConfirmations() uint64 {
+	if x != nil {
+		return x.Confirmations
+	}
+	return 0
+}
+
+func (x *Block) GetFinalized() bool {
+	if x != nil {
+		return x.Finalized
+	}
+	return false
+}
+
+func (x *Block) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+func (x *Block) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *Block) GetTxHashes() []string {
+	if x != nil {
+		return x.TxHashes
+	}
+	return nil
+}
+
+func (x *Block) GetTransactions() []*Transaction {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+// 只读方法调用参数
+type CallContractRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainId       int64                  `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Contract      string                 `protobuf:"bytes,2,opt,name=contract,proto3" json:"contract,omitempty"`                 // contracts 配置中的别名
+	Method        string                 `protobuf:"bytes,3,opt,name=method,proto3" json:"method,omitempty"`                     // ABI 方法名 (重载方法带序号，如 foo0)
+	ArgsJson      string                 `protobuf:"bytes,4,opt,name=args_json,json=argsJson,proto3" json:"args_json,omitempty"` // 参数 JSON 数组，如 ["0x...", "1000"]
+	Block         string                 `protobuf:"bytes,5,opt,name=block,proto3" json:"block,omitempty"`                       // 区块号或标签，默认 latest
+	Quorum        bool                   `protobuf:"varint,6,opt,name=quorum,proto3" json:"quorum,omitempty"`                    // 仲裁读：多个独立服务商一致才返回，否则 UNAVAILABLE
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallContractRequest) Reset() {
+	*x = CallContractRequest{}
+	mi := &file_api_proto_web3_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallContractRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallContractRequest) ProtoMessage() {}
+
+func (x *CallContractRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_web3_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallContractRequest.ProtoReflect.Descriptor instead.
+func (*CallContractRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_web3_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CallContractRequest) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *CallContractRequest) GetContract() string {
+	if x != nil {
+		return x.Contract
+	}
+	return ""
+}
+
+func (x *CallContractRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *CallContractRequest) GetArgsJson() string {
+	if x != nil {
+		return x.ArgsJson
+	}
+	return ""
+}
+
+func (x *CallContractRequest) GetBlock() string {
+	if x != nil {
+		return x.Block
+	}
+	return ""
+}
+
+func (x *CallContractRequest) GetQuorum() bool {
+	if x != nil {
+		return x.Quorum
+	}
+	return false
+}
+
+// 按 ABI 解码的返回值
+type ContractOutput struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	ValueJson     string                 `protobuf:"bytes,3,opt,name=value_json,json=valueJson,proto3" json:"value_json,omitempty"` // 值的 JSON (大整数为十进制字符串，地址 / 字节为 0x 十六进制，tuple 为对象)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ContractOutput) Reset() {
+	*x = ContractOutput{}
+	mi := &file_api_proto_web3_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ContractOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ContractOutput) ProtoMessage() {}
+
+func (x *ContractOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_web3_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ContractOutput.ProtoReflect.Descriptor instead.
+func (*ContractOutput) Descriptor() ([]byte, []int) {
+	return file_api_proto_web3_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ContractOutput) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ContractOutput) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ContractOutput) GetValueJson() string {
+	if x != nil {
+		return x.ValueJson
+	}
+	return ""
+}
+
+// 只读方法调用结果
+type CallContractResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainId       int64                  `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	Contract      string                 `protobuf:"bytes,2,opt,name=contract,proto3" json:"contract,omitempty"`
+	Address       string                 `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	Method        string                 `protobuf:"bytes,4,opt,name=method,proto3" json:"method,omitempty"`
+	Block         string                 `protobuf:"bytes,5,opt,name=block,proto3" json:"block,omitempty"`
+	Outputs       []*ContractOutput      `protobuf:"bytes,6,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallContractResponse) Reset() {
+	*x = CallContractResponse{}
+	mi := &file_api_proto_web3_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallContractResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallContractResponse) ProtoMessage() {}
+
+func (x *CallContractResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_web3_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallContractResponse.ProtoReflect.Descriptor instead.
+func (*CallContractResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_web3_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CallContractResponse) GetChainId() int64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *CallContractResponse) GetContract() string {
+	if x != nil {
+		return x.Contract
+	}
+	return ""
+}
+
+func (x *CallContractResponse) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *CallContractResponse) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *CallContractResponse) GetBlock() string {
+	if x != nil {
+		return x.Block
+	}
+	return ""
+}
+
+func (x *CallContractResponse) GetOutputs() []*ContractOutput {
+	if x != nil {
+		return x.Outputs
+	}
+	return nil
+}
+
 var File_api_proto_web3_proto protoreflect.FileDescriptor
 
 const file_api_proto_web3_proto_rawDesc = "" +
 	"\n" +
 	"\x14api/proto/web3.proto\x12\x05proto\"2\n" +
 	"\x15GetBlockHeightRequest\x12\x19\n" +
-	"\bchain_id\x18\x01 \x01(\x03R\achainId\"K\n" +
+	"\bchain_id\x18\x01 \x01(\x03R\achainId\"\xf1\x01\n" +
 	"\x16GetBlockHeightResponse\x12\x19\n" +
 	"\bchain_id\x18\x01 \x01(\x03R\achainId\x12\x16\n" +
-	"\x06height\x18\x02 \x01(\x03R\x06height2\\\n" +
+	"\x06height\x18\x02 \x01(\x03R\x06height\x12\x16\n" +
+	"\x06latest\x18\x03 \x01(\x04R\x06latest\x12\x12\n" +
+	"\x04safe\x18\x04 \x01(\x04R\x04safe\x12\x1c\n" +
+	"\tfinalized\x18\x05 \x01(\x04R\tfinalized\x12\x1c\n" +
+	"\tconfirmed\x18\x06 \x01(\x04R\tconfirmed\x12$\n" +
+	"\rconfirmations\x18\a \x01(\x04R\rconfirmations\x12\x16\n" +
+	"\x06source\x18\b \x01(\tR\x06source\"\x8e\x01\n" +
+	"\x11GetBalanceRequest\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\x03R\achainId\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x16\n" +
+	"\x06tokens\x18\x03 \x03(\tR\x06tokens\x12\x14\n" +
+	"\x05block\x18\x04 \x01(\tR\x05block\x12\x16\n" +
+	"\x06quorum\x18\x05 \x01(\bR\x06quorum\"\xad\x01\n" +
+	"\aBalance\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x16\n" +
+	"\x06symbol\x18\x03 \x01(\tR\x06symbol\x12\x1a\n" +
+	"\bdecimals\x18\x04 \x01(\rR\bdecimals\x12\x10\n" +
+	"\x03raw\x18\x05 \x01(\tR\x03raw\x12\x1c\n" +
+	"\tformatted\x18\x06 \x01(\tR\tformatted\x12\x14\n" +
+	"\x05error\x18\a \x01(\tR\x05error\"\xaf\x01\n" +
+	"\x12GetBalanceResponse\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\x03R\achainId\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x14\n" +
+	"\x05block\x18\x03 \x01(\tR\x05block\x12&\n" +
+	"\x06native\x18\x04 \x01(\v2\x0e.proto.BalanceR\x06native\x12&\n" +
+	"\x06tokens\x18\x05 \x03(\v2\x0e.proto.BalanceR\x06tokens\"F\n" +
+	"\x15GetTransactionRequest\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\x03R\achainId\x12\x12\n" +
+	"\x04hash\x18\x02 \x01(\tR\x04hash\"\x88\x03\n" +
+	"\vTransaction\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\x03R\achainId\x12\x12\n" +
+	"\x04hash\x18\x02 \x01(\tR\x04hash\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\rR\x04type\x12\x12\n" +
+	"\x04from\x18\x04 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x05 \x01(\tR\x02to\x12\x14\n" +
+	"\x05nonce\x18\x06 \x01(\x04R\x05nonce\x12\x14\n" +
+	"\x05value\x18\a \x01(\tR\x05value\x12\x14\n" +
+	"\x05input\x18\b \x01(\tR\x05input\x12\x10\n" +
+	"\x03gas\x18\t \x01(\x04R\x03gas\x12\x1b\n" +
+	"\tgas_price\x18\n" +
+	" \x01(\tR\bgasPrice\x12%\n" +
+	"\x0fmax_fee_per_gas\x18\v \x01(\tR\fmaxFeePerGas\x126\n" +
+	"\x18max_priority_fee_per_gas\x18\f \x01(\tR\x14maxPriorityFeePerGas\x12\x18\n" +
+	"\apending\x18\r \x01(\bR\apending\x12(\n" +
+	"\areceipt\x18\x0e \x01(\v2\x0e.proto.ReceiptR\areceipt\"\xa9\x04\n" +
+	"\aReceipt\x12\x17\n" +
+	"\atx_hash\x18\x01 \x01(\tR\x06txHash\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\x04R\x06status\x12\x18\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess\x12!\n" +
+	"\fblock_number\x18\x04 \x01(\x04R\vblockNumber\x12\x1d\n" +
+	"\n" +
+	"block_hash\x18\x05 \x01(\tR\tblockHash\x12+\n" +
+	"\x11transaction_index\x18\x06 \x01(\rR\x10transactionIndex\x12\x19\n" +
+	"\bgas_used\x18\a \x01(\x04R\agasUsed\x12.\n" +
+	"\x13cumulative_gas_used\x18\b \x01(\x04R\x11cumulativeGasUsed\x12.\n" +
+	"\x13effective_gas_price\x18\t \x01(\tR\x11effectiveGasPrice\x12\"\n" +
+	"\rblob_gas_used\x18\n" +
+	" \x01(\x04R\vblobGasUsed\x12$\n" +
+	"\x0eblob_gas_price\x18\v \x01(\tR\fblobGasPrice\x12\x10\n" +
+	"\x03fee\x18\f \x01(\tR\x03fee\x12)\n" +
+	"\x10contract_address\x18\r \x01(\tR\x0fcontractAddress\x12\x1e\n" +
+	"\x04logs\x18\x0e \x03(\v2\n" +
+	".proto.LogR\x04logs\x12$\n" +
+	"\rconfirmations\x18\x0f \x01(\x04R\rconfirmations\x12\x1c\n" +
+	"\tfinalized\x18\x10 \x01(\bR\tfinalized\"\xa8\x01\n" +
+	"\x03Log\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\rR\x05index\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x16\n" +
+	"\x06topics\x18\x03 \x03(\tR\x06topics\x12\x12\n" +
+	"\x04data\x18\x04 \x01(\tR\x04data\x12\x18\n" +
+	"\aremoved\x18\x05 \x01(\bR\aremoved\x12+\n" +
+	"\adecoded\x18\x06 \x01(\v2\x11.proto.DecodedLogR\adecoded\"y\n" +
+	"\n" +
+	"DecodedLog\x12\x1a\n" +
+	"\bcontract\x18\x01 \x01(\tR\bcontract\x12\x14\n" +
+	"\x05event\x18\x02 \x01(\tR\x05event\x12\x1c\n" +
+	"\tsignature\x18\x03 \x01(\tR\tsignature\x12\x1b\n" +
+	"\targs_json\x18\x04 \x01(\tR\bargsJson\"\x8c\x01\n" +
+	"\x0fGetBlockRequest\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\x03R\achainId\x12\x14\n" +
+	"\x05block\x18\x02 \x01(\tR\x05block\x12\x17\n" +
+	"\afull_tx\x18\x03 \x01(\bR\x06fullTx\x12\x12\n" +
+	"\x04page\x18\x04 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x05 \x01(\x05R\bpageSize\"\xf9\x04\n" +
+	"\x05Block\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\x03R\achainId\x12\x16\n" +
+	"\x06number\x18\x02 \x01(\x04R\x06number\x12\x12\n" +
+	"\x04hash\x18\x03 \x01(\tR\x04hash\x12\x1f\n" +
+	"\vparent_hash\x18\x04 \x01(\tR\n" +
+	"parentHash\x12\x1c\n" +
+	"\ttimestamp\x18\x05 \x01(\x04R\ttimestamp\x12\x14\n" +
+	"\x05miner\x18\x06 \x01(\tR\x05miner\x12\x19\n" +
+	"\bgas_used\x18\a \x01(\x04R\agasUsed\x12\x1b\n" +
+	"\tgas_limit\x18\b \x01(\x04R\bgasLimit\x12'\n" +
+	"\x10base_fee_per_gas\x18\t \x01(\tR\rbaseFeePerGas\x12'\n" +
+	"\rblob_gas_used\x18\n" +
+	" \x01(\x04H\x00R\vblobGasUsed\x88\x01\x01\x12+\n" +
+	"\x0fexcess_blob_gas\x18\v \x01(\x04H\x01R\rexcessBlobGas\x88\x01\x01\x12\x12\n" +
+	"\x04size\x18\f \x01(\x04R\x04size\x12\x19\n" +
+	"\btx_count\x18\r \x01(\x05R\atxCount\x12$\n" +
+	"\rconfirmations\x18\x0e \x01(\x04R\rconfirmations\x12\x1c\n" +
+	"\tfinalized\x18\x0f \x01(\bR\tfinalized\x12\x12\n" +
+	"\x04page\x18\x10 \x01(\x05R\x04page\x12\x1b\n" +
+	"\tpage_size\x18\x11 \x01(\x05R\bpageSize\x12\x1b\n" +
+	"\ttx_hashes\x18\x12 \x03(\tR\btxHashes\x126\n" +
+	"\ftransactions\x18\x13 \x03(\v2\x12.proto.TransactionR\ftransactionsB\x10\n" +
+	"\x0e_blob_gas_usedB\x12\n" +
+	"\x10_excess_blob_gas\"\xaf\x01\n" +
+	"\x13CallContractRequest\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\x03R\achainId\x12\x1a\n" +
+	"\bcontract\x18\x02 \x01(\tR\bcontract\x12\x16\n" +
+	"\x06method\x18\x03 \x01(\tR\x06method\x12\x1b\n" +
+	"\targs_json\x18\x04 \x01(\tR\bargsJson\x12\x14\n" +
+	"\x05block\x18\x05 \x01(\tR\x05block\x12\x16\n" +
+	"\x06quorum\x18\x06 \x01(\bR\x06quorum\"W\n" +
+	"\x0eContractOutput\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1d\n" +
+	"\n" +
+	"value_json\x18\x03 \x01(\tR\tvalueJson\"\xc6\x01\n" +
+	"\x14CallContractResponse\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\x03R\achainId\x12\x1a\n" +
+	"\bcontract\x18\x02 \x01(\tR\bcontract\x12\x18\n" +
+	"\aaddress\x18\x03 \x01(\tR\aaddress\x12\x16\n" +
+	"\x06method\x18\x04 \x01(\tR\x06method\x12\x14\n" +
+	"\x05block\x18\x05 \x01(\tR\x05block\x12/\n" +
+	"\aoutputs\x18\x06 \x03(\v2\x15.proto.ContractOutputR\aoutputs2\xa5\x03\n" +
 	"\vWeb3Service\x12M\n" +
-	"\x0eGetBlockHeight\x12\x1c.proto.GetBlockHeightRequest\x1a\x1d.proto.GetBlockHeightResponseB7Z5github.com/zy99978455-otw/go-micro-template/api/protob\x06proto3"
+	"\x0eGetBlockHeight\x12\x1c.proto.GetBlockHeightRequest\x1a\x1d.proto.GetBlockHeightResponse\x12A\n" +
+	"\n" +
+	"GetBalance\x12\x18.proto.GetBalanceRequest\x1a\x19.proto.GetBalanceResponse\x12B\n" +
+	"\x0eGetTransaction\x12\x1c.proto.GetTransactionRequest\x1a\x12.proto.Transaction\x12E\n" +
+	"\x15GetTransactionReceipt\x12\x1c.proto.GetTransactionRequest\x1a\x0e.proto.Receipt\x120\n" +
+	"\bGetBlock\x12\x16.proto.GetBlockRequest\x1a\f.proto.Block\x12G\n" +
+	"\fCallContract\x12\x1a.proto.CallContractRequest\x1a\x1b.proto.CallContractResponseB7Z5github.com/zy99978455-otw/go-micro-template/api/protob\x06proto3"
 
 var (
 	file_api_proto_web3_proto_rawDescOnce sync.Once
@@ -144,19 +1590,49 @@ func file_api_proto_web3_proto_rawDescGZIP() []byte {
 	return file_api_proto_web3_proto_rawDescData
 }
 
-var file_api_proto_web3_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_api_proto_web3_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_api_proto_web3_proto_goTypes = []any{
 	(*GetBlockHeightRequest)(nil),  // 0: proto.GetBlockHeightRequest
 	(*GetBlockHeightResponse)(nil), // 1: proto.GetBlockHeightResponse
+	(*GetBalanceRequest)(nil),      // 2: proto.GetBalanceRequest
+	(*Balance)(nil),                // 3: proto.Balance
+	(*GetBalanceResponse)(nil),     // 4: proto.GetBalanceResponse
+	(*GetTransactionRequest)(nil),  // 5: proto.GetTransactionRequest
+	(*Transaction)(nil),            // 6: proto.Transaction
+	(*Receipt)(nil),                // 7: proto.Receipt
+	(*Log)(nil),                    // 8: proto.Log
+	(*DecodedLog)(nil),             // 9: proto.DecodedLog
+	(*GetBlockRequest)(nil),        // 10: proto.GetBlockRequest
+	(*Block)(nil),                  // 11: proto.Block
+	(*CallContractRequest)(nil),    // 12: proto.CallContractRequest
+	(*ContractOutput)(nil),         // 13: proto.ContractOutput
+	(*CallContractResponse)(nil),   // 14: proto.CallContractResponse
 }
 var file_api_proto_web3_proto_depIdxs = []int32{
-	0, // 0: proto.Web3Service.GetBlockHeight:input_type -> proto.GetBlockHeightRequest
-	1, // 1: proto.Web3Service.GetBlockHeight:output_type -> proto.GetBlockHeightResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	3,  // 0: proto.GetBalanceResponse.native:type_name -> proto.Balance
+	3,  // 1: proto.GetBalanceResponse.tokens:type_name -> proto.Balance
+	7,  // 2: proto.Transaction.receipt:type_name -> proto.Receipt
+	8,  // 3: proto.Receipt.logs:type_name -> proto.Log
+	9,  // 4: proto.Log.decoded:type_name -> proto.DecodedLog
+	6,  // 5: proto.Block.transactions:type_name -> proto.Transaction
+	13, // 6: proto.CallContractResponse.outputs:type_name -> proto.ContractOutput
+	0,  // 7: proto.Web3Service.GetBlockHeight:input_type -> proto.GetBlockHeightRequest
+	2,  // 8: proto.Web3Service.GetBalance:input_type -> proto.GetBalanceRequest
+	5,  // 9: proto.Web3Service.GetTransaction:input_type -> proto.GetTransactionRequest
+	5,  // 10: proto.Web3Service.GetTransactionReceipt:input_type -> proto.GetTransactionRequest
+	10, // 11: proto.Web3Service.GetBlock:input_type -> proto.GetBlockRequest
+	12, // 12: proto.Web3Service.CallContract:input_type -> proto.CallContractRequest
+	1,  // 13: proto.Web3Service.GetBlockHeight:output_type -> proto.GetBlockHeightResponse
+	4,  // 14: proto.Web3Service.GetBalance:output_type -> proto.GetBalanceResponse
+	6,  // 15: proto.Web3Service.GetTransaction:output_type -> proto.Transaction
+	7,  // 16: proto.Web3Service.GetTransactionReceipt:output_type -> proto.Receipt
+	11, // 17: proto.Web3Service.GetBlock:output_type -> proto.Block
+	14, // 18: proto.Web3Service.CallContract:output_type -> proto.CallContractResponse
+	13, // [13:19] is the sub-list for method output_type
+	7,  // [7:13] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_web3_proto_init() }
@@ -164,13 +1640,14 @@ func file_api_proto_web3_proto_init() {
 	if File_api_proto_web3_proto != nil {
 		return
 	}
+	file_api_proto_web3_proto_msgTypes[11].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_web3_proto_rawDesc), len(file_api_proto_web3_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,7 +19,12 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Web3Service_GetBlockHeight_FullMethodName = "/proto.Web3Service/GetBlockHeight"
+	Web3Service_GetBlockHeight_FullMethodName        = "/proto.Web3Service/GetBlockHeight"
+	Web3Service_GetBalance_FullMethodName            = "/proto.Web3Service/GetBalance"
+	Web3Service_GetTransaction_FullMethodName        = "/proto.Web3Service/GetTransaction"
+	Web3Service_GetTransactionReceipt_FullMethodName = "/proto.Web3Service/GetTransactionReceipt"
+	Web3Service_GetBlock_FullMethodName              = "/proto.Web3Service/GetBlock"
+	Web3Service_CallContract_FullMethodName          = "/proto.Web3Service/CallContract"
 )
 
 // Web3ServiceClient is the client API for Web3Service service.
@@ -28,8 +33,18 @@ const (
 //
 // 定义 Web3 服务接口
 type Web3ServiceClient interface {
-	// 定义一个方法: 输入 ChainID，返回高度
+	// 定义一个方法: 输入 ChainID，返回高度 (latest / safe / finalized / N 确认)
 	GetBlockHeight(ctx context.Context, in *GetBlockHeightRequest, opts ...grpc.CallOption) (*GetBlockHeightResponse, error)
+	// 查询地址的原生币余额，以及 (可选) 一批 ERC-20 余额
+	GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error)
+	// 按哈希查询交易及其回执 (含确认数、按 ABI 解码的日志)
+	GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*Transaction, error)
+	// 按交易哈希查询回执
+	GetTransactionReceipt(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*Receipt, error)
+	// 按区块号 / 哈希 / 标签查询区块 (交易列表可分页、可返回完整交易)
+	GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*Block, error)
+	// 按合约别名 (contracts 配置) + 方法名调用只读方法，返回按 ABI 解码的结果
+	CallContract(ctx context.Context, in *CallContractRequest, opts ...grpc.CallOption) (*CallContractResponse, error)
 }
 
 type web3ServiceClient struct {
@@ -50,14 +65,74 @@ func (c *web3ServiceClient) GetBlockHeight(ctx context.Context, in *GetBlockHeig
 	return out, nil
 }
 
+func (c *web3ServiceClient) GetBalance(ctx context.Context, in *GetBalanceRequest, opts ...grpc.CallOption) (*GetBalanceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBalanceResponse)
+	err := c.cc.Invoke(ctx, Web3Service_GetBalance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *web3ServiceClient) GetTransaction(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*Transaction, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Transaction)
+	err := c.cc.Invoke(ctx, Web3Service_GetTransaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *web3ServiceClient) GetTransactionReceipt(ctx context.Context, in *GetTransactionRequest, opts ...grpc.CallOption) (*Receipt, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Receipt)
+	err := c.cc.Invoke(ctx, Web3Service_GetTransactionReceipt_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *web3ServiceClient) GetBlock(ctx context.Context, in *GetBlockRequest, opts ...grpc.CallOption) (*Block, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Block)
+	err := c.cc.Invoke(ctx, Web3Service_GetBlock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *web3ServiceClient) CallContract(ctx context.Context, in *CallContractRequest, opts ...grpc.CallOption) (*CallContractResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CallContractResponse)
+	err := c.cc.Invoke(ctx, Web3Service_CallContract_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Web3ServiceServer is the server API for Web3Service service.
 // All implementations must embed UnimplementedWeb3ServiceServer
 // for forward compatibility.
 //
 // 定义 Web3 服务接口
 type Web3ServiceServer interface {
-	// 定义一个方法: 输入 ChainID，返回高度
+	// 定义一个方法: 输入 ChainID，返回高度 (latest / safe / finalized / N 确认)
 	GetBlockHeight(context.Context, *GetBlockHeightRequest) (*GetBlockHeightResponse, error)
+	// 查询地址的原生币余额，以及 (可选) 一批 ERC-20 余额
+	GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error)
+	// 按哈希查询交易及其回执 (含确认数、按 ABI 解码的日志)
+	GetTransaction(context.Context, *GetTransactionRequest) (*Transaction, error)
+	// 按交易哈希查询回执
+	GetTransactionReceipt(context.Context, *GetTransactionRequest) (*Receipt, error)
+	// 按区块号 / 哈希 / 标签查询区块 (交易列表可分页、可返回完整交易)
+	GetBlock(context.Context, *GetBlockRequest) (*Block, error)
+	// 按合约别名 (contracts 配置) + 方法名调用只读方法，返回按 ABI 解码的结果
+	CallContract(context.Context, *CallContractRequest) (*CallContractResponse, error)
 	mustEmbedUnimplementedWeb3ServiceServer()
 }
 
@@ -71,6 +146,21 @@ type UnimplementedWeb3ServiceServer struct{}
 func (UnimplementedWeb3ServiceServer) GetBlockHeight(context.Context, *GetBlockHeightRequest) (*GetBlockHeightResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetBlockHeight not implemented")
 }
+func (UnimplementedWeb3ServiceServer) GetBalance(context.Context, *GetBalanceRequest) (*GetBalanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalance not implemented")
+}
+func (UnimplementedWeb3ServiceServer) GetTransaction(context.Context, *GetTransactionRequest) (*Transaction, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransaction not implemented")
+}
+func (UnimplementedWeb3ServiceServer) GetTransactionReceipt(context.Context, *GetTransactionRequest) (*Receipt, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionReceipt not implemented")
+}
+func (UnimplementedWeb3ServiceServer) GetBlock(context.Context, *GetBlockRequest) (*Block, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlock not implemented")
+}
+func (UnimplementedWeb3ServiceServer) CallContract(context.Context, *CallContractRequest) (*CallContractResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CallContract not implemented")
+}
 func (UnimplementedWeb3ServiceServer) mustEmbedUnimplementedWeb3ServiceServer() {}
 func (UnimplementedWeb3ServiceServer) testEmbeddedByValue()                     {}
 
@@ -110,6 +200,96 @@ func _Web3Service_GetBlockHeight_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Web3Service_GetBalance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Web3ServiceServer).GetBalance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Web3Service_GetBalance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Web3ServiceServer).GetBalance(ctx, req.(*GetBalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Web3Service_GetTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Web3ServiceServer).GetTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Web3Service_GetTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Web3ServiceServer).GetTransaction(ctx, req.(*GetTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Web3Service_GetTransactionReceipt_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Web3ServiceServer).GetTransactionReceipt(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Web3Service_GetTransactionReceipt_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Web3ServiceServer).GetTransactionReceipt(ctx, req.(*GetTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Web3Service_GetBlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Web3ServiceServer).GetBlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Web3Service_GetBlock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Web3ServiceServer).GetBlock(ctx, req.(*GetBlockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Web3Service_CallContract_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CallContractRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(Web3ServiceServer).CallContract(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Web3Service_CallContract_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(Web3ServiceServer).CallContract(ctx, req.(*CallContractRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Web3Service_ServiceDesc is the grpc.ServiceDesc for Web3Service service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -121,6 +301,26 @@ var Web3Service_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetBlockHeight",
 			Handler:    _Web3Service_GetBlockHeight_Handler,
 		},
+		{
+			MethodName: "GetBalance",
+			Handler:    _Web3Service_GetBalance_Handler,
+		},
+		{
+			MethodName: "GetTransaction",
+			Handler:    _Web3Service_GetTransaction_Handler,
+		},
+		{
+			MethodName: "GetTransactionReceipt",
+			Handler:    _Web3Service_GetTransactionReceipt_Handler,
+		},
+		{
+			MethodName: "GetBlock",
+			Handler:    _Web3Service_GetBlock_Handler,
+		},
+		{
+			MethodName: "CallContract",
+			Handler:    _Web3Service_CallContract_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/proto/web3.proto",
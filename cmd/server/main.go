@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 
 	// 引入各层
 	"github.com/zy99978455-otw/go-micro-template/internal/data"
@@ -17,6 +18,7 @@ import (
 	"github.com/zy99978455-otw/go-micro-template/pkg/config"
 	"github.com/zy99978455-otw/go-micro-template/pkg/database"
 	"github.com/zy99978455-otw/go-micro-template/pkg/global"
+	"github.com/zy99978455-otw/go-micro-template/pkg/grpc_server"
 	"github.com/zy99978455-otw/go-micro-template/pkg/logger"
 	"github.com/zy99978455-otw/go-micro-template/pkg/register"
 )
@@ -55,15 +57,37 @@ func main() {
 	defer cleanupRedis()
 	// ================= 4. 初始化 Data 层 (依赖注入) =================
 	
-	// 4.1 先初始化 RPC Manager (传入 conf)
+	// 4.1 先初始化 RPC Manager (传入 conf)，并启动后台健康检查
+	// 关闭由 Data 层的 cleanup 负责
 	rpcMgr := data.NewRPCManager(conf)
+	rpcMgr.PersistQuota(rdb) // CU 用量写入 Redis，重启后继续累计 (Redis 未配置时只在内存中计数)
+	rpcMgr.Start(context.Background())
 
-	// 4.2 然后注入到 Data 层
-	dataModule, cleanupData, err := data.NewData(db, rdb, rpcMgr)
+	// 4.2 WSS 订阅管理器 (使用 chains[].nodes[].wss_url)
+	subMgr := data.NewSubscriptionManager(rpcMgr)
+
+	// 4.3 链数据缓存 (Redis 未配置时为 nil，直接走 RPC)
+	chainCache := data.NewChainCache(conf, rdb)
+
+	// 4.4 节点健康历史 (写入 MySQL，MySQL 未配置时为 nil)
+	nodeHistory := data.NewNodeHistory(conf, db, rpcMgr)
+	nodeHistory.Start(context.Background())
+
+	// 4.5 链头跟踪与重组检测 (重组时清理缓存，其他组件可订阅重组事件)
+	// 在 NewData 注册缓存失效回调之后才 Start，避免启动期间的重组漏掉回调
+	headTracker := data.NewHeadTracker(conf, rpcMgr, subMgr)
+
+	// 4.6 合约注册表 (解析 contracts 配置中的 ABI，校验各条链上是否有合约代码)
+	contracts := data.NewContractRegistry(conf, rpcMgr)
+	contracts.Validate(context.Background())
+
+	// 4.7 然后注入到 Data 层
+	dataModule, cleanupData, err := data.NewData(db, rdb, rpcMgr, subMgr, chainCache, nodeHistory, headTracker, contracts)
 	if err != nil {
 		global.Log.Fatalf("Data 层初始化失败: %v", err)
 	}
 	defer cleanupData()
+	headTracker.Start(context.Background())
 
 	// 验证 RPC
 	fmt.Println("------------------------------------------------")
@@ -86,7 +110,7 @@ func main() {
 	}
 
 	// 组装 Server
-	r := server.NewHTTPServer(dataModule)
+	r := server.NewHTTPServer(conf, dataModule)
 
 	httpSrv := &http.Server{
 		Addr:    fmt.Sprintf(":%d", httpPort),
@@ -99,6 +123,15 @@ func main() {
 		}
 	}()
 
+	// gRPC 服务 (server.grpc_port 未配置时不启动)
+	var grpcSrv *grpc.Server
+	if conf.Server.GrpcPort > 0 {
+		grpcSrv, err = grpc_server.Run(conf.Server.GrpcPort, server.NewGRPCServer(dataModule))
+		if err != nil {
+			global.Log.Fatalf("gRPC Server 启动失败: %v", err)
+		}
+	}
+
 	// ================= 6. 服务注册 (Consul) =================
 	// 🔥 传入 conf
 	registerToConsul(httpPort, conf)
@@ -117,6 +150,10 @@ func main() {
 	} else {
 		global.Log.Info("✅ [HTTP] 服务已停止")
 	}
+	if grpcSrv != nil {
+		grpcSrv.GracefulStop()
+		global.Log.Info("✅ [gRPC] 服务已停止")
+	}
 	global.Log.Info("👋 服务退出完成")
 }
 // registerToConsul 辅助函数
@@ -2,6 +2,10 @@ package biz
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
 	"github.com/google/wire"
 )
 
@@ -9,6 +13,20 @@ import (
 var ProviderSet = wire.NewSet(NewChainUsecase)
 
 
+// ErrInvalidArgument 请求参数错误 (地址、区块号等格式不对)，HTTP 返回 400，gRPC 返回 InvalidArgument
+var ErrInvalidArgument = errors.New("invalid argument")
+
+// ErrNotFound 查询的交易 / 区块不存在，HTTP 返回 404，gRPC 返回 NotFound
+var ErrNotFound = errors.New("not found")
+
+// ErrQuorumNotReached 仲裁读时独立服务商不足或应答不一致，HTTP 返回 503，gRPC 返回 Unavailable
+var ErrQuorumNotReached = errors.New("quorum not reached")
+
+const (
+	maxBalanceTokens = 100  // 一次批量查询最多的 Token 数
+	maxBlockPageSize = 1000 // 区块交易列表每页最多条数
+)
+
 // ChainUsecase 定义了与链交互的业务逻辑接口
 type ChainUsecase struct {
 	repo ChainRepo
@@ -17,7 +35,193 @@ type ChainUsecase struct {
 // ChainRepo 定义了数据层必须实现的方法 (依赖倒置)
 type ChainRepo interface {
 	GetBlockHeight(ctx context.Context, chainID int64) (uint64, error)
-	// 未来可以在这里加: GetBalance, SendTransaction ...
+	GetBlockHeights(ctx context.Context, chainID int64) (*BlockHeights, error)
+	// block 为空或 latest / pending / safe / finalized / earliest 标签，或十进制 / 0x 十六进制区块号
+	// quorum 为 true 时走仲裁读 (多个独立服务商一致才返回)
+	GetNativeBalance(ctx context.Context, chainID int64, address, block string, quorum bool) (*Balance, error)
+	// 单个 Token 查询失败时记录在 Balance.Error，不影响其他 Token
+	GetTokenBalances(ctx context.Context, chainID int64, address string, tokens []string, block string, quorum bool) ([]Balance, error)
+	// 交易及其回执 (未上链时 Receipt 为 nil)；不存在时返回 ErrNotFound
+	GetTransaction(ctx context.Context, chainID int64, hash string) (*Transaction, error)
+	GetReceipt(ctx context.Context, chainID int64, hash string) (*Receipt, error)
+	// 区块 (q.Block 为区块号 / 哈希 / 标签)，交易列表按 q 分页；不存在时返回 ErrNotFound
+	GetBlock(ctx context.Context, q BlockQuery) (*Block, error)
+	// 合约注册表 (contracts 配置) 中的合约，以及按别名 + 方法名调用只读方法
+	ListContracts(ctx context.Context) ([]ContractInfo, error)
+	CallContract(ctx context.Context, q ContractCallQuery) (*ContractCallResult, error)
+}
+
+// BlockHeights 各个确认级别的区块高度
+// 结算类业务应以 Finalized (或 Confirmed) 为准，Latest 可能被重组回滚
+type BlockHeights struct {
+	ChainID       int64  `json:"chain_id"`
+	Latest        uint64 `json:"latest"`
+	Safe          uint64 `json:"safe"`
+	Finalized     uint64 `json:"finalized"`
+	Confirmed     uint64 `json:"confirmed"`     // latest - confirmations
+	Confirmations uint64 `json:"confirmations"` // 该链配置的确认数
+	Source        string `json:"source"`        // safe / finalized 的来源: tag (节点标签) / confirmations (按确认数推算)
+}
+
+// Balance 一种资产的余额
+type Balance struct {
+	Token     string `json:"token,omitempty"` // ERC-20 合约地址，原生币为空
+	Name      string `json:"name,omitempty"`
+	Symbol    string `json:"symbol"`
+	Decimals  uint8  `json:"decimals"`
+	Raw       string `json:"raw"`             // 最小单位余额 (十进制字符串，避免精度丢失)
+	Formatted string `json:"formatted"`       // 按 decimals 换算后的余额
+	Error     string `json:"error,omitempty"` // 批量查询时该 Token 查询失败的原因
+}
+
+// BalanceQuery 余额查询参数
+type BalanceQuery struct {
+	ChainID int64
+	Address string
+	Tokens  []string // ERC-20 合约地址，为空只查原生币
+	Block   string   // 为空表示 latest
+	Quorum  bool     // 仲裁读：多个独立服务商一致才返回 (打款前校验等场景)
+}
+
+// Balances 一个地址的原生币与 Token 余额
+type Balances struct {
+	ChainID int64     `json:"chain_id"`
+	Address string    `json:"address"`
+	Block   string    `json:"block"`
+	Native  *Balance  `json:"native"`
+	Tokens  []Balance `json:"tokens"`
+}
+
+// Transaction 交易
+type Transaction struct {
+	ChainID              int64    `json:"chain_id"`
+	Hash                 string   `json:"hash"`
+	Type                 uint8    `json:"type"`
+	From                 string   `json:"from"`
+	To                   string   `json:"to,omitempty"` // 创建合约时为空
+	Nonce                uint64   `json:"nonce"`
+	Value                string   `json:"value"`
+	Input                string   `json:"input"`
+	Gas                  uint64   `json:"gas"`
+	GasPrice             string   `json:"gas_price,omitempty"`                // legacy / access list 交易
+	MaxFeePerGas         string   `json:"max_fee_per_gas,omitempty"`          // EIP-1559 及之后的交易
+	MaxPriorityFeePerGas string   `json:"max_priority_fee_per_gas,omitempty"` // EIP-1559 及之后的交易
+	Pending              bool     `json:"pending"`
+	Receipt              *Receipt `json:"receipt,omitempty"`
+}
+
+// Receipt 交易回执
+type Receipt struct {
+	TxHash            string `json:"tx_hash"`
+	Status            uint64 `json:"status"` // 1 成功，0 失败
+	Success           bool   `json:"success"`
+	BlockNumber       uint64 `json:"block_number"`
+	BlockHash         string `json:"block_hash"`
+	TransactionIndex  uint   `json:"transaction_index"`
+	GasUsed           uint64 `json:"gas_used"`
+	CumulativeGasUsed uint64 `json:"cumulative_gas_used"`
+	EffectiveGasPrice string `json:"effective_gas_price"`
+	BlobGasUsed       uint64 `json:"blob_gas_used,omitempty"`
+	BlobGasPrice      string `json:"blob_gas_price,omitempty"`
+	Fee               string `json:"fee"` // gas_used * effective_gas_price (+ blob 费用)，单位 wei
+	ContractAddress   string `json:"contract_address,omitempty"`
+	Logs              []Log  `json:"logs"`
+	Confirmations     uint64 `json:"confirmations"` // 含所在区块，刚上链为 1
+	Finalized         bool   `json:"finalized"`     // 所在区块已最终确认 (见 BlockHeights.Finalized)
+}
+
+// Log 事件日志
+type Log struct {
+	Index   uint        `json:"index"`
+	Address string      `json:"address"`
+	Topics  []string    `json:"topics"`
+	Data    string      `json:"data"`
+	Removed bool        `json:"removed"`
+	Decoded *DecodedLog `json:"decoded,omitempty"` // 没有匹配的 ABI 时为空
+}
+
+// DecodedLog 按 contracts 配置中的 ABI 解码的事件
+type DecodedLog struct {
+	Contract  string         `json:"contract"`
+	Event     string         `json:"event"`
+	Signature string         `json:"signature"`
+	Args      map[string]any `json:"args"`
+}
+
+// BlockQuery 区块查询参数
+type BlockQuery struct {
+	ChainID  int64
+	Block    string // 区块号 (十进制 / 0x 十六进制)、区块哈希或 latest / pending / safe / finalized / earliest，默认 latest
+	FullTx   bool   // 返回完整交易，否则只返回交易哈希
+	Page     int    // 页码，从 1 开始
+	PageSize int    // 每页条数，0 表示不分页
+}
+
+// Block 区块
+type Block struct {
+	ChainID       int64         `json:"chain_id"`
+	Number        uint64        `json:"number"`
+	Hash          string        `json:"hash"`
+	ParentHash    string        `json:"parent_hash"`
+	Timestamp     uint64        `json:"timestamp"`
+	Miner         string        `json:"miner"`
+	GasUsed       uint64        `json:"gas_used"`
+	GasLimit      uint64        `json:"gas_limit"`
+	BaseFeePerGas string        `json:"base_fee_per_gas,omitempty"` // London 之后
+	BlobGasUsed   *uint64       `json:"blob_gas_used,omitempty"`    // Cancun 之后
+	ExcessBlobGas *uint64       `json:"excess_blob_gas,omitempty"`  // Cancun 之后
+	Size          uint64        `json:"size"`
+	TxCount       int           `json:"tx_count"`
+	Confirmations uint64        `json:"confirmations"`
+	Finalized     bool          `json:"finalized"`
+	Page          int           `json:"page,omitempty"`
+	PageSize      int           `json:"page_size,omitempty"`
+	TxHashes      []string      `json:"tx_hashes,omitempty"`    // FullTx 为 false 时
+	Transactions  []Transaction `json:"transactions,omitempty"` // FullTx 为 true 时
+}
+
+// ContractInfo 已注册合约的概要
+type ContractInfo struct {
+	Name        string               `json:"name"`
+	Address     string               `json:"address"`     // 默认地址 (未按链配置时使用)
+	Deployments []ContractDeployment `json:"deployments"` // 按链配置的地址
+	Methods     []string             `json:"methods"`     // 可调用的只读方法
+	Events      []string             `json:"events"`      // 可解码的事件
+	Deployed    []int64              `json:"deployed"`    // 启动校验时确认有合约代码的链
+}
+
+// ContractDeployment 合约在某条链上的地址与部署区块
+type ContractDeployment struct {
+	ChainID     int64  `json:"chain_id"`
+	Address     string `json:"address"`
+	DeployBlock uint64 `json:"deploy_block,omitempty"`
+}
+
+// ContractCallQuery 只读方法调用参数
+type ContractCallQuery struct {
+	ChainID  int64
+	Contract string          // contracts 配置中的别名
+	Method   string          // ABI 方法名 (重载方法带序号，如 foo0)
+	Args     json.RawMessage // JSON 数组，按 ABI 转换
+	Block    string          // 为空表示 latest
+	Quorum   bool            // 仲裁读：多个独立服务商一致才返回
+}
+
+// ContractCallResult 只读方法调用结果
+type ContractCallResult struct {
+	ChainID  int64            `json:"chain_id"`
+	Contract string           `json:"contract"`
+	Address  string           `json:"address"`
+	Method   string           `json:"method"`
+	Block    string           `json:"block"`
+	Outputs  []ContractOutput `json:"outputs"`
+}
+
+// ContractOutput 按 ABI 解码的返回值
+type ContractOutput struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value any    `json:"value"` // 大整数为十进制字符串，地址 / 字节为 0x 十六进制，tuple 为对象
 }
 
 // NewChainUsecase 构造函数
@@ -29,3 +233,119 @@ func NewChainUsecase(repo ChainRepo) *ChainUsecase {
 func (uc *ChainUsecase) GetCurrentHeight(ctx context.Context, chainID int64) (uint64, error) {
 	return uc.repo.GetBlockHeight(ctx, chainID)
 }
+
+// GetBlockHeights 业务方法：获取 latest / safe / finalized / N 确认高度
+func (uc *ChainUsecase) GetBlockHeights(ctx context.Context, chainID int64) (*BlockHeights, error) {
+	return uc.repo.GetBlockHeights(ctx, chainID)
+}
+
+// GetBalances 业务方法：查询原生币余额，以及 (可选) 一批 ERC-20 余额
+func (uc *ChainUsecase) GetBalances(ctx context.Context, q BalanceQuery) (*Balances, error) {
+	if len(q.Tokens) > maxBalanceTokens {
+		return nil, fmt.Errorf("%w: at most %d tokens per query", ErrInvalidArgument, maxBalanceTokens)
+	}
+	if q.Block == "" {
+		q.Block = "latest"
+	}
+
+	native, err := uc.repo.GetNativeBalance(ctx, q.ChainID, q.Address, q.Block, q.Quorum)
+	if err != nil {
+		return nil, err
+	}
+	out := &Balances{ChainID: q.ChainID, Address: q.Address, Block: q.Block, Native: native, Tokens: []Balance{}}
+	if len(q.Tokens) == 0 {
+		return out, nil
+	}
+
+	if out.Tokens, err = uc.repo.GetTokenBalances(ctx, q.ChainID, q.Address, q.Tokens, q.Block, q.Quorum); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetTransaction 业务方法：交易、回执与确认数
+func (uc *ChainUsecase) GetTransaction(ctx context.Context, chainID int64, hash string) (*Transaction, error) {
+	tx, err := uc.repo.GetTransaction(ctx, chainID, hash)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Receipt != nil {
+		if err := uc.fillConfirmations(ctx, chainID, tx.Receipt); err != nil {
+			return nil, err
+		}
+	}
+	return tx, nil
+}
+
+// GetReceipt 业务方法：回执与确认数
+func (uc *ChainUsecase) GetReceipt(ctx context.Context, chainID int64, hash string) (*Receipt, error) {
+	r, err := uc.repo.GetReceipt(ctx, chainID, hash)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.fillConfirmations(ctx, chainID, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetBlock 业务方法：区块头、确认数与 (分页的) 交易列表
+func (uc *ChainUsecase) GetBlock(ctx context.Context, q BlockQuery) (*Block, error) {
+	// 1. 参数校验
+	if q.PageSize < 0 || q.PageSize > maxBlockPageSize {
+		return nil, fmt.Errorf("%w: page_size must be between 0 and %d", ErrInvalidArgument, maxBlockPageSize)
+	}
+	if q.Page < 0 {
+		return nil, fmt.Errorf("%w: page must be positive", ErrInvalidArgument)
+	}
+	if q.PageSize > 0 && q.Page == 0 {
+		q.Page = 1
+	}
+	if q.Block == "" {
+		q.Block = "latest"
+	}
+
+	// 2. 查询
+	block, err := uc.repo.GetBlock(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	heights, err := uc.repo.GetBlockHeights(ctx, q.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	block.Confirmations, block.Finalized = confirmations(heights, block.Number)
+	return block, nil
+}
+
+// fillConfirmations 按当前链头计算回执的确认数，并按该链的确认策略判断是否已最终确认
+func (uc *ChainUsecase) fillConfirmations(ctx context.Context, chainID int64, r *Receipt) error {
+	heights, err := uc.repo.GetBlockHeights(ctx, chainID)
+	if err != nil {
+		return err
+	}
+	r.Confirmations, r.Finalized = confirmations(heights, r.BlockNumber)
+	return nil
+}
+
+// confirmations 区块 number 的确认数 (含该区块) 以及是否已最终确认
+// 链头高度有短期缓存，可能比该区块还低，此时按刚上链计
+func confirmations(heights *BlockHeights, number uint64) (uint64, bool) {
+	return max(heights.Latest, number) - number + 1, number <= heights.Finalized
+}
+
+// ListContracts 业务方法：已注册的合约
+func (uc *ChainUsecase) ListContracts(ctx context.Context) ([]ContractInfo, error) {
+	return uc.repo.ListContracts(ctx)
+}
+
+// CallContract 业务方法：按合约别名 + 方法名调用只读方法
+func (uc *ChainUsecase) CallContract(ctx context.Context, q ContractCallQuery) (*ContractCallResult, error) {
+	if q.Contract == "" || q.Method == "" {
+		return nil, fmt.Errorf("%w: contract and method are required", ErrInvalidArgument)
+	}
+	if q.Block == "" {
+		q.Block = "latest"
+	}
+	return uc.repo.CallContract(ctx, q)
+}
@@ -2,9 +2,19 @@ package data
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/zy99978455-otw/go-micro-template/internal/biz" 
 )
 
+const tokenQueryConcurrency = 16 // 批量查询 Token 余额时的并发数 (并发的 eth_call 会被合并成 batch 请求)
+
 // 定义 Data 层的 ProviderSet
 
 
@@ -22,13 +32,10 @@ func NewChainRepo(data *Data) biz.ChainRepo {
 
 // GetBlockHeight 实现接口方法
 func (r *chainRepo) GetBlockHeight(ctx context.Context, chainID int64) (uint64, error) {
-	// 1. 从 Data 层获取 RPC 客户端 (利用了我们的 RPC Manager)
-	client, err := r.data.GetRPCClient(chainID)
-	if err != nil {
-		return 0, err
-	}
+	// 1. 从 Data 层获取链客户端门面 (节点故障时自动切换到下一个节点)
+	client := r.data.GetChainClient(chainID)
 
-	// 2. 调用 ethclient 的方法
+	// 2. 调用与 ethclient 同名的方法
 	height, err := client.BlockNumber(ctx)
 	if err != nil {
 		return 0, err
@@ -36,3 +43,150 @@ func (r *chainRepo) GetBlockHeight(ctx context.Context, chainID int64) (uint64,
 
 	return height, nil
 }
+
+// GetBlockHeights 各个确认级别的高度 (确认策略见链配置 finality)
+func (r *chainRepo) GetBlockHeights(ctx context.Context, chainID int64) (*biz.BlockHeights, error) {
+	h, err := r.data.GetChainClient(chainID).FinalityHeights(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &biz.BlockHeights{
+		ChainID:       chainID,
+		Latest:        h.Latest,
+		Safe:          h.Safe,
+		Finalized:     h.Finalized,
+		Confirmed:     h.Confirmed,
+		Confirmations: h.Confirmations,
+		Source:        h.Source,
+	}, nil
+}
+
+// GetNativeBalance 原生币余额
+func (r *chainRepo) GetNativeBalance(ctx context.Context, chainID int64, address, block string, quorum bool) (*biz.Balance, error) {
+	// 1. 解析参数
+	account, err := parseAddress("address", address)
+	if err != nil {
+		return nil, err
+	}
+	blockNumber, err := parseBlockParam(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. 查询
+	client := r.data.GetChainClient(chainID)
+	balanceAt := client.BalanceAt
+	if quorum {
+		balanceAt = client.Quorum().BalanceAt
+	}
+	wei, err := balanceAt(ctx, account, blockNumber)
+	if err != nil {
+		return nil, quorumError(err)
+	}
+
+	return &biz.Balance{
+		Symbol:    client.NativeSymbol(),
+		Decimals:  nativeDecimals,
+		Raw:       wei.String(),
+		Formatted: formatUnits(wei, nativeDecimals),
+	}, nil
+}
+
+// GetTokenBalances 批量查询 ERC-20 余额 (decimals / symbol 来自 Token 元数据缓存；quorum 只作用于 balanceOf)
+func (r *chainRepo) GetTokenBalances(ctx context.Context, chainID int64, address string, tokens []string, block string, quorum bool) ([]biz.Balance, error) {
+	// 1. 解析参数 (任意一个地址不合法则整个请求失败)
+	account, err := parseAddress("address", address)
+	if err != nil {
+		return nil, err
+	}
+	blockNumber, err := parseBlockParam(block)
+	if err != nil {
+		return nil, err
+	}
+	tokenAddrs := make([]common.Address, len(tokens))
+	for i, t := range tokens {
+		if tokenAddrs[i], err = parseAddress("token", t); err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. 并发查询，单个 Token 失败只记录在该项
+	client := r.data.GetChainClient(chainID)
+	tokenBalance := client.TokenBalance
+	if quorum {
+		tokenBalance = client.Quorum().TokenBalance
+	}
+	out := make([]biz.Balance, len(tokenAddrs))
+	sem := make(chan struct{}, tokenQueryConcurrency)
+	var wg sync.WaitGroup
+	for i, token := range tokenAddrs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			b := biz.Balance{Token: token.Hex()}
+			meta, err := client.TokenMetadata(ctx, token)
+			if err == nil {
+				b.Name, b.Symbol, b.Decimals = meta.Name, meta.Symbol, meta.Decimals
+				var raw *big.Int
+				if raw, err = tokenBalance(ctx, token, account, blockNumber); err == nil {
+					b.Raw, b.Formatted = raw.String(), formatUnits(raw, meta.Decimals)
+				}
+			}
+			if err != nil {
+				b.Error = err.Error()
+			}
+			out[i] = b
+		}()
+	}
+	wg.Wait()
+
+	// 调用方取消时整体失败，而不是返回一批错误项
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// quorumError *QuorumError -> biz.ErrQuorumNotReached，其他错误原样返回
+func quorumError(err error) error {
+	var qErr *QuorumError
+	if errors.As(err, &qErr) {
+		return fmt.Errorf("%w: %v", biz.ErrQuorumNotReached, err)
+	}
+	return err
+}
+
+// parseAddress 校验十六进制地址
+func parseAddress(field, s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("%w: %s %q is not a hex address", biz.ErrInvalidArgument, field, s)
+	}
+	return common.HexToAddress(s), nil
+}
+
+// parseBlockParam 解析区块参数：空 / latest 返回 nil；pending / safe / finalized / earliest 返回对应标签；
+// 其他按十进制或 0x 十六进制区块号解析
+func parseBlockParam(s string) (*big.Int, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "latest":
+		return nil, nil
+	case "pending":
+		return big.NewInt(int64(rpc.PendingBlockNumber)), nil
+	case "safe":
+		return big.NewInt(int64(rpc.SafeBlockNumber)), nil
+	case "finalized":
+		return big.NewInt(int64(rpc.FinalizedBlockNumber)), nil
+	case "earliest":
+		return big.NewInt(int64(rpc.EarliestBlockNumber)), nil
+	}
+
+	n, ok := new(big.Int).SetString(strings.TrimSpace(s), 0)
+	if !ok || n.Sign() < 0 || !n.IsUint64() {
+		return nil, fmt.Errorf("%w: block %q is not a block number or tag", biz.ErrInvalidArgument, s)
+	}
+	return n, nil
+}
@@ -0,0 +1,89 @@
+package data
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/zy99978455-otw/go-micro-template/internal/biz"
+)
+
+// ================= 区块 (chainRepo) =================
+
+// GetBlock 按区块号 / 哈希 / 标签查询区块
+// 按哈希、按高度查询的区块走 Redis 缓存 (已最终确认的长期缓存)；safe / finalized 标签先换算成高度再查，同样命中缓存
+func (r *chainRepo) GetBlock(ctx context.Context, q biz.BlockQuery) (*biz.Block, error) {
+	client := r.data.GetChainClient(q.ChainID)
+
+	// 1. 查询区块
+	var (
+		block *types.Block
+		err   error
+	)
+	if id := strings.TrimSpace(q.Block); strings.HasPrefix(id, "0x") && len(id) == 66 {
+		hash, perr := parseHash("block", id)
+		if perr != nil {
+			return nil, perr
+		}
+		block, err = client.BlockByHash(ctx, hash)
+	} else {
+		number, perr := parseBlockParam(id)
+		if perr != nil {
+			return nil, perr
+		}
+		if number != nil && (number.Int64() == int64(rpc.SafeBlockNumber) || number.Int64() == int64(rpc.FinalizedBlockNumber)) {
+			n, terr := client.tagHeight(ctx, rpc.BlockNumber(number.Int64()))
+			if terr != nil {
+				return nil, notFound(terr, "block %s", id)
+			}
+			number = new(big.Int).SetUint64(n)
+		}
+		block, err = client.BlockByNumber(ctx, number)
+	}
+	if err != nil {
+		return nil, notFound(err, "block %s", q.Block)
+	}
+
+	// 2. 区块头
+	h := block.Header()
+	out := &biz.Block{
+		ChainID:       q.ChainID,
+		Number:        block.NumberU64(),
+		Hash:          block.Hash().Hex(),
+		ParentHash:    h.ParentHash.Hex(),
+		Timestamp:     h.Time,
+		Miner:         h.Coinbase.Hex(),
+		GasUsed:       h.GasUsed,
+		GasLimit:      h.GasLimit,
+		BlobGasUsed:   h.BlobGasUsed,
+		ExcessBlobGas: h.ExcessBlobGas,
+		Size:          block.Size(),
+		TxCount:       len(block.Transactions()),
+		Page:          q.Page,
+		PageSize:      q.PageSize,
+	}
+	if h.BaseFee != nil {
+		out.BaseFeePerGas = h.BaseFee.String()
+	}
+
+	// 3. 交易列表 (分页)
+	txs := block.Transactions()
+	if q.PageSize > 0 {
+		from := min((q.Page-1)*q.PageSize, len(txs))
+		txs = txs[from:min(from+q.PageSize, len(txs))]
+	}
+	if q.FullTx {
+		out.Transactions = make([]biz.Transaction, 0, len(txs))
+		for _, tx := range txs {
+			out.Transactions = append(out.Transactions, *toBizTransaction(q.ChainID, tx, false))
+		}
+	} else {
+		out.TxHashes = make([]string, 0, len(txs))
+		for _, tx := range txs {
+			out.TxHashes = append(out.TxHashes, tx.Hash().Hex())
+		}
+	}
+	return out, nil
+}
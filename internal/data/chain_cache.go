@@ -0,0 +1,384 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+	"github.com/zy99978455-otw/go-micro-template/pkg/global"
+)
+
+const (
+	defaultCachePrefix    = "chain"
+	defaultCacheHeadTTL   = 2 * time.Second
+	defaultCacheRecentTTL = 12 * time.Second
+	defaultCacheTTL       = 7 * 24 * time.Hour
+)
+
+// ChainCache 链数据的 Redis 缓存
+// 1. 不可变数据 (按哈希的区块/区块头、已最终确认的区块/回执/合约代码、Token 元数据) 长期缓存
+// 2. 依赖链头的数据 (区块高度、safe / finalized 高度、latest 合约代码) 以及未最终确认的数据短期缓存
+// 3. 发生重组时通过 InvalidateFrom 清理未最终确认的数据
+// nil 表示不缓存 (Redis 未配置或 cache.disable)，所有方法直接回源
+type ChainCache struct {
+	rdb       *redis.Client
+	prefix    string
+	headTTL   time.Duration
+	recentTTL time.Duration
+	ttl       time.Duration
+
+	reorgDepth map[int64]uint64 // 重组时向后清理的区块数 (= 该链 head_tracker.window，能检测到的最大重组深度)
+	heads      *HeadTracker     // 提供随链头刷新的最终确认高度 (NewData 注入，nil 时现查)
+}
+
+// NewChainCache 构造函数；Redis 未配置时返回 nil
+func NewChainCache(cfg *config.AppConfig, rdb *redis.Client) *ChainCache {
+	c := cfg.Cache
+	if rdb == nil || c.Disable {
+		return nil
+	}
+	cache := &ChainCache{
+		rdb:       rdb,
+		prefix:    c.Prefix,
+		headTTL:   c.HeadTTL,
+		recentTTL: c.RecentTTL,
+		ttl:       c.TTL,
+
+		reorgDepth: make(map[int64]uint64, len(cfg.Chains)),
+	}
+	for _, chain := range cfg.Chains {
+		depth := chain.HeadTracker.Window
+		if depth <= 0 {
+			depth = defaultHeadWindow
+		}
+		cache.reorgDepth[chain.ChainID] = uint64(depth)
+	}
+	if cache.prefix == "" {
+		cache.prefix = defaultCachePrefix
+	}
+	if cache.headTTL <= 0 {
+		cache.headTTL = defaultCacheHeadTTL
+	}
+	if cache.recentTTL <= 0 {
+		cache.recentTTL = defaultCacheRecentTTL
+	}
+	if cache.ttl <= 0 {
+		cache.ttl = defaultCacheTTL
+	}
+	return cache
+}
+
+// key 带链 ID 的缓存 key，例如 chain:1:block:0xabc...
+func (c *ChainCache) key(chainID int64, kind, id string) string {
+	return fmt.Sprintf("%s:%d:%s:%s", c.prefix, chainID, kind, id)
+}
+
+// get 读缓存；Redis 出错按未命中处理，不影响业务
+func (c *ChainCache) get(ctx context.Context, key string) ([]byte, bool) {
+	b, err := c.rdb.Get(ctx, key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) && global.Log != nil {
+			global.Log.Debugf("⚠️ [Cache] get %s failed: %v", key, err)
+		}
+		return nil, false
+	}
+	return b, true
+}
+
+func (c *ChainCache) set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	if err := c.rdb.Set(ctx, key, value, ttl).Err(); err != nil && global.Log != nil {
+		global.Log.Debugf("⚠️ [Cache] set %s failed: %v", key, err)
+	}
+}
+
+// cacheThrough 读穿缓存：命中直接解码返回，未命中回源并按 ttl 写入 (ttl <= 0 不写)
+func cacheThrough[T any](ctx context.Context, c *ChainCache, key string, ttl func(T) time.Duration,
+	encode func(T) ([]byte, error), decode func([]byte) (T, error), load func() (T, error)) (T, error) {
+	if c == nil {
+		return load()
+	}
+	if b, ok := c.get(ctx, key); ok {
+		if v, err := decode(b); err == nil {
+			return v, nil
+		}
+	}
+
+	v, err := load()
+	if err != nil {
+		return v, err
+	}
+	if d := ttl(v); d > 0 {
+		if b, err := encode(v); err == nil {
+			c.set(ctx, key, b, d)
+		}
+	}
+	return v, nil
+}
+
+// ================= 依赖链头的数据 =================
+
+// blockNumber 区块高度 (短期缓存)
+func (c *ChainCache) blockNumber(ctx context.Context, chainID int64, load func() (uint64, error)) (uint64, error) {
+	if c == nil {
+		return load()
+	}
+	return cacheThrough(ctx, c, c.key(chainID, "head", "number"), fixedTTL[uint64](c.headTTL), encodeUint, decodeUint, load)
+}
+
+// finalized 已最终确认的高度：按链的确认策略 (finality 配置) 计算，与 FinalityHeights 一致
+// 优先用 HeadTracker 随链头刷新的值 (不额外发 RPC)；跟踪器未启用或还没有值时现查，查询失败时返回 0 (视为都未确认)
+func (c *ChainCache) finalized(ctx context.Context, cl *ChainClient) uint64 {
+	if c.heads != nil {
+		if n, ok := c.heads.Finalized(cl.chainID); ok {
+			return n
+		}
+	}
+	h, err := cl.FinalityHeights(ctx)
+	if err != nil {
+		return 0
+	}
+	return h.Finalized
+}
+
+// headTag safe / finalized 标签对应的高度 (短期缓存)
+func (c *ChainCache) headTag(ctx context.Context, chainID int64, tag rpc.BlockNumber, load func() (uint64, error)) (uint64, error) {
+	if c == nil {
+		return load()
+	}
+	return cacheThrough(ctx, c, c.key(chainID, "head", tag.String()), fixedTTL[uint64](c.headTTL), encodeUint, decodeUint, load)
+}
+
+// ================= 不可变数据 =================
+
+// blockByHash 按哈希查询的区块内容不会变，长期缓存
+func (c *ChainCache) blockByHash(ctx context.Context, chainID int64, hash common.Hash, load func() (*types.Block, error)) (*types.Block, error) {
+	if c == nil {
+		return load()
+	}
+	return cacheThrough(ctx, c, c.key(chainID, "block", hash.Hex()), fixedTTL[*types.Block](c.ttl), encodeRLP[*types.Block], decodeBlock, load)
+}
+
+// headerByHash 按哈希查询的区块头，长期缓存
+func (c *ChainCache) headerByHash(ctx context.Context, chainID int64, hash common.Hash, load func() (*types.Header, error)) (*types.Header, error) {
+	if c == nil {
+		return load()
+	}
+	return cacheThrough(ctx, c, c.key(chainID, "header", hash.Hex()), fixedTTL[*types.Header](c.ttl), encodeRLP[*types.Header], decodeHeader, load)
+}
+
+// blockByNumber 按高度查询：先查 高度 -> 哈希 索引，再按哈希取区块
+// 已最终确认的高度长期缓存，未确认的只短期缓存 (重组时由 InvalidateFrom 清理)
+func (c *ChainCache) blockByNumber(ctx context.Context, cl *ChainClient, number *big.Int, load func() (*types.Block, error)) (*types.Block, error) {
+	// latest / pending / finalized 等标签随链头变化，不缓存
+	if c == nil || number == nil || number.Sign() < 0 {
+		return load()
+	}
+
+	// 1. 索引命中
+	indexKey := c.key(cl.chainID, "blocknum", number.String())
+	if b, ok := c.get(ctx, indexKey); ok {
+		hash := common.BytesToHash(b)
+		if raw, ok := c.get(ctx, c.key(cl.chainID, "block", hash.Hex())); ok {
+			if block, err := decodeBlock(raw); err == nil {
+				return block, nil
+			}
+		}
+	}
+
+	// 2. 回源，按是否最终确认决定 TTL
+	block, err := load()
+	if err != nil {
+		return nil, err
+	}
+	ttl := c.recentTTL
+	if block.NumberU64() <= c.finalized(ctx, cl) {
+		ttl = c.ttl
+	}
+	if raw, err := rlp.EncodeToBytes(block); err == nil {
+		c.set(ctx, c.key(cl.chainID, "block", block.Hash().Hex()), raw, c.ttl)
+		c.set(ctx, indexKey, block.Hash().Bytes(), ttl)
+	}
+	return block, nil
+}
+
+// receipt 交易回执：所在区块已最终确认时长期缓存，否则短期缓存并登记到区块下，重组时清理
+func (c *ChainCache) receipt(ctx context.Context, cl *ChainClient, txHash common.Hash, load func() (*types.Receipt, error)) (*types.Receipt, error) {
+	if c == nil {
+		return load()
+	}
+	key := c.key(cl.chainID, "receipt", txHash.Hex())
+	if b, ok := c.get(ctx, key); ok {
+		var r types.Receipt
+		if err := json.Unmarshal(b, &r); err == nil {
+			return &r, nil
+		}
+	}
+
+	r, err := load()
+	if err != nil || r == nil || r.BlockNumber == nil {
+		return r, err
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return r, nil
+	}
+	if r.BlockNumber.Uint64() <= c.finalized(ctx, cl) {
+		c.set(ctx, key, b, c.ttl)
+		return r, nil
+	}
+
+	// 未确认：登记到 blocktxs 集合，InvalidateFrom 按高度清理
+	setKey := c.key(cl.chainID, "blocktxs", r.BlockNumber.String())
+	pipe := c.rdb.Pipeline()
+	pipe.Set(ctx, key, b, c.recentTTL)
+	pipe.SAdd(ctx, setKey, key)
+	pipe.Expire(ctx, setKey, c.recentTTL)
+	if _, err := pipe.Exec(ctx); err != nil && global.Log != nil {
+		global.Log.Debugf("⚠️ [Cache] cache receipt %s failed: %v", txHash.Hex(), err)
+	}
+	return r, nil
+}
+
+// code 合约代码：同一地址的代码会变 (SELFDESTRUCT 后 CREATE2 重新部署、EIP-7702 委托)
+// 已最终确认高度的代码长期缓存；latest 的非空代码只短期缓存 (空代码可能之后被部署，不缓存)；其他高度和标签不缓存
+func (c *ChainCache) code(ctx context.Context, cl *ChainClient, account common.Address, blockNumber *big.Int, load func() ([]byte, error)) ([]byte, error) {
+	if c == nil {
+		return load()
+	}
+	if blockNumber == nil {
+		ttl := func(code []byte) time.Duration {
+			if len(code) == 0 {
+				return 0
+			}
+			return c.recentTTL
+		}
+		return cacheThrough(ctx, c, c.key(cl.chainID, "code", account.Hex()), ttl, encodeBytes, decodeBytes, load)
+	}
+	if blockNumber.Sign() < 0 || blockNumber.Uint64() > c.finalized(ctx, cl) {
+		return load()
+	}
+	return cacheThrough(ctx, c, c.key(cl.chainID, "code", account.Hex()+":"+blockNumber.String()), fixedTTL[[]byte](c.ttl), encodeBytes, decodeBytes, load)
+}
+
+// token Token 元数据 (name / symbol / decimals)
+func (c *ChainCache) token(ctx context.Context, chainID int64, token common.Address, load func() (*TokenMetadata, error)) (*TokenMetadata, error) {
+	if c == nil {
+		return load()
+	}
+	// name / symbol 为空可能是节点临时出错，只短期缓存，之后重新查询
+	ttl := func(m *TokenMetadata) time.Duration {
+		if m.Name == "" || m.Symbol == "" {
+			return c.recentTTL
+		}
+		return c.ttl
+	}
+	return cacheThrough(ctx, c, c.key(chainID, "token", token.Hex()), ttl, encodeJSON[*TokenMetadata], decodeJSON[TokenMetadata], load)
+}
+
+// ================= 重组失效 =================
+
+// InvalidateFrom 链在 from 高度发生重组：清理该高度及之后未最终确认的缓存 (高度索引、回执、链头)
+// 已最终确认的数据和按哈希缓存的数据不受重组影响
+func (c *ChainCache) InvalidateFrom(ctx context.Context, chainID int64, from uint64) {
+	if c == nil {
+		return
+	}
+
+	// 1. 收集每个高度登记的回执
+	pipe := c.rdb.Pipeline()
+	depth := c.reorgDepth[chainID]
+	if depth == 0 {
+		depth = defaultHeadWindow
+	}
+	members := make([]*redis.StringSliceCmd, 0, depth)
+	keys := []string{c.key(chainID, "head", "number"), c.key(chainID, "head", "safe"), c.key(chainID, "head", "finalized")}
+	for n := from; n < from+depth; n++ {
+		id := strconv.FormatUint(n, 10)
+		setKey := c.key(chainID, "blocktxs", id)
+		members = append(members, pipe.SMembers(ctx, setKey))
+		keys = append(keys, c.key(chainID, "blocknum", id), setKey)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		if global.Log != nil {
+			global.Log.Warnf("⚠️ [Cache] chain %d invalidate from %d failed: %v", chainID, from, err)
+		}
+		return
+	}
+	for _, m := range members {
+		keys = append(keys, m.Val()...)
+	}
+
+	// 2. 一次删除
+	deleted, err := c.rdb.Del(ctx, keys...).Result()
+	if err != nil {
+		if global.Log != nil {
+			global.Log.Warnf("⚠️ [Cache] chain %d invalidate from %d failed: %v", chainID, from, err)
+		}
+		return
+	}
+	if global.Log != nil {
+		global.Log.Infof("♻️ [Cache] chain %d reorg at block %d, invalidated %d keys", chainID, from, deleted)
+	}
+}
+
+// ================= 编解码 =================
+
+// fixedTTL 固定 TTL
+func fixedTTL[T any](ttl time.Duration) func(T) time.Duration {
+	return func(T) time.Duration { return ttl }
+}
+
+func encodeUint(v uint64) ([]byte, error) {
+	return strconv.AppendUint(nil, v, 10), nil
+}
+
+func decodeUint(b []byte) (uint64, error) {
+	return strconv.ParseUint(string(b), 10, 64)
+}
+
+func encodeBytes(v []byte) ([]byte, error) { return v, nil }
+
+func decodeBytes(b []byte) ([]byte, error) { return b, nil }
+
+func encodeRLP[T any](v T) ([]byte, error) {
+	return rlp.EncodeToBytes(v)
+}
+
+func decodeBlock(b []byte) (*types.Block, error) {
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(b, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+func decodeHeader(b []byte) (*types.Header, error) {
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(b, header); err != nil {
+		return nil, err
+	}
+	return header, nil
+}
+
+func encodeJSON[T any](v T) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func decodeJSON[T any](b []byte) (*T, error) {
+	v := new(T)
+	if err := json.Unmarshal(b, v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
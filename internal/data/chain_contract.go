@@ -0,0 +1,75 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zy99978455-otw/go-micro-template/internal/biz"
+)
+
+// ================= 合约 (chainRepo) =================
+
+// ListContracts 合约注册表中的合约
+func (r *chainRepo) ListContracts(ctx context.Context) ([]biz.ContractInfo, error) {
+	infos := r.data.GetContractRegistry().Contracts()
+	out := make([]biz.ContractInfo, 0, len(infos))
+	for _, info := range infos {
+		ci := biz.ContractInfo{
+			Name:        info.Name,
+			Address:     info.Address,
+			Deployments: make([]biz.ContractDeployment, 0, len(info.Deployments)),
+			Methods:     info.Methods,
+			Events:      info.Events,
+			Deployed:    info.Deployed,
+		}
+		for _, d := range info.Deployments {
+			ci.Deployments = append(ci.Deployments, biz.ContractDeployment(d))
+		}
+		out = append(out, ci)
+	}
+	return out, nil
+}
+
+// CallContract 按别名 + 方法名调用只读方法 (地址按 chain_id 解析)
+func (r *chainRepo) CallContract(ctx context.Context, q biz.ContractCallQuery) (*biz.ContractCallResult, error) {
+	blockNumber, err := parseBlockParam(q.Block)
+	if err != nil {
+		return nil, err
+	}
+
+	dep, outputs, err := r.data.GetContractRegistry().Call(ctx, q.ChainID, q.Contract, q.Method, q.Args, blockNumber, q.Quorum)
+	if err != nil {
+		return nil, contractError(ctx, err)
+	}
+
+	out := &biz.ContractCallResult{
+		ChainID:  q.ChainID,
+		Contract: q.Contract,
+		Address:  dep.Address.Hex(),
+		Method:   q.Method,
+		Block:    q.Block,
+		Outputs:  make([]biz.ContractOutput, 0, len(outputs)),
+	}
+	for _, o := range outputs {
+		out.Outputs = append(out.Outputs, biz.ContractOutput(o))
+	}
+	return out, nil
+}
+
+// contractError 合约调用错误 -> biz 错误
+// 合约不存在 / 该链上没有地址或代码 / 早于部署区块 -> ErrNotFound；参数与 ABI 不符、执行 revert -> ErrInvalidArgument
+// 仲裁未达成 -> ErrQuorumNotReached
+func contractError(ctx context.Context, err error) error {
+	var callErr *ContractCallError
+	var qErr *QuorumError
+	switch {
+	case errors.As(err, &qErr):
+		return fmt.Errorf("%w: %v", biz.ErrQuorumNotReached, err)
+	case errors.Is(err, ErrContractNotFound), errors.Is(err, ErrContractNotDeployed):
+		return fmt.Errorf("%w: %v", biz.ErrNotFound, err)
+	case errors.As(err, &callErr), classifyError(ctx, err) == errClassExecution:
+		return fmt.Errorf("%w: %v", biz.ErrInvalidArgument, err)
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package data
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/zy99978455-otw/go-micro-template/internal/biz"
+)
+
+func TestParseBlockParam(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    *big.Int // nil 表示 latest
+		wantErr bool
+	}{
+		{in: ""},
+		{in: "latest"},
+		{in: " Latest "},
+		{in: "pending", want: big.NewInt(int64(rpc.PendingBlockNumber))},
+		{in: "SAFE", want: big.NewInt(int64(rpc.SafeBlockNumber))},
+		{in: "finalized", want: big.NewInt(int64(rpc.FinalizedBlockNumber))},
+		{in: "earliest", want: big.NewInt(int64(rpc.EarliestBlockNumber))},
+		{in: "0", want: big.NewInt(0)},
+		{in: "19000000", want: big.NewInt(19000000)},
+		{in: "0x10", want: big.NewInt(16)},
+		{in: "-1", wantErr: true},
+		{in: "0xzz", wantErr: true},
+		{in: "newest", wantErr: true},
+		{in: "18446744073709551616", wantErr: true}, // 超过 uint64
+	}
+	for _, tt := range tests {
+		got, err := parseBlockParam(tt.in)
+		if tt.wantErr {
+			if !errors.Is(err, biz.ErrInvalidArgument) {
+				t.Errorf("parseBlockParam(%q) error = %v, want ErrInvalidArgument", tt.in, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseBlockParam(%q) unexpected error: %v", tt.in, err)
+			continue
+		}
+		if (got == nil) != (tt.want == nil) || (got != nil && got.Cmp(tt.want) != 0) {
+			t.Errorf("parseBlockParam(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
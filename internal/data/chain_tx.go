@@ -0,0 +1,158 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/zy99978455-otw/go-micro-template/internal/biz"
+)
+
+// ================= 交易与回执 (chainRepo) =================
+
+// GetTransaction 交易及其回执；已上链但节点暂时查不到回执时 Receipt 为 nil
+func (r *chainRepo) GetTransaction(ctx context.Context, chainID int64, hash string) (*biz.Transaction, error) {
+	txHash, err := parseHash("hash", hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. 交易本身
+	client := r.data.GetChainClient(chainID)
+	tx, pending, err := client.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return nil, notFound(err, "transaction %s", txHash.Hex())
+	}
+	out := toBizTransaction(chainID, tx, pending)
+	if pending {
+		return out, nil
+	}
+
+	// 2. 回执
+	receipt, err := client.TransactionReceipt(ctx, txHash)
+	switch {
+	case errors.Is(err, ethereum.NotFound):
+		return out, nil
+	case err != nil:
+		return nil, err
+	}
+	out.Receipt = r.toBizReceipt(chainID, receipt)
+	return out, nil
+}
+
+// GetReceipt 交易回执；交易不存在或未上链时返回 biz.ErrNotFound
+func (r *chainRepo) GetReceipt(ctx context.Context, chainID int64, hash string) (*biz.Receipt, error) {
+	txHash, err := parseHash("hash", hash)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := r.data.GetChainClient(chainID).TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, notFound(err, "receipt of %s", txHash.Hex())
+	}
+	return r.toBizReceipt(chainID, receipt), nil
+}
+
+func toBizTransaction(chainID int64, tx *types.Transaction, pending bool) *biz.Transaction {
+	out := &biz.Transaction{
+		ChainID: chainID,
+		Hash:    tx.Hash().Hex(),
+		Type:    tx.Type(),
+		Nonce:   tx.Nonce(),
+		Value:   tx.Value().String(),
+		Input:   hexutil.Encode(tx.Data()),
+		Gas:     tx.Gas(),
+		Pending: pending,
+	}
+	if from, err := types.Sender(types.LatestSignerForChainID(big.NewInt(chainID)), tx); err == nil {
+		out.From = from.Hex()
+	}
+	if tx.To() != nil {
+		out.To = tx.To().Hex()
+	}
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		out.GasPrice = tx.GasPrice().String()
+	default:
+		out.MaxFeePerGas = tx.GasFeeCap().String()
+		out.MaxPriorityFeePerGas = tx.GasTipCap().String()
+	}
+	return out
+}
+
+// toBizReceipt 转换回执并按 contracts 配置中的 ABI 解码日志 (确认数由 biz 层填充)
+func (r *chainRepo) toBizReceipt(chainID int64, receipt *types.Receipt) *biz.Receipt {
+	out := &biz.Receipt{
+		TxHash:            receipt.TxHash.Hex(),
+		Status:            receipt.Status,
+		Success:           receipt.Status == types.ReceiptStatusSuccessful,
+		BlockNumber:       receipt.BlockNumber.Uint64(),
+		BlockHash:         receipt.BlockHash.Hex(),
+		TransactionIndex:  receipt.TransactionIndex,
+		GasUsed:           receipt.GasUsed,
+		CumulativeGasUsed: receipt.CumulativeGasUsed,
+		BlobGasUsed:       receipt.BlobGasUsed,
+		Logs:              r.toBizLogs(chainID, receipt.Logs),
+	}
+
+	// 手续费 = gas_used * effective_gas_price + blob_gas_used * blob_gas_price
+	fee := new(big.Int)
+	if receipt.EffectiveGasPrice != nil {
+		out.EffectiveGasPrice = receipt.EffectiveGasPrice.String()
+		fee.Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+	}
+	if receipt.BlobGasPrice != nil && receipt.BlobGasUsed > 0 {
+		out.BlobGasPrice = receipt.BlobGasPrice.String()
+		fee.Add(fee, new(big.Int).Mul(new(big.Int).SetUint64(receipt.BlobGasUsed), receipt.BlobGasPrice))
+	}
+	out.Fee = fee.String()
+
+	if receipt.ContractAddress != (common.Address{}) {
+		out.ContractAddress = receipt.ContractAddress.Hex()
+	}
+	return out
+}
+
+func (r *chainRepo) toBizLogs(chainID int64, logs []*types.Log) []biz.Log {
+	registry := r.data.GetContractRegistry()
+	out := make([]biz.Log, 0, len(logs))
+	for _, l := range logs {
+		bl := biz.Log{
+			Index:   l.Index,
+			Address: l.Address.Hex(),
+			Topics:  make([]string, len(l.Topics)),
+			Data:    hexutil.Encode(l.Data),
+			Removed: l.Removed,
+		}
+		for i, t := range l.Topics {
+			bl.Topics[i] = t.Hex()
+		}
+		if d := registry.DecodeLog(chainID, l); d != nil {
+			bl.Decoded = &biz.DecodedLog{Contract: d.Contract, Event: d.Event, Signature: d.Signature, Args: d.Args}
+		}
+		out = append(out, bl)
+	}
+	return out
+}
+
+// parseHash 校验 32 字节哈希 (0x + 64 位十六进制)
+func parseHash(field, s string) (common.Hash, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("%w: %s %q is not a 32-byte hex hash", biz.ErrInvalidArgument, field, s)
+	}
+	return common.BytesToHash(b), nil
+}
+
+// notFound ethereum.NotFound -> biz.ErrNotFound，其他错误原样返回
+func notFound(err error, format string, args ...any) error {
+	if errors.Is(err, ethereum.NotFound) {
+		return fmt.Errorf("%w: %s", biz.ErrNotFound, fmt.Sprintf(format, args...))
+	}
+	return err
+}
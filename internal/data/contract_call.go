@@ -0,0 +1,240 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ContractOutput 只读方法的一个返回值
+type ContractOutput struct {
+	Name  string `json:"name"`  // ABI 中的返回值名 (可能为空)
+	Type  string `json:"type"`  // ABI 类型，如 uint256 / address / (uint64,bytes32)
+	Value any    `json:"value"` // JSON 友好的值 (见 jsonValue)
+}
+
+// ContractCallError 调用参数与 ABI 不符 (方法不存在、不是只读方法、参数个数或类型错误)
+type ContractCallError struct {
+	Contract string
+	Method   string
+	Reason   string
+}
+
+func (e *ContractCallError) Error() string {
+	return fmt.Sprintf("%s.%s: %s", e.Contract, e.Method, e.Reason)
+}
+
+// Call 调用合约在链 chainID 上的只读 (view / pure) 方法，地址按别名 + chain_id 解析 (见 Resolve)
+// args 为 JSON 数组，按 ABI 转换：整数可以是数字或十进制 / 0x 字符串，地址和字节为 0x 十六进制，tuple 为对象 (按字段名) 或数组
+// blockNumber 为 nil 表示 latest；早于部署区块的高度返回 ErrContractNotDeployed；quorum 为 true 时走仲裁读
+func (r *ContractRegistry) Call(ctx context.Context, chainID int64, name, method string, args json.RawMessage, blockNumber *big.Int, quorum bool) (Deployment, []ContractOutput, error) {
+	// 1. 合约、地址与方法
+	c, dep, err := r.Resolve(name, chainID)
+	if err != nil {
+		return Deployment{}, nil, err
+	}
+	if blockNumber != nil && blockNumber.Sign() >= 0 && dep.DeployBlock > 0 && blockNumber.Cmp(new(big.Int).SetUint64(dep.DeployBlock)) < 0 {
+		return Deployment{}, nil, fmt.Errorf("%w: %s on chain %d before deploy block %d", ErrContractNotDeployed, name, chainID, dep.DeployBlock)
+	}
+	m, ok := c.ABI.Methods[method]
+	if !ok {
+		return Deployment{}, nil, &ContractCallError{Contract: name, Method: method, Reason: "method not found in abi"}
+	}
+	if !m.IsConstant() {
+		return Deployment{}, nil, &ContractCallError{Contract: name, Method: method, Reason: "not a view / pure method"}
+	}
+
+	// 2. 参数：JSON -> ABI 类型 -> calldata
+	var raw []json.RawMessage
+	if len(args) > 0 && string(args) != "null" {
+		if err := json.Unmarshal(args, &raw); err != nil {
+			return Deployment{}, nil, &ContractCallError{Contract: name, Method: method, Reason: "args must be a JSON array"}
+		}
+	}
+	if len(raw) != len(m.Inputs) {
+		return Deployment{}, nil, &ContractCallError{Contract: name, Method: method, Reason: fmt.Sprintf("expected %d args %s, got %d", len(m.Inputs), m.Sig, len(raw))}
+	}
+	values := make([]any, len(raw))
+	for i, input := range m.Inputs {
+		v, err := abiArg(input.Type, raw[i])
+		if err != nil {
+			return Deployment{}, nil, &ContractCallError{Contract: name, Method: method, Reason: fmt.Sprintf("arg %d (%s %s): %v", i, input.Type.String(), input.Name, err)}
+		}
+		values[i] = v
+	}
+	data, err := c.ABI.Pack(method, values...)
+	if err != nil {
+		return Deployment{}, nil, &ContractCallError{Contract: name, Method: method, Reason: err.Error()}
+	}
+
+	// 3. 调用并按 ABI 解码返回值
+	client := r.rpcMgr.Client(chainID)
+	call := client.CallContract
+	if quorum {
+		call = client.Quorum().CallContract
+	}
+	out, err := call(ctx, ethereum.CallMsg{To: &dep.Address, Data: data}, blockNumber)
+	if err != nil {
+		return Deployment{}, nil, err
+	}
+	decoded, err := m.Outputs.Unpack(out)
+	if err != nil {
+		return Deployment{}, nil, fmt.Errorf("%s.%s: decode output: %w", name, method, err)
+	}
+	outputs := make([]ContractOutput, len(decoded))
+	for i, v := range decoded {
+		outputs[i] = ContractOutput{Name: m.Outputs[i].Name, Type: m.Outputs[i].Type.String(), Value: jsonValue(v)}
+	}
+	return dep, outputs, nil
+}
+
+// abiArg 把一个 JSON 参数转换成 ABI Pack 需要的 Go 类型
+func abiArg(t abi.Type, raw json.RawMessage) (any, error) {
+	v, err := abiValue(t, raw)
+	if err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+func abiValue(t abi.Type, raw json.RawMessage) (reflect.Value, error) {
+	goType := t.GetType()
+	switch t.T {
+	case abi.IntTy, abi.UintTy:
+		n, err := jsonInt(raw)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if !intInRange(n, t) {
+			return reflect.Value{}, fmt.Errorf("value out of range for %s", t.String())
+		}
+		if goType == reflect.TypeOf(&big.Int{}) {
+			return reflect.ValueOf(n), nil
+		}
+		if t.T == abi.UintTy {
+			return reflect.ValueOf(n.Uint64()).Convert(goType), nil
+		}
+		return reflect.ValueOf(n.Int64()).Convert(goType), nil
+
+	case abi.BoolTy:
+		var b bool
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return reflect.Value{}, fmt.Errorf("expected true / false")
+		}
+		return reflect.ValueOf(b), nil
+
+	case abi.StringTy:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return reflect.Value{}, fmt.Errorf("expected a string")
+		}
+		return reflect.ValueOf(s), nil
+
+	case abi.AddressTy:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil || !common.IsHexAddress(s) {
+			return reflect.Value{}, fmt.Errorf("expected a hex address")
+		}
+		return reflect.ValueOf(common.HexToAddress(s)), nil
+
+	case abi.BytesTy, abi.FixedBytesTy:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return reflect.Value{}, fmt.Errorf("expected 0x hex bytes")
+		}
+		b, err := hexutil.Decode(s)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("expected 0x hex bytes")
+		}
+		if t.T == abi.BytesTy {
+			return reflect.ValueOf(b), nil
+		}
+		if len(b) != t.Size {
+			return reflect.Value{}, fmt.Errorf("expected %d bytes, got %d", t.Size, len(b))
+		}
+		arr := reflect.New(goType).Elem()
+		reflect.Copy(arr, reflect.ValueOf(b))
+		return arr, nil
+
+	case abi.SliceTy, abi.ArrayTy:
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return reflect.Value{}, fmt.Errorf("expected an array")
+		}
+		var out reflect.Value
+		if t.T == abi.ArrayTy {
+			if len(items) != t.Size {
+				return reflect.Value{}, fmt.Errorf("expected %d items, got %d", t.Size, len(items))
+			}
+			out = reflect.New(goType).Elem()
+		} else {
+			out = reflect.MakeSlice(goType, len(items), len(items))
+		}
+		for i, item := range items {
+			v, err := abiValue(*t.Elem, item)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("[%d]: %w", i, err)
+			}
+			out.Index(i).Set(v)
+		}
+		return out, nil
+
+	case abi.TupleTy:
+		// 对象按字段名，数组按顺序
+		items := make([]json.RawMessage, len(t.TupleElems))
+		var byName map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &byName); err == nil {
+			for i, name := range t.TupleRawNames {
+				v, ok := byName[name]
+				if !ok {
+					return reflect.Value{}, fmt.Errorf("missing field %q", name)
+				}
+				items[i] = v
+			}
+		} else if err := json.Unmarshal(raw, &items); err != nil || len(items) != len(t.TupleElems) {
+			return reflect.Value{}, fmt.Errorf("expected an object or an array of %d items", len(t.TupleElems))
+		}
+		out := reflect.New(goType).Elem()
+		for i, elem := range t.TupleElems {
+			v, err := abiValue(*elem, items[i])
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("%s: %w", t.TupleRawNames[i], err)
+			}
+			out.Field(i).Set(v)
+		}
+		return out, nil
+	}
+	return reflect.Value{}, fmt.Errorf("unsupported type %s", t.String())
+}
+
+// intInRange uintN: [0, 2^N)；intN: [-2^(N-1), 2^(N-1))
+func intInRange(n *big.Int, t abi.Type) bool {
+	if t.T == abi.UintTy {
+		return n.Sign() >= 0 && n.BitLen() <= t.Size
+	}
+	limit := new(big.Int).Lsh(big.NewInt(1), uint(t.Size-1))
+	return n.Cmp(limit) < 0 && n.Cmp(new(big.Int).Neg(limit)) >= 0
+}
+
+// jsonInt 整数参数：JSON 数字，或十进制 / 0x 十六进制字符串 (大整数建议用字符串，避免精度丢失)
+func jsonInt(raw json.RawMessage) (*big.Int, error) {
+	s := strings.TrimSpace(string(raw))
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, fmt.Errorf("expected an integer")
+		}
+	}
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("expected an integer")
+	}
+	return n, nil
+}
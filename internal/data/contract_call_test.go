@@ -0,0 +1,75 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestABIValue(t *testing.T) {
+	addr := common.HexToAddress("0x00000000000000000000000000000000000000aa").Hex()
+	transfer := []abi.ArgumentMarshaling{{Name: "to", Type: "address"}, {Name: "amount", Type: "uint256"}}
+	tests := []struct {
+		name       string
+		typ        string
+		components []abi.ArgumentMarshaling
+		raw        string
+		want       string // fmt.Sprint(v.Interface())
+		wantErr    bool
+	}{
+		{name: "uint256 number", typ: "uint256", raw: `42`, want: "42"},
+		{name: "uint256 decimal string", typ: "uint256", raw: `"1000000000000000000000"`, want: "1000000000000000000000"},
+		{name: "uint256 hex string", typ: "uint256", raw: `"0xff"`, want: "255"},
+		{name: "uint8 max", typ: "uint8", raw: `255`, want: "255"},
+		{name: "uint8 overflow", typ: "uint8", raw: `256`, wantErr: true},
+		{name: "uint negative", typ: "uint64", raw: `-1`, wantErr: true},
+		{name: "int8 min", typ: "int8", raw: `-128`, want: "-128"},
+		{name: "int8 overflow", typ: "int8", raw: `128`, wantErr: true},
+		{name: "int256 negative", typ: "int256", raw: `"-5"`, want: "-5"},
+		{name: "not an integer", typ: "uint256", raw: `"abc"`, wantErr: true},
+		{name: "bool", typ: "bool", raw: `true`, want: "true"},
+		{name: "bool from string", typ: "bool", raw: `"true"`, wantErr: true},
+		{name: "string", typ: "string", raw: `"hello"`, want: "hello"},
+		{name: "address", typ: "address", raw: `"0x00000000000000000000000000000000000000aa"`, want: addr},
+		{name: "bad address", typ: "address", raw: `"0x1234"`, wantErr: true},
+		{name: "bytes", typ: "bytes", raw: `"0x0102"`, want: "[1 2]"},
+		{name: "bytes4", typ: "bytes4", raw: `"0x01020304"`, want: "[1 2 3 4]"},
+		{name: "bytes4 wrong size", typ: "bytes4", raw: `"0x0102"`, wantErr: true},
+		{name: "bytes without 0x", typ: "bytes", raw: `"0102"`, wantErr: true},
+		{name: "uint256 slice", typ: "uint256[]", raw: `[1, "2", "0x3"]`, want: "[1 2 3]"},
+		{name: "fixed array", typ: "bool[2]", raw: `[true, false]`, want: "[true false]"},
+		{name: "fixed array wrong length", typ: "bool[2]", raw: `[true]`, wantErr: true},
+		{name: "slice item error", typ: "uint8[]", raw: `[1, 300]`, wantErr: true},
+		{name: "tuple from object", typ: "tuple", components: transfer, raw: `{"to":"0x00000000000000000000000000000000000000aa","amount":"5"}`, want: "{" + addr + " 5}"},
+		{name: "tuple from array", typ: "tuple", components: transfer, raw: `["0x00000000000000000000000000000000000000aa", 5]`, want: "{" + addr + " 5}"},
+		{name: "tuple missing field", typ: "tuple", components: transfer, raw: `{"to":"0x00000000000000000000000000000000000000aa"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			typ, err := abi.NewType(tt.typ, "", tt.components)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := abiValue(typ, json.RawMessage(tt.raw))
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("abiValue(%s, %s) = %v, want error", tt.typ, tt.raw, v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("abiValue(%s, %s) unexpected error: %v", tt.typ, tt.raw, err)
+			}
+			if got := fmt.Sprint(v.Interface()); got != tt.want {
+				t.Errorf("abiValue(%s, %s) = %s, want %s", tt.typ, tt.raw, got, tt.want)
+			}
+			// 转换结果必须能被 ABI 编码
+			if _, err := (abi.Arguments{{Type: typ}}).Pack(v.Interface()); err != nil {
+				t.Errorf("pack %s: %v", tt.typ, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,457 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+	"github.com/zy99978455-otw/go-micro-template/pkg/global"
+)
+
+const defaultContractValidateTimeout = 10 * time.Second
+
+var (
+	ErrContractNotFound    = errors.New("contract not found")
+	ErrContractNotDeployed = errors.New("contract not deployed on this chain")
+)
+
+// Contract 已注册的合约 (来自 contracts 配置)
+type Contract struct {
+	Name        string
+	Address     common.Address // 默认地址 (未在 Deployments 中配置的链使用)，可为空
+	ABI         abi.ABI
+	Deployments map[int64]Deployment // 按链配置的部署
+}
+
+// Deployment 合约在某条链上的部署
+type Deployment struct {
+	ChainID     int64
+	Address     common.Address
+	DeployBlock uint64 // 0 表示未知
+}
+
+// Deployment 合约在某条链上的地址：优先按链配置，否则使用默认地址；都没有时 ok = false
+func (c *Contract) Deployment(chainID int64) (Deployment, bool) {
+	if d, ok := c.Deployments[chainID]; ok {
+		return d, true
+	}
+	if c.Address != (common.Address{}) {
+		return Deployment{ChainID: chainID, Address: c.Address}, true
+	}
+	return Deployment{}, false
+}
+
+// ContractInfo 合约概要 (列表接口用)
+type ContractInfo struct {
+	Name        string                   `json:"name"`
+	Address     string                   `json:"address"`
+	Deployments []ContractDeploymentInfo `json:"deployments"` // 按链配置的地址
+	Methods     []string                 `json:"methods"`     // 只读方法签名 (重载方法的名字带序号，如 foo0)
+	Events      []string                 `json:"events"`      // 事件签名
+	Deployed    []int64                  `json:"deployed"`    // 启动校验时确认有合约代码的链
+}
+
+// ContractDeploymentInfo 按链配置的部署 (列表接口用)
+type ContractDeploymentInfo struct {
+	ChainID     int64  `json:"chain_id"`
+	Address     string `json:"address"`
+	DeployBlock uint64 `json:"deploy_block,omitempty"`
+}
+
+// ContractRegistry 合约注册表
+// 1. 启动时解析 contracts 配置中的 ABI (内容或文件路径) 并校验地址
+// 2. 按别名 + chain_id 解析合约地址 (Resolve)，Validate 检查各条链上的地址是否有代码
+// 3. 提供事件日志解码 (DecodeLog) 和只读方法调用 (Call)
+type ContractRegistry struct {
+	rpcMgr    *RPCManager
+	contracts []*Contract
+	byName    map[string]*Contract
+	byAddress map[common.Address][]*Contract // 地址 (默认地址或任意链上的地址) -> 合约，解码时再按链核对
+	byTopic   map[common.Hash][]*Contract    // 事件签名 -> 定义了该事件的合约 (按配置顺序)
+
+	mu       sync.RWMutex
+	deployed map[string]map[int64]bool // 合约名 -> 链 -> 是否有代码 (没有记录表示未校验或校验失败)
+}
+
+// NewContractRegistry 构造函数；配置不合法 (名字重复、地址或 ABI 错误) 的合约会被跳过 (记录日志)，不影响启动
+func NewContractRegistry(cfg *config.AppConfig, rpcMgr *RPCManager) *ContractRegistry {
+	r := &ContractRegistry{
+		rpcMgr:    rpcMgr,
+		byName:    make(map[string]*Contract),
+		byAddress: make(map[common.Address][]*Contract),
+		byTopic:   make(map[common.Hash][]*Contract),
+		deployed:  make(map[string]map[int64]bool),
+	}
+	for _, cc := range cfg.Contracts {
+		c, err := loadContract(cc)
+		if err == nil && r.byName[c.Name] != nil {
+			err = fmt.Errorf("duplicate contract name")
+		}
+		if err != nil {
+			if global.Log != nil {
+				global.Log.Warnf("⚠️ [Contract] skip %q: %v", cc.Name, err)
+			}
+			continue
+		}
+		r.contracts = append(r.contracts, c)
+		r.byName[c.Name] = c
+		if c.Address != (common.Address{}) {
+			r.byAddress[c.Address] = append(r.byAddress[c.Address], c)
+		}
+		for _, d := range c.Deployments {
+			if d.Address != c.Address {
+				r.byAddress[d.Address] = append(r.byAddress[d.Address], c)
+			}
+		}
+		for _, ev := range c.ABI.Events {
+			r.byTopic[ev.ID] = append(r.byTopic[ev.ID], c)
+		}
+		if global.Log != nil {
+			global.Log.Infof("📜 [Contract] %s registered (%s, %d chain deployments, %d methods, %d events)", c.Name, c.Address.Hex(), len(c.Deployments), len(c.ABI.Methods), len(c.ABI.Events))
+		}
+	}
+	return r
+}
+
+// Validate 检查每个合约在每条链上解析出的地址是否有代码 (启动时在 RPC Manager 完成首轮检查后调用)
+// 在某条链上确认没有代码的合约，在该链上调用会直接报错；RPC 失败时只记录日志，不阻止调用
+func (r *ContractRegistry) Validate(ctx context.Context) {
+	if r == nil || r.rpcMgr == nil || len(r.contracts) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, defaultContractValidateTimeout)
+	defer cancel()
+
+	chainIDs := make([]int64, 0)
+	for chainID := range r.rpcMgr.allNodes() {
+		chainIDs = append(chainIDs, chainID)
+	}
+	sort.Slice(chainIDs, func(i, j int) bool { return chainIDs[i] < chainIDs[j] })
+
+	var wg sync.WaitGroup
+	for _, c := range r.contracts {
+		for _, chainID := range chainIDs {
+			d, ok := c.Deployment(chainID)
+			if !ok {
+				continue // 该链上没有地址 (或只用于解码日志的 ABI)
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				code, err := r.rpcMgr.Client(chainID).CodeAt(ctx, d.Address, nil)
+				if err != nil {
+					if global.Log != nil {
+						global.Log.Warnf("⚠️ [Contract] %s: check code on chain %d failed: %v", c.Name, chainID, err)
+					}
+					return
+				}
+				r.mu.Lock()
+				if r.deployed[c.Name] == nil {
+					r.deployed[c.Name] = make(map[int64]bool)
+				}
+				r.deployed[c.Name][chainID] = len(code) > 0
+				r.mu.Unlock()
+			}()
+		}
+	}
+	wg.Wait()
+
+	if global.Log == nil {
+		return
+	}
+	for _, c := range r.contracts {
+		for _, chainID := range chainIDs {
+			d, ok := c.Deployment(chainID)
+			if !ok {
+				continue
+			}
+			r.mu.RLock()
+			deployed, checked := r.deployed[c.Name][chainID]
+			r.mu.RUnlock()
+			if checked && !deployed {
+				global.Log.Warnf("⚠️ [Contract] %s (%s) has no code on chain %d", c.Name, d.Address.Hex(), chainID)
+			}
+		}
+		for chainID := range c.Deployments {
+			if _, ok := r.rpcMgr.allNodes()[chainID]; !ok {
+				global.Log.Warnf("⚠️ [Contract] %s: deployment on chain %d ignored, chain not configured", c.Name, chainID)
+			}
+		}
+		if chains := r.deployedChains(c.Name); len(chains) > 0 {
+			global.Log.Infof("✅ [Contract] %s deployed on chains %v", c.Name, chains)
+		}
+	}
+}
+
+// deployedChains 校验时确认有代码的链
+func (r *ContractRegistry) deployedChains(name string) []int64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	chains := make([]int64, 0)
+	for chainID, ok := range r.deployed[name] {
+		if ok {
+			chains = append(chains, chainID)
+		}
+	}
+	sort.Slice(chains, func(i, j int) bool { return chains[i] < chains[j] })
+	return chains
+}
+
+// Contract 按别名查找合约
+func (r *ContractRegistry) Contract(name string) (*Contract, error) {
+	if r == nil || r.byName[name] == nil {
+		return nil, fmt.Errorf("%w: %q", ErrContractNotFound, name)
+	}
+	return r.byName[name], nil
+}
+
+// Resolve 按别名 + chain_id 解析合约地址
+// 该链上没有配置地址，或启动校验确认该地址没有代码时返回 ErrContractNotDeployed
+func (r *ContractRegistry) Resolve(name string, chainID int64) (*Contract, Deployment, error) {
+	c, err := r.Contract(name)
+	if err != nil {
+		return nil, Deployment{}, err
+	}
+	d, ok := c.Deployment(chainID)
+	if !ok {
+		return nil, Deployment{}, fmt.Errorf("%w: %s has no address on chain %d", ErrContractNotDeployed, name, chainID)
+	}
+	r.mu.RLock()
+	deployed, checked := r.deployed[name][chainID]
+	r.mu.RUnlock()
+	if checked && !deployed {
+		return nil, Deployment{}, fmt.Errorf("%w: %s (%s) on chain %d", ErrContractNotDeployed, name, d.Address.Hex(), chainID)
+	}
+	return c, d, nil
+}
+
+// LogQuery 合约在链 chainID 上的日志查询：地址按别名 + chain_id 解析，起始高度不早于部署区块
+// q.FromBlock 为空时从部署区块开始 (部署区块未知时保持为空)；q.Addresses 会被覆盖，按 BlockHash 查询时不调整区块范围
+// 返回的查询可以直接用于 FilterLogs，或作为 SubscriptionManager.SubscribeLogs 的起点
+func (r *ContractRegistry) LogQuery(name string, chainID int64, q ethereum.FilterQuery) (ethereum.FilterQuery, error) {
+	_, dep, err := r.Resolve(name, chainID)
+	if err != nil {
+		return ethereum.FilterQuery{}, err
+	}
+	q.Addresses = []common.Address{dep.Address}
+	if q.BlockHash == nil && dep.DeployBlock > 0 && (q.FromBlock == nil || q.FromBlock.Sign() >= 0 && q.FromBlock.Uint64() < dep.DeployBlock) {
+		q.FromBlock = new(big.Int).SetUint64(dep.DeployBlock)
+	}
+	return q, nil
+}
+
+// FilterLogs 查询合约在链 chainID 上的日志 (见 LogQuery)；结束高度早于部署区块时直接返回空
+func (r *ContractRegistry) FilterLogs(ctx context.Context, chainID int64, name string, q ethereum.FilterQuery) ([]types.Log, error) {
+	q, err := r.LogQuery(name, chainID, q)
+	if err != nil {
+		return nil, err
+	}
+	if q.FromBlock != nil && q.ToBlock != nil && q.ToBlock.Sign() >= 0 && q.ToBlock.Cmp(q.FromBlock) < 0 {
+		return []types.Log{}, nil
+	}
+	return r.rpcMgr.Client(chainID).FilterLogs(ctx, q)
+}
+
+// Contracts 已注册合约的概要 (按配置顺序)
+func (r *ContractRegistry) Contracts() []ContractInfo {
+	if r == nil {
+		return []ContractInfo{}
+	}
+	out := make([]ContractInfo, 0, len(r.contracts))
+	for _, c := range r.contracts {
+		info := ContractInfo{Name: c.Name, Deployments: []ContractDeploymentInfo{}, Methods: []string{}, Events: []string{}, Deployed: r.deployedChains(c.Name)}
+		if c.Address != (common.Address{}) {
+			info.Address = c.Address.Hex()
+		}
+		for _, d := range c.Deployments {
+			info.Deployments = append(info.Deployments, ContractDeploymentInfo{ChainID: d.ChainID, Address: d.Address.Hex(), DeployBlock: d.DeployBlock})
+		}
+		sort.Slice(info.Deployments, func(i, j int) bool { return info.Deployments[i].ChainID < info.Deployments[j].ChainID })
+		for name, m := range c.ABI.Methods {
+			if m.IsConstant() {
+				info.Methods = append(info.Methods, name+m.Sig[len(m.RawName):])
+			}
+		}
+		for _, ev := range c.ABI.Events {
+			info.Events = append(info.Events, ev.Sig)
+		}
+		sort.Strings(info.Methods)
+		sort.Strings(info.Events)
+		out = append(out, info)
+	}
+	return out
+}
+
+// loadContract 解析一条合约配置：abi_json 以 [ 开头视为 ABI 内容，否则视为文件路径
+func loadContract(cc config.ContractConfig) (*Contract, error) {
+	if cc.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if cc.Address != "" && !common.IsHexAddress(cc.Address) {
+		return nil, fmt.Errorf("invalid address %q", cc.Address)
+	}
+	deployments := make(map[int64]Deployment, len(cc.Deployments))
+	for _, dc := range cc.Deployments {
+		switch {
+		case dc.ChainID <= 0:
+			return nil, fmt.Errorf("deployment: chain_id is required")
+		case !common.IsHexAddress(dc.Address):
+			return nil, fmt.Errorf("deployment on chain %d: invalid address %q", dc.ChainID, dc.Address)
+		}
+		if _, dup := deployments[dc.ChainID]; dup {
+			return nil, fmt.Errorf("duplicate deployment on chain %d", dc.ChainID)
+		}
+		deployments[dc.ChainID] = Deployment{ChainID: dc.ChainID, Address: common.HexToAddress(dc.Address), DeployBlock: dc.DeployBlock}
+	}
+
+	raw := strings.TrimSpace(cc.AbiJson)
+	if raw == "" {
+		return nil, fmt.Errorf("abi_json is required")
+	}
+	if !strings.HasPrefix(raw, "[") {
+		b, err := os.ReadFile(raw)
+		if err != nil {
+			return nil, fmt.Errorf("read abi file: %w", err)
+		}
+		raw = string(b)
+	}
+	parsed, err := abi.JSON(strings.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("parse abi: %w", err)
+	}
+
+	return &Contract{Name: cc.Name, Address: common.HexToAddress(cc.Address), ABI: parsed, Deployments: deployments}, nil
+}
+
+// DecodedLog 按 ABI 解码的事件
+type DecodedLog struct {
+	Contract  string         `json:"contract"`  // 用于解码的合约别名
+	Event     string         `json:"event"`     // 事件名
+	Signature string         `json:"signature"` // 例如 Transfer(address,address,uint256)
+	Args      map[string]any `json:"args"`      // 参数名 -> JSON 友好的值 (大整数为十进制字符串，字节为 0x 十六进制)
+}
+
+// DecodeLog 解码链 chainID 上的一条日志
+// 1. 优先使用在该链上地址匹配的合约的 ABI
+// 2. 否则尝试任意定义了同签名事件的 ABI (例如用一份 ERC-20 ABI 解码所有 Token 的 Transfer)
+// 都无法解码时返回 nil
+func (r *ContractRegistry) DecodeLog(chainID int64, l *types.Log) *DecodedLog {
+	if r == nil || len(l.Topics) == 0 {
+		return nil
+	}
+	for _, c := range r.byAddress[l.Address] {
+		if dep, ok := c.Deployment(chainID); !ok || dep.Address != l.Address {
+			continue
+		}
+		if d := decodeWith(c, l); d != nil {
+			return d
+		}
+	}
+	for _, c := range r.byTopic[l.Topics[0]] {
+		if d := decodeWith(c, l); d != nil {
+			return d
+		}
+	}
+	return nil
+}
+
+// decodeWith 用合约的 ABI 解码；indexed 参数个数与 topics 不符 (同名不同布局) 时返回 nil
+func decodeWith(c *Contract, l *types.Log) *DecodedLog {
+	ev, err := c.ABI.EventByID(l.Topics[0])
+	if err != nil || ev.Anonymous {
+		return nil
+	}
+	var indexed abi.Arguments
+	for _, arg := range ev.Inputs {
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		}
+	}
+	if len(indexed) != len(l.Topics)-1 {
+		return nil
+	}
+
+	args := make(map[string]any, len(ev.Inputs))
+	if err := ev.Inputs.UnpackIntoMap(args, l.Data); err != nil {
+		return nil
+	}
+	if err := abi.ParseTopicsIntoMap(args, indexed, l.Topics[1:]); err != nil {
+		return nil
+	}
+	for k, v := range args {
+		args[k] = jsonValue(v)
+	}
+
+	return &DecodedLog{Contract: c.Name, Event: ev.Name, Signature: ev.Sig, Args: args}
+}
+
+// jsonValue 把 ABI 解码出的 Go 值转换成 JSON 友好的形式
+// 64 位及以上整数 -> 十进制字符串 (避免 JS 精度丢失)，地址 / 哈希 / 字节 -> 0x 十六进制，tuple -> 按字段名的 map
+func jsonValue(v any) any {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case *big.Int:
+		return x.String()
+	case common.Address:
+		return x.Hex()
+	case common.Hash:
+		return x.Hex()
+	case []byte:
+		return hexutil.Encode(x)
+	case string, bool:
+		return x
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int64:
+		return big.NewInt(rv.Int()).String()
+	case reflect.Uint64:
+		return new(big.Int).SetUint64(rv.Uint()).String()
+	case reflect.Array:
+		// bytesN
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return hexutil.Encode(b)
+		}
+		fallthrough
+	case reflect.Slice:
+		out := make([]any, rv.Len())
+		for i := range out {
+			out[i] = jsonValue(rv.Index(i).Interface())
+		}
+		return out
+	case reflect.Struct:
+		out := make(map[string]any, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			f := rv.Type().Field(i)
+			name := f.Name
+			if tag := f.Tag.Get("json"); tag != "" {
+				name = tag
+			}
+			out[name] = jsonValue(rv.Field(i).Interface())
+		}
+		return out
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		return jsonValue(rv.Elem().Interface())
+	}
+	return v
+}
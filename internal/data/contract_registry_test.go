@@ -0,0 +1,77 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+)
+
+func TestFilterLogsStartsAtDeployBlock(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		from []string // 每次 eth_getLogs 的 fromBlock
+	)
+	srv := newTestRPCServer(t, 1, func(method string, params []json.RawMessage) (any, *rpcTestError, bool) {
+		if method != "eth_getLogs" {
+			return nil, nil, false
+		}
+		var arg struct {
+			FromBlock string `json:"fromBlock"`
+		}
+		_ = json.Unmarshal(params[0], &arg)
+		mu.Lock()
+		from = append(from, arg.FromBlock)
+		mu.Unlock()
+		return []any{}, nil, true
+	})
+	chain := config.ChainConfig{ChainID: 1, Nodes: []config.NodeConfig{{RpcUrl: srv.URL}}}
+	mgr := startTestManager(t, chain)
+	reg := NewContractRegistry(&config.AppConfig{Contracts: []config.ContractConfig{{
+		Name:        "vault",
+		AbiJson:     `[]`,
+		Deployments: []config.ContractDeploymentConfig{{ChainID: 1, Address: "0x00000000000000000000000000000000000000aa", DeployBlock: 100}},
+	}}}, mgr)
+
+	tests := []struct {
+		name     string
+		from, to *big.Int
+		want     string // 发出的 fromBlock，空表示不查询
+	}{
+		{name: "no start", want: "0x64"},
+		{name: "before deploy block", from: big.NewInt(50), want: "0x64"},
+		{name: "after deploy block", from: big.NewInt(200), want: "0xc8"},
+		{name: "range ends before deploy block", from: big.NewInt(10), to: big.NewInt(50)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			from = nil
+			mu.Unlock()
+
+			logs, err := reg.FilterLogs(context.Background(), 1, "vault", ethereum.FilterQuery{FromBlock: tt.from, ToBlock: tt.to})
+			if err != nil {
+				t.Fatalf("FilterLogs() error: %v", err)
+			}
+			if logs == nil {
+				t.Error("FilterLogs() = nil, want empty slice")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case tt.want == "" && len(from) != 0:
+				t.Errorf("sent eth_getLogs from %v, want no request", from)
+			case tt.want != "" && (len(from) != 1 || from[0] != tt.want):
+				t.Errorf("eth_getLogs fromBlock = %v, want [%s]", from, tt.want)
+			}
+		})
+	}
+
+	if _, err := reg.LogQuery("vault", 56, ethereum.FilterQuery{}); err == nil {
+		t.Error("LogQuery() on a chain without deployment succeeded")
+	}
+}
@@ -1,6 +1,8 @@
 package data
 
 import (
+	"context"
+
 	"github.com/google/wire" // 引入 wire
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
@@ -10,25 +12,56 @@ import (
 )
 
 // 🔥 定义 ProviderSet，告诉 Wire data 层有哪些组件
-var ProviderSet = wire.NewSet(NewData, NewRPCManager, NewChainRepo)
+var ProviderSet = wire.NewSet(NewData, NewRPCManager, NewSubscriptionManager, NewChainCache, NewNodeHistory, NewHeadTracker, NewContractRegistry, NewChainRepo)
 
 type Data struct {
 	db         *gorm.DB
 	redis      *redis.Client
 	rpcManager *RPCManager
+	subManager *SubscriptionManager
+	cache      *ChainCache
+	history    *NodeHistory
+	heads      *HeadTracker
+	contracts  *ContractRegistry
 }
 
 // NewData 显式接收依赖
-// 参数 db, redis, rpcMgr 都会由 Wire 自动注入
-func NewData(db *gorm.DB, rdb *redis.Client, rpcMgr *RPCManager) (*Data, func(), error) {
+// 参数 db, redis, rpcMgr, subMgr, cache, history, heads, contracts 都会由 Wire 自动注入
+func NewData(db *gorm.DB, rdb *redis.Client, rpcMgr *RPCManager, subMgr *SubscriptionManager, cache *ChainCache, history *NodeHistory, heads *HeadTracker, contracts *ContractRegistry) (*Data, func(), error) {
 	d := &Data{
 		db:         db,
 		redis:      rdb,
 		rpcManager: rpcMgr,
+		subManager: subMgr,
+		cache:      cache,
+		history:    history,
+		heads:      heads,
+		contracts:  contracts,
+	}
+
+	// 链重组时清理未最终确认的缓存；缓存按跟踪器随链头刷新的最终确认高度决定 TTL
+	if heads != nil && cache != nil {
+		cache.heads = heads
+		heads.OnReorg(func(ev ReorgEvent) {
+			cache.InvalidateFrom(context.Background(), ev.ChainID, ev.FromBlock())
+		})
 	}
 
 	cleanup := func() {
 		global.Log.Info("正在关闭 Data 层资源...")
+		// 先停健康历史写入 (写入依赖 DB 和 RPC Manager)
+		history.Close()
+		// 先停链头跟踪 (依赖订阅)，再停订阅 (订阅补齐依赖 RPC Manager)，最后停 RPC
+		if heads != nil {
+			heads.Close()
+		}
+		if subMgr != nil {
+			subMgr.Close()
+		}
+		// 停止 RPC 健康检查并关闭所有节点连接
+		if rpcMgr != nil {
+			rpcMgr.Close()
+		}
 	}
 
 	return d, cleanup, nil
@@ -38,6 +71,43 @@ func (d *Data) GetRPCClient(chainID int64) (*ethclient.Client, error) {
 	return d.rpcManager.GetClient(chainID)
 }
 
+// GetChainClient 获取带故障转移和 Redis 缓存的链客户端门面
+func (d *Data) GetChainClient(chainID int64) *ChainClient {
+	client := d.rpcManager.Client(chainID)
+	client.cache = d.cache
+	return client
+}
+
+// GetRPCManager 获取 RPC 节点管理器 (管理接口使用)
+func (d *Data) GetRPCManager() *RPCManager {
+	return d.rpcManager
+}
+
+// GetChainCache 获取链数据缓存 (Redis 未配置时为 nil)
+func (d *Data) GetChainCache() *ChainCache {
+	return d.cache
+}
+
+// GetNodeHistory 获取节点健康历史 (MySQL 未配置时为 nil)
+func (d *Data) GetNodeHistory() *NodeHistory {
+	return d.history
+}
+
+// GetHeadTracker 获取链头跟踪器 (订阅重组事件、查询最近的规范链哈希)
+func (d *Data) GetHeadTracker() *HeadTracker {
+	return d.heads
+}
+
+// GetContractRegistry 获取合约注册表 (contracts 配置中的 ABI)
+func (d *Data) GetContractRegistry() *ContractRegistry {
+	return d.contracts
+}
+
+// GetSubscriptionManager 获取 WSS 订阅管理器
+func (d *Data) GetSubscriptionManager() *SubscriptionManager {
+	return d.subManager
+}
+
 func (d *Data) GetDB() *gorm.DB {
 	return d.db
 }
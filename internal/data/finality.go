@@ -0,0 +1,140 @@
+package data
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+	"github.com/zy99978455-otw/go-micro-template/pkg/global"
+)
+
+const defaultConfirmations = 12
+
+// safe / finalized 高度的来源
+const (
+	FinalitySourceTag           = "tag"           // 节点的 safe / finalized 标签
+	FinalitySourceConfirmations = "confirmations" // 按 latest - confirmations 推算
+)
+
+// finalityPolicy 确认策略
+type finalityPolicy struct {
+	confirmations uint64
+	useTags       bool
+}
+
+func newFinalityPolicy(c config.FinalityConfig) finalityPolicy {
+	p := finalityPolicy{confirmations: c.Confirmations, useTags: !c.DisableTags}
+	if p.confirmations == 0 {
+		p.confirmations = defaultConfirmations
+	}
+	return p
+}
+
+// finalityPolicy 获取链的确认策略
+func (m *RPCManager) finalityPolicy(chainID int64) finalityPolicy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return newFinalityPolicy(m.chains[chainID].Finality)
+}
+
+// FinalityHeights 一条链各个确认级别的高度
+type FinalityHeights struct {
+	Latest        uint64 `json:"latest"`
+	Safe          uint64 `json:"safe"`
+	Finalized     uint64 `json:"finalized"`
+	Confirmed     uint64 `json:"confirmed"` // latest - confirmations
+	Confirmations uint64 `json:"confirmations"`
+	Source        string `json:"source"` // safe / finalized 的来源: tag / confirmations
+}
+
+// FinalityHeights 查询 latest / safe / finalized / N 确认高度
+// 1. 默认使用节点的 safe / finalized 标签 (与 latest 并发查询)
+// 2. 链配置了 finality.disable_tags，或节点不支持这两个标签时，safe / finalized 都退化为 latest - confirmations
+func (c *ChainClient) FinalityHeights(ctx context.Context) (*FinalityHeights, error) {
+	policy := c.mgr.finalityPolicy(c.chainID)
+
+	var (
+		wg              sync.WaitGroup
+		safe, finalized uint64
+		tagErr          error
+		tagMu           sync.Mutex
+	)
+	if policy.useTags {
+		for _, tag := range []rpc.BlockNumber{rpc.SafeBlockNumber, rpc.FinalizedBlockNumber} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				n, err := c.tagHeight(ctx, tag)
+				tagMu.Lock()
+				defer tagMu.Unlock()
+				switch {
+				case err != nil:
+					tagErr = err
+				case tag == rpc.SafeBlockNumber:
+					safe = n
+				default:
+					finalized = n
+				}
+			}()
+		}
+	}
+
+	latest, err := c.BlockNumber(ctx)
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+
+	h := &FinalityHeights{
+		Latest:        latest,
+		Confirmations: policy.confirmations,
+		Confirmed:     latest - min(latest, policy.confirmations),
+	}
+	if policy.useTags && tagErr == nil {
+		// latest 可能来自稍落后的节点或缓存，标签高度不超过 latest
+		h.Safe, h.Finalized, h.Source = min(safe, latest), min(finalized, latest), FinalitySourceTag
+		return h, nil
+	}
+	if policy.useTags {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if global.Log != nil {
+			global.Log.Debugf("⚠️ [Finality] chain %d safe/finalized tags unavailable, using %d confirmations: %v", c.chainID, policy.confirmations, tagErr)
+		}
+	}
+	h.Safe, h.Finalized, h.Source = h.Confirmed, h.Confirmed, FinalitySourceConfirmations
+	return h, nil
+}
+
+// finalizedHeight 链头为 latest 时已最终确认的高度 (规则同 FinalityHeights，只查 finalized 标签)
+func (c *ChainClient) finalizedHeight(ctx context.Context, latest uint64) (uint64, error) {
+	policy := c.mgr.finalityPolicy(c.chainID)
+	if policy.useTags {
+		n, err := c.tagHeight(ctx, rpc.FinalizedBlockNumber)
+		if err == nil {
+			return min(n, latest), nil
+		}
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+	}
+	return latest - min(latest, policy.confirmations), nil
+}
+
+// tagHeight safe / finalized 标签对应的高度；节点返回 null 时为 ethereum.NotFound
+func (c *ChainClient) tagHeight(ctx context.Context, tag rpc.BlockNumber) (uint64, error) {
+	return c.cache.headTag(ctx, c.chainID, tag, func() (uint64, error) {
+		h, err := read(ctx, c, "eth_getBlockByNumber", func(ctx context.Context, cl *ethclient.Client) (*types.Header, error) {
+			return cl.HeaderByNumber(ctx, big.NewInt(int64(tag)))
+		})
+		if err != nil {
+			return 0, err
+		}
+		return h.Number.Uint64(), nil
+	})
+}
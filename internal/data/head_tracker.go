@@ -0,0 +1,450 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+	"github.com/zy99978455-otw/go-micro-template/pkg/global"
+)
+
+const (
+	defaultHeadWindow       = 128
+	defaultHeadPollInterval = 3 * time.Second
+	headFetchTimeout        = 10 * time.Second
+)
+
+// BlockRef 区块引用
+type BlockRef struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+}
+
+// ReorgEvent 一次链重组
+// 旧链上 (CommonAncestor, OldHead] 的区块被 (CommonAncestor, NewHead] 替换
+type ReorgEvent struct {
+	ChainID        int64         `json:"chain_id"`
+	CommonAncestor BlockRef      `json:"common_ancestor"`
+	OldHead        BlockRef      `json:"old_head"`
+	NewHead        BlockRef      `json:"new_head"`
+	Depth          uint64        `json:"depth"`   // 被替换的旧区块数 (OldHead - CommonAncestor)
+	Dropped        []common.Hash `json:"dropped"` // 被替换的旧区块哈希 (高度升序)
+	Added          []common.Hash `json:"added"`   // 新链上的区块哈希 (高度升序)
+	// 共同祖先早于跟踪窗口：CommonAncestor 只是窗口下界，实际重组可能更深
+	BeyondWindow bool      `json:"beyond_window"`
+	DetectedAt   time.Time `json:"detected_at"`
+}
+
+// FromBlock 受影响的最低高度
+func (e ReorgEvent) FromBlock() uint64 {
+	return e.CommonAncestor.Number + 1
+}
+
+// headWindow 一条链最近的区块哈希 ([low, tip] 高度连续，最多 size 个)
+type headWindow struct {
+	size   int
+	hashes map[uint64]common.Hash
+	low    uint64
+	tip    *types.Header
+}
+
+func (w *headWindow) hash(number uint64) (common.Hash, bool) {
+	h, ok := w.hashes[number]
+	return h, ok
+}
+
+// advance 把 headers (高度连续、升序，第一个接在窗口内某个区块之后) 接到窗口上，并裁掉超出窗口的旧区块
+func (w *headWindow) advance(headers []*types.Header) {
+	for _, h := range headers {
+		w.hashes[h.Number.Uint64()] = h.Hash()
+		w.tip = h
+	}
+	tip := w.tip.Number.Uint64()
+	if tip+1 > uint64(w.size) {
+		w.low = max(w.low, tip+1-uint64(w.size))
+	}
+	for n := range w.hashes {
+		if n > tip || n < w.low {
+			delete(w.hashes, n)
+		}
+	}
+}
+
+// reset 从 h 开始重新跟踪
+func (w *headWindow) reset(h *types.Header) {
+	w.hashes = map[uint64]common.Hash{h.Number.Uint64(): h.Hash()}
+	w.low = h.Number.Uint64()
+	w.tip = h
+}
+
+// HeadTracker 每条链一个链头跟踪器
+// 1. 来源：有 WSS 时订阅 newHeads，否则轮询最新区块头
+// 2. 维护最近 window 个区块的哈希，新区块的父哈希对不上时沿父哈希回溯，找到共同祖先
+// 3. 发布重组事件：OnReorg 回调 (同步，如缓存失效) 和 SubscribeReorgs 订阅 (异步，如索引器、交易跟踪)
+type HeadTracker struct {
+	rpcMgr *RPCManager
+	subMgr *SubscriptionManager
+	chains []config.ChainConfig
+
+	mu        sync.RWMutex
+	windows   map[int64]*headWindow
+	finalized map[int64]uint64 // 随链头刷新的已最终确认高度 (见 Finalized)
+	hooks     []func(ReorgEvent)
+	subs      map[*Subscription[ReorgEvent]]struct{}
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	startOnce sync.Once
+	closeOnce sync.Once
+}
+
+// NewHeadTracker 创建链头跟踪器 (由 Start 启动)
+func NewHeadTracker(cfg *config.AppConfig, rpcMgr *RPCManager, subMgr *SubscriptionManager) *HeadTracker {
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &HeadTracker{
+		rpcMgr:    rpcMgr,
+		subMgr:    subMgr,
+		windows:   make(map[int64]*headWindow),
+		finalized: make(map[int64]uint64),
+		subs:      make(map[*Subscription[ReorgEvent]]struct{}),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+	if cfg != nil {
+		for _, c := range cfg.Chains {
+			if !c.HeadTracker.Disable {
+				t.chains = append(t.chains, c)
+			}
+		}
+	}
+	return t
+}
+
+// Start 为每条链启动跟踪 goroutine (多次调用只生效一次)，ctx 取消或调用 Close 时停止
+func (t *HeadTracker) Start(ctx context.Context) {
+	t.startOnce.Do(func() {
+		go func() {
+			select {
+			case <-ctx.Done():
+				t.cancel()
+			case <-t.ctx.Done():
+			}
+		}()
+		for _, c := range t.chains {
+			t.wg.Add(1)
+			go t.run(c)
+		}
+	})
+}
+
+// Close 停止跟踪并结束所有重组订阅
+func (t *HeadTracker) Close() {
+	t.closeOnce.Do(func() {
+		t.cancel()
+		t.wg.Wait()
+		if global.Log != nil {
+			global.Log.Info("✅ [Head] HeadTracker closed")
+		}
+	})
+}
+
+// OnReorg 注册重组回调 (在跟踪 goroutine 中同步调用，不要阻塞)；应在 Start 之前注册，否则启动期间的重组不会触发回调
+func (t *HeadTracker) OnReorg(fn func(ReorgEvent)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.hooks = append(t.hooks, fn)
+}
+
+// SubscribeReorgs 订阅所有链的重组事件；消费太慢时 (缓冲满) 新事件会被丢弃并记录日志
+func (t *HeadTracker) SubscribeReorgs() *Subscription[ReorgEvent] {
+	ctx, cancel := context.WithCancel(t.ctx)
+	sub := &Subscription[ReorgEvent]{
+		ch:     make(chan ReorgEvent, defaultSubBuffer),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	t.mu.Lock()
+	t.subs[sub] = struct{}{}
+	t.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		t.mu.Lock()
+		delete(t.subs, sub)
+		close(sub.ch)
+		t.mu.Unlock()
+		close(sub.done)
+	}()
+	return sub
+}
+
+// Head 当前跟踪到的链头 (还没有时返回 nil)
+func (t *HeadTracker) Head(chainID int64) *types.Header {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if w := t.windows[chainID]; w != nil {
+		return w.tip
+	}
+	return nil
+}
+
+// Finalized 随链头刷新的已最终确认高度 (规则同 FinalityHeights)；还没有时返回 false
+func (t *HeadTracker) Finalized(chainID int64) (uint64, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	n, ok := t.finalized[chainID]
+	return n, ok
+}
+
+// CanonicalHash 窗口内某高度的规范链哈希
+func (t *HeadTracker) CanonicalHash(chainID int64, number uint64) (common.Hash, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if w := t.windows[chainID]; w != nil {
+		return w.hash(number)
+	}
+	return common.Hash{}, false
+}
+
+// ================= 跟踪主循环 =================
+
+func (t *HeadTracker) run(c config.ChainConfig) {
+	defer t.wg.Done()
+
+	// 有 WSS 端点时订阅 newHeads (断线重连、补齐由订阅管理器负责)
+	if t.subMgr != nil && len(t.rpcMgr.wssEndpoints(c.ChainID)) > 0 {
+		sub, err := t.subMgr.SubscribeNewHeads(c.ChainID)
+		if err == nil {
+			defer sub.Unsubscribe()
+			if global.Log != nil {
+				global.Log.Infof("🧭 [Head] chain %d tracking heads via newHeads subscription", c.ChainID)
+			}
+			for {
+				select {
+				case <-t.ctx.Done():
+					return
+				case h, ok := <-sub.Chan():
+					if !ok {
+						return
+					}
+					t.track(c, h)
+				}
+			}
+		}
+		if global.Log != nil {
+			global.Log.Warnf("⚠️ [Head] chain %d newHeads subscription failed, falling back to polling: %v", c.ChainID, err)
+		}
+	}
+
+	// 否则轮询最新区块头
+	interval := c.HeadTracker.PollInterval
+	if interval <= 0 {
+		interval = defaultHeadPollInterval
+	}
+	if global.Log != nil {
+		global.Log.Infof("🧭 [Head] chain %d tracking heads by polling every %s", c.ChainID, interval)
+	}
+	client := t.rpcMgr.Client(c.ChainID)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		ctx, cancel := context.WithTimeout(t.ctx, headFetchTimeout)
+		h, err := client.HeaderByNumber(ctx, nil)
+		cancel()
+		if err == nil {
+			t.track(c, h)
+		} else if t.ctx.Err() == nil && global.Log != nil {
+			global.Log.Debugf("🧭 [Head] chain %d poll failed: %v", c.ChainID, err)
+		}
+
+		select {
+		case <-t.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// process 处理一个新链头
+// 1. 高度低于当前链头：多为落后节点的旧数据，忽略 (真实的降高度重组会在新链超过旧链头时被检测到)
+// 2. 父哈希正好是当前链头：直接接上
+// 3. 否则沿父哈希回溯到窗口内的共同祖先：祖先就是当前链头说明只是中间有缺口，否则是重组
+// track 处理新区块头；链头变化后刷新已最终确认高度
+func (t *HeadTracker) track(c config.ChainConfig, h *types.Header) {
+	before := t.Head(c.ChainID)
+	t.process(c, h)
+	if after := t.Head(c.ChainID); after != nil && (before == nil || after.Hash() != before.Hash()) {
+		t.refreshFinalized(c.ChainID, after.Number.Uint64())
+	}
+}
+
+// refreshFinalized 每个新链头查一次 finalized 标签 (只有一个 RPC)，缓存据此判断数据能否长期保存
+func (t *HeadTracker) refreshFinalized(chainID int64, head uint64) {
+	ctx, cancel := context.WithTimeout(t.ctx, headFetchTimeout)
+	defer cancel()
+	n, err := t.rpcMgr.Client(chainID).finalizedHeight(ctx, head)
+	if err != nil {
+		if t.ctx.Err() == nil && global.Log != nil {
+			global.Log.Debugf("🧭 [Head] chain %d finalized height refresh failed: %v", chainID, err)
+		}
+		return
+	}
+	t.mu.Lock()
+	t.finalized[chainID] = n
+	t.mu.Unlock()
+}
+
+func (t *HeadTracker) process(c config.ChainConfig, h *types.Header) {
+	chainID := c.ChainID
+	t.mu.RLock()
+	w := t.windows[chainID]
+	var tip *types.Header
+	if w != nil {
+		tip = w.tip
+	}
+	t.mu.RUnlock()
+
+	if w == nil {
+		size := c.HeadTracker.Window
+		if size <= 0 {
+			size = defaultHeadWindow
+		}
+		w = &headWindow{size: size}
+		w.reset(h)
+		t.mu.Lock()
+		t.windows[chainID] = w
+		t.mu.Unlock()
+		return
+	}
+
+	number, tipNumber := h.Number.Uint64(), tip.Number.Uint64()
+	switch {
+	case number < tipNumber:
+		return
+	case h.Hash() == tip.Hash():
+		return
+	case number == tipNumber+1 && h.ParentHash == tip.Hash():
+		t.mu.Lock()
+		w.advance([]*types.Header{h})
+		t.mu.Unlock()
+		return
+	case number-tipNumber > uint64(w.size):
+		// 落后太多 (如长时间断线)，无法判断是否重组，重新开始跟踪
+		if global.Log != nil {
+			global.Log.Warnf("⚠️ [Head] chain %d jumped from %d to %d, resetting head window", chainID, tipNumber, number)
+		}
+		t.mu.Lock()
+		w.reset(h)
+		t.mu.Unlock()
+		return
+	}
+
+	// 回溯共同祖先
+	branch, ancestor, found, err := t.findAncestor(chainID, w, h)
+	if err != nil {
+		if t.ctx.Err() == nil && global.Log != nil {
+			global.Log.Warnf("⚠️ [Head] chain %d failed to walk back from block %d: %v", chainID, number, err)
+		}
+		return
+	}
+
+	t.mu.Lock()
+	var dropped []common.Hash
+	for n := ancestor.Number + 1; n <= tipNumber; n++ {
+		if hash, ok := w.hash(n); ok {
+			dropped = append(dropped, hash)
+		}
+	}
+	if found {
+		w.advance(branch)
+	} else {
+		w.reset(h)
+	}
+	t.mu.Unlock()
+
+	// 只是补齐缺口，没有区块被替换
+	if found && ancestor.Number == tipNumber {
+		return
+	}
+
+	added := make([]common.Hash, 0, len(branch))
+	for _, b := range branch {
+		added = append(added, b.Hash())
+	}
+	t.publish(ReorgEvent{
+		ChainID:        chainID,
+		CommonAncestor: ancestor,
+		OldHead:        BlockRef{Number: tipNumber, Hash: tip.Hash()},
+		NewHead:        BlockRef{Number: number, Hash: h.Hash()},
+		Depth:          tipNumber - ancestor.Number,
+		Dropped:        dropped,
+		Added:          added,
+		BeyondWindow:   !found,
+		DetectedAt:     time.Now(),
+	})
+}
+
+// findAncestor 从 h 沿父哈希回溯，直到父区块与窗口内的哈希一致
+// 返回新链上祖先之后的区块 (升序)；回溯到窗口下界仍未找到时 found = false，ancestor 为窗口下界的前一个区块
+func (t *HeadTracker) findAncestor(chainID int64, w *headWindow, h *types.Header) (branch []*types.Header, ancestor BlockRef, found bool, err error) {
+	t.mu.RLock()
+	oldest := w.low
+	t.mu.RUnlock()
+
+	client := t.rpcMgr.Client(chainID)
+	cur := h
+	branch = []*types.Header{h}
+	for {
+		number := cur.Number.Uint64()
+		if number == 0 || number-1 < oldest {
+			return branch, BlockRef{Number: oldest - min(oldest, 1)}, false, nil
+		}
+		t.mu.RLock()
+		known, ok := w.hash(number - 1)
+		t.mu.RUnlock()
+		if ok && known == cur.ParentHash {
+			return branch, BlockRef{Number: number - 1, Hash: known}, true, nil
+		}
+
+		ctx, cancel := context.WithTimeout(t.ctx, headFetchTimeout)
+		parent, err := client.HeaderByHash(ctx, cur.ParentHash)
+		cancel()
+		if err != nil {
+			return nil, BlockRef{}, false, fmt.Errorf("header %s: %w", cur.ParentHash.Hex(), err)
+		}
+		branch = append([]*types.Header{parent}, branch...)
+		cur = parent
+	}
+}
+
+// publish 通知回调和订阅者
+func (t *HeadTracker) publish(ev ReorgEvent) {
+	if global.Log != nil {
+		global.Log.Warnf("🔀 [Head] chain %d reorg: depth=%d ancestor=%d (%s) old_head=%d new_head=%d beyond_window=%v",
+			ev.ChainID, ev.Depth, ev.CommonAncestor.Number, ev.CommonAncestor.Hash.Hex(), ev.OldHead.Number, ev.NewHead.Number, ev.BeyondWindow)
+	}
+
+	t.mu.RLock()
+	hooks := append([]func(ReorgEvent){}, t.hooks...)
+	t.mu.RUnlock()
+	for _, fn := range hooks {
+		fn(ev)
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for sub := range t.subs {
+		select {
+		case sub.ch <- ev:
+		default:
+			if global.Log != nil {
+				global.Log.Warnf("⚠️ [Head] reorg subscriber is too slow, dropped event for chain %d", ev.ChainID)
+			}
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package data
+
+import (
+	"encoding/json"
+	"math/big"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+)
+
+// testChain 按高度生成一段链：fork 区分不同分叉上同一高度的区块
+func testChain(parent common.Hash, from, to uint64, fork string) []*types.Header {
+	var out []*types.Header
+	for n := from; n <= to; n++ {
+		h := &types.Header{
+			Number:     new(big.Int).SetUint64(n),
+			ParentHash: parent,
+			Difficulty: big.NewInt(1),
+			Extra:      []byte(fork),
+		}
+		out = append(out, h)
+		parent = h.Hash()
+	}
+	return out
+}
+
+func hashes(headers []*types.Header) []common.Hash {
+	out := make([]common.Hash, 0, len(headers))
+	for _, h := range headers {
+		out = append(out, h.Hash())
+	}
+	return out
+}
+
+func TestHeadWindowAdvance(t *testing.T) {
+	chain := testChain(common.Hash{}, 1, 10, "a")
+	tests := []struct {
+		name     string
+		size     int
+		advance  [][]*types.Header
+		wantLow  uint64
+		wantTip  uint64
+		wantKeep []uint64
+	}{
+		{"keeps everything within size", 8, [][]*types.Header{chain[1:3]}, 1, 3, []uint64{1, 2, 3}},
+		{"trims below window", 3, [][]*types.Header{chain[1:4], chain[4:6]}, 4, 6, []uint64{4, 5, 6}},
+		{"drops hashes above new tip", 8, [][]*types.Header{chain[1:6], chain[2:3]}, 1, 3, []uint64{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &headWindow{size: tt.size}
+			w.reset(chain[0])
+			for _, headers := range tt.advance {
+				w.advance(headers)
+			}
+			if w.low != tt.wantLow || w.tip.Number.Uint64() != tt.wantTip {
+				t.Errorf("low=%d tip=%d, want low=%d tip=%d", w.low, w.tip.Number.Uint64(), tt.wantLow, tt.wantTip)
+			}
+			var kept []uint64
+			for n := range w.hashes {
+				kept = append(kept, n)
+			}
+			slices.Sort(kept)
+			if !slices.Equal(kept, tt.wantKeep) {
+				t.Errorf("kept %v, want %v", kept, tt.wantKeep)
+			}
+			for _, n := range tt.wantKeep {
+				if h, _ := w.hash(n); h != chain[n-1].Hash() {
+					t.Errorf("hash(%d) = %s, want %s", n, h.Hex(), chain[n-1].Hash().Hex())
+				}
+			}
+		})
+	}
+}
+
+func TestHeadTrackerReorgDetection(t *testing.T) {
+	// 主链 a: 100..110；b 从 102 分叉 (103..106)；c 从 101 分叉 (102..106)
+	a := testChain(common.Hash{0x01}, 100, 110, "a")
+	b := testChain(a[2].Hash(), 103, 106, "b")
+	c := testChain(a[1].Hash(), 102, 106, "c")
+
+	byHash := make(map[common.Hash]*types.Header)
+	for _, h := range slices.Concat(a, b, c) {
+		byHash[h.Hash()] = h
+	}
+	srv := newTestRPCServer(t, 1, func(method string, params []json.RawMessage) (any, *rpcTestError, bool) {
+		if method != "eth_getBlockByHash" || len(params) == 0 {
+			return nil, nil, false
+		}
+		var hash common.Hash
+		_ = json.Unmarshal(params[0], &hash)
+		if h, ok := byHash[hash]; ok {
+			return h, nil, true
+		}
+		return nil, nil, true
+	})
+	mgr := startTestManager(t, config.ChainConfig{ChainID: 1, RpcUrl: srv.URL})
+
+	tests := []struct {
+		name      string
+		window    int
+		heads     []*types.Header
+		wantHead  common.Hash
+		wantReorg *ReorgEvent // nil 表示不应触发重组
+		canonical map[uint64]common.Hash
+	}{
+		{
+			name:     "extends tip",
+			heads:    a[0:4],
+			wantHead: a[3].Hash(),
+		},
+		{
+			name:      "gap filled without reorg",
+			heads:     []*types.Header{a[0], a[1], a[3]},
+			wantHead:  a[3].Hash(),
+			canonical: map[uint64]common.Hash{102: a[2].Hash()},
+		},
+		{
+			name:     "older head ignored",
+			heads:    []*types.Header{a[0], a[1], a[2], a[3], c[0]},
+			wantHead: a[3].Hash(),
+		},
+		{
+			name:     "reorg replaces blocks",
+			heads:    []*types.Header{a[0], a[1], a[2], a[3], a[4], a[5], b[3]},
+			wantHead: b[3].Hash(),
+			wantReorg: &ReorgEvent{
+				CommonAncestor: BlockRef{Number: 102, Hash: a[2].Hash()},
+				OldHead:        BlockRef{Number: 105, Hash: a[5].Hash()},
+				NewHead:        BlockRef{Number: 106, Hash: b[3].Hash()},
+				Depth:          3,
+				Dropped:        hashes(a[3:6]),
+				Added:          hashes(b),
+			},
+			canonical: map[uint64]common.Hash{102: a[2].Hash(), 104: b[1].Hash()},
+		},
+		{
+			name:     "reorg deeper than window",
+			window:   3,
+			heads:    []*types.Header{a[0], a[1], a[2], a[3], a[4], a[5], c[4]},
+			wantHead: c[4].Hash(),
+			wantReorg: &ReorgEvent{
+				CommonAncestor: BlockRef{Number: 102},
+				OldHead:        BlockRef{Number: 105, Hash: a[5].Hash()},
+				NewHead:        BlockRef{Number: 106, Hash: c[4].Hash()},
+				Depth:          3,
+				Dropped:        hashes(a[3:6]),
+				Added:          hashes(c[1:5]),
+				BeyondWindow:   true,
+			},
+		},
+		{
+			name:     "jump beyond window resets",
+			window:   3,
+			heads:    []*types.Header{a[0], a[10]},
+			wantHead: a[10].Hash(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := config.ChainConfig{ChainID: 1, HeadTracker: config.HeadTrackerConfig{Window: tt.window}}
+			tracker := NewHeadTracker(nil, mgr, nil)
+			defer tracker.Close()
+
+			var mu sync.Mutex
+			var events []ReorgEvent
+			tracker.OnReorg(func(ev ReorgEvent) {
+				mu.Lock()
+				events = append(events, ev)
+				mu.Unlock()
+			})
+			for _, h := range tt.heads {
+				tracker.process(chain, h)
+			}
+
+			if head := tracker.Head(1); head == nil || head.Hash() != tt.wantHead {
+				t.Errorf("head = %v, want %s", head, tt.wantHead.Hex())
+			}
+			for n, want := range tt.canonical {
+				if got, _ := tracker.CanonicalHash(1, n); got != want {
+					t.Errorf("canonical %d = %s, want %s", n, got.Hex(), want.Hex())
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if tt.wantReorg == nil {
+				if len(events) != 0 {
+					t.Fatalf("unexpected reorg events: %+v", events)
+				}
+				return
+			}
+			if len(events) != 1 {
+				t.Fatalf("got %d reorg events, want 1", len(events))
+			}
+			got, want := events[0], *tt.wantReorg
+			if got.ChainID != 1 || got.CommonAncestor != want.CommonAncestor || got.OldHead != want.OldHead ||
+				got.NewHead != want.NewHead || got.Depth != want.Depth || got.BeyondWindow != want.BeyondWindow ||
+				!slices.Equal(got.Dropped, want.Dropped) || !slices.Equal(got.Added, want.Added) {
+				t.Errorf("reorg event = %+v, want %+v", got, want)
+			}
+			if got.FromBlock() != want.CommonAncestor.Number+1 {
+				t.Errorf("FromBlock() = %d, want %d", got.FromBlock(), want.CommonAncestor.Number+1)
+			}
+		})
+	}
+}
+
+func TestHeadTrackerFinalized(t *testing.T) {
+	heads := testChain(common.Hash{0x01}, 100, 110, "a")
+	tests := []struct {
+		name      string
+		finality  config.FinalityConfig
+		tag       *types.Header // 节点对 finalized 标签的应答 (nil = 不支持)
+		wantFinal uint64
+	}{
+		{name: "finalized tag", tag: heads[5], wantFinal: 105},
+		{name: "tag ahead of tracked head", tag: testChain(common.Hash{}, 120, 120, "b")[0], wantFinal: 110},
+		{name: "tag unsupported falls back to confirmations", wantFinal: 110 - defaultConfirmations},
+		{name: "tags disabled", finality: config.FinalityConfig{DisableTags: true, Confirmations: 4}, tag: heads[5], wantFinal: 106},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestRPCServer(t, 1, func(method string, params []json.RawMessage) (any, *rpcTestError, bool) {
+				if method == "eth_getBlockByNumber" && len(params) > 0 && string(params[0]) == `"finalized"` {
+					return tt.tag, nil, true
+				}
+				return nil, nil, false
+			})
+			chain := config.ChainConfig{ChainID: 1, RpcUrl: srv.URL, Finality: tt.finality}
+			mgr := startTestManager(t, chain)
+			tracker := NewHeadTracker(nil, mgr, nil)
+			defer tracker.Close()
+
+			if _, ok := tracker.Finalized(1); ok {
+				t.Fatal("Finalized() reported a height before any head")
+			}
+			for _, h := range heads {
+				tracker.track(chain, h)
+			}
+			if got, ok := tracker.Finalized(1); !ok || got != tt.wantFinal {
+				t.Errorf("Finalized() = %d, %v, want %d", got, ok, tt.wantFinal)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+	"github.com/zy99978455-otw/go-micro-template/pkg/global"
+)
+
+// 运行时节点管理 (管理接口使用)
+// chainNodes 的切片只整体替换、不原地修改，读方复制出的切片不受影响
+
+const drainPollInterval = 50 * time.Millisecond
+
+var (
+	ErrChainNotFound = errors.New("chain not configured")
+	ErrNodeNotFound  = errors.New("node not found")
+	ErrNodeExists    = errors.New("node already exists")
+	ErrLastNode      = errors.New("cannot remove the last node of a chain")
+)
+
+// AddNode 运行时为已配置的链添加节点
+// 1. 按链配置填充默认值 (继承 api_key、权重、优先级)
+// 2. 立即做一次检查 (连接、链校验、能力探测、探针)，未通过也会加入，由后台健康检查继续重试
+func (m *RPCManager) AddNode(ctx context.Context, chainID int64, nodeConf config.NodeConfig) (NodeStatus, error) {
+	if nodeConf.Name == "" || nodeConf.RpcUrl == "" {
+		return NodeStatus{}, errors.New("name and rpc_url are required")
+	}
+
+	m.mu.RLock()
+	chainConf, ok := m.chains[chainID]
+	policy := m.health[chainID]
+	quotaRedis := m.quotaRedis
+	m.mu.RUnlock()
+	if !ok {
+		return NodeStatus{}, fmt.Errorf("%w: %d", ErrChainNotFound, chainID)
+	}
+	if _, err := m.findNode(chainID, nodeConf.Name); err == nil {
+		return NodeStatus{}, fmt.Errorf("%w: %s", ErrNodeExists, nodeConf.Name)
+	}
+
+	// 复用 Endpoints 的默认值逻辑
+	chainConf.Nodes = []config.NodeConfig{nodeConf}
+	nodeConf = chainConf.Endpoints()[0]
+
+	node := newNode(chainID, nodeConf, newBatchPolicy(chainConf.Batch, nodeConf))
+	node.breaker = newCircuitBreaker(node.Name, newBreakerPolicy(chainConf.CircuitBreaker))
+	if quotaRedis != nil {
+		// 恢复同名节点之前的 CU 用量
+		node.quota.persist(quotaRedis, chainID, node.Name)
+		_ = node.quota.sync(ctx)
+	}
+	m.checkOneNode(ctx, node, policy)
+
+	m.mu.Lock()
+	for _, n := range m.chainNodes[chainID] {
+		if n.Name == node.Name {
+			m.mu.Unlock()
+			node.close()
+			return NodeStatus{}, fmt.Errorf("%w: %s", ErrNodeExists, node.Name)
+		}
+	}
+	m.chainNodes[chainID] = append(append([]*Node(nil), m.chainNodes[chainID]...), node)
+	m.mu.Unlock()
+	m.notifyNodesChanged(chainID)
+
+	if global.Log != nil {
+		global.Log.Infof("➕ [RPC] Node added at runtime for chain %d: %s (%s, weight=%d)", chainID, node.Name, node.Priority, node.Weight)
+	}
+	return node.Status(), nil
+}
+
+// SetDraining 设置 / 取消节点排空：排空中的节点不再被选中，进行中的请求继续完成
+func (m *RPCManager) SetDraining(chainID int64, name string, draining bool) (NodeStatus, error) {
+	n, err := m.findNode(chainID, name)
+	if err != nil {
+		return NodeStatus{}, err
+	}
+	n.mu.Lock()
+	n.Draining = draining
+	n.mu.Unlock()
+
+	if global.Log != nil {
+		global.Log.Infof("🚰 [RPC] Node %s (chain %d) draining=%v", name, chainID, draining)
+	}
+	return n.Status(), nil
+}
+
+// CheckNode 立即对单个节点做一次健康检查
+func (m *RPCManager) CheckNode(ctx context.Context, chainID int64, name string) (NodeStatus, error) {
+	n, err := m.findNode(chainID, name)
+	if err != nil {
+		return NodeStatus{}, err
+	}
+	m.mu.RLock()
+	policy := m.health[chainID]
+	m.mu.RUnlock()
+
+	m.checkOneNode(ctx, n, policy)
+	return n.Status(), nil
+}
+
+// CheckChain 立即对整条链做一次健康检查 (包括落后检测)
+func (m *RPCManager) CheckChain(ctx context.Context, chainID int64) ([]NodeStatus, error) {
+	m.mu.RLock()
+	_, ok := m.chains[chainID]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrChainNotFound, chainID)
+	}
+	m.checkChain(ctx, chainID)
+	return m.NodeStatuses(chainID), nil
+}
+
+// RemoveNode 安全移除节点
+// 1. 先从选择列表中摘除 (新请求不会再选中它，订阅不会再连它的 WSS 端点)
+// 2. 等待进行中的请求完成 (ctx 超时则不再等待)
+// 3. 关闭连接
+func (m *RPCManager) RemoveNode(ctx context.Context, chainID int64, name string) error {
+	m.mu.Lock()
+	nodes := m.chainNodes[chainID]
+	var target *Node
+	for _, n := range nodes {
+		if n.Name == name {
+			target = n
+		}
+	}
+	if target == nil {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %s (chain %d)", ErrNodeNotFound, name, chainID)
+	}
+	if len(nodes) == 1 {
+		m.mu.Unlock()
+		return ErrLastNode
+	}
+	m.chainNodes[chainID] = removeNode(append([]*Node(nil), nodes...), target)
+	m.mu.Unlock()
+	m.notifyNodesChanged(chainID)
+
+	target.mu.Lock()
+	target.Draining = true
+	target.mu.Unlock()
+
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for target.InFlight() > 0 {
+		select {
+		case <-ctx.Done():
+			if global.Log != nil {
+				global.Log.Warnf("⚠️ [RPC] Node %s (chain %d) removed with %d requests still in flight", name, chainID, target.InFlight())
+			}
+			target.close()
+			return nil
+		case <-ticker.C:
+		}
+	}
+
+	target.close()
+	if global.Log != nil {
+		global.Log.Infof("➖ [RPC] Node removed at runtime from chain %d: %s", chainID, name)
+	}
+	return nil
+}
+
+// ListNodes 链上所有节点的状态
+func (m *RPCManager) ListNodes(chainID int64) ([]NodeStatus, error) {
+	m.mu.RLock()
+	_, ok := m.chains[chainID]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrChainNotFound, chainID)
+	}
+	return m.NodeStatuses(chainID), nil
+}
+
+// OnNodesChanged 注册运行时增删节点的回调 (在管理接口的请求中同步调用，不要阻塞)
+func (m *RPCManager) OnNodesChanged(fn func(chainID int64)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nodeHooks = append(m.nodeHooks, fn)
+}
+
+func (m *RPCManager) notifyNodesChanged(chainID int64) {
+	m.mu.RLock()
+	hooks := append([]func(int64){}, m.nodeHooks...)
+	m.mu.RUnlock()
+	for _, fn := range hooks {
+		fn(chainID)
+	}
+}
+
+// findNode 按名称查找节点
+func (m *RPCManager) findNode(chainID int64, name string) (*Node, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if _, ok := m.chains[chainID]; !ok {
+		return nil, fmt.Errorf("%w: %d", ErrChainNotFound, chainID)
+	}
+	for _, n := range m.chainNodes[chainID] {
+		if n.Name == name {
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s (chain %d)", ErrNodeNotFound, name, chainID)
+}
+
+// close 关闭节点连接并标记为已移除 (健康检查不会再重连)
+func (n *Node) close() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.removed = true
+	n.IsHealthy = false
+	if n.Client != nil {
+		n.Client.Close()
+		n.Client = nil
+	}
+}
@@ -0,0 +1,148 @@
+package data
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+)
+
+// 内置的节点选择策略 (对应 chains[].balancer 配置)
+const (
+	BalancerLatency       = "latency"              // 延迟最低
+	BalancerWeightedRR    = "weighted_round_robin" // 平滑加权轮询
+	BalancerLeastInFlight = "least_inflight"       // 在途请求最少
+	BalancerHighestBlock  = "highest_block"        // 区块高度最高
+	BalancerP2CEWMA       = "p2c_ewma"             // Power of Two Choices + EWMA 延迟
+	defaultBalancerName   = BalancerLatency
+	ewmaDecay             = 0.3 // EWMA 平滑系数：越大越偏向最新样本
+)
+
+// Balancer 节点选择策略
+// nodes 是已经过滤好的候选节点 (健康 + 同一优先级)，保证非空
+type Balancer interface {
+	Pick(nodes []*Node) *Node
+}
+
+// NewBalancer 根据名称创建策略，名称为空时使用默认策略
+func NewBalancer(name string) (Balancer, error) {
+	switch name {
+	case "", BalancerLatency:
+		return latencyBalancer{}, nil
+	case BalancerWeightedRR:
+		return &weightedRRBalancer{current: make(map[*Node]int)}, nil
+	case BalancerLeastInFlight:
+		return leastInFlightBalancer{}, nil
+	case BalancerHighestBlock:
+		return highestBlockBalancer{}, nil
+	case BalancerP2CEWMA:
+		return p2cBalancer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown balancer %q", name)
+	}
+}
+
+// ================= 延迟最低 =================
+
+type latencyBalancer struct{}
+
+func (latencyBalancer) Pick(nodes []*Node) *Node {
+	return minBy(nodes, func(n *Node) float64 { return n.ewma() })
+}
+
+// ================= 平滑加权轮询 (nginx 算法) =================
+
+type weightedRRBalancer struct {
+	mu      sync.Mutex
+	current map[*Node]int
+}
+
+func (b *weightedRRBalancer) Pick(nodes []*Node) *Node {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	total := 0
+	var best *Node
+	for _, n := range nodes {
+		b.current[n] += n.Weight
+		total += n.Weight
+		if best == nil || b.current[n] > b.current[best] {
+			best = n
+		}
+	}
+	b.current[best] -= total
+
+	// 清理已经不在候选列表里的节点，避免它们恢复时带着过期的权重
+	if len(b.current) > len(nodes) {
+		alive := make(map[*Node]struct{}, len(nodes))
+		for _, n := range nodes {
+			alive[n] = struct{}{}
+		}
+		for n := range b.current {
+			if _, ok := alive[n]; !ok {
+				delete(b.current, n)
+			}
+		}
+	}
+	return best
+}
+
+// ================= 在途请求最少 =================
+
+type leastInFlightBalancer struct{}
+
+func (leastInFlightBalancer) Pick(nodes []*Node) *Node {
+	return minBy(nodes, func(n *Node) float64 { return float64(n.InFlight()) })
+}
+
+// ================= 区块高度最高 =================
+
+type highestBlockBalancer struct{}
+
+func (highestBlockBalancer) Pick(nodes []*Node) *Node {
+	best := nodes[0]
+	bestHeight := best.height()
+	for _, n := range nodes[1:] {
+		h := n.height()
+		// 高度相同时再比较延迟
+		if h > bestHeight || (h == bestHeight && n.ewma() < best.ewma()) {
+			best, bestHeight = n, h
+		}
+	}
+	return best
+}
+
+// ================= P2C + EWMA =================
+
+type p2cBalancer struct{}
+
+func (p2cBalancer) Pick(nodes []*Node) *Node {
+	if len(nodes) == 1 {
+		return nodes[0]
+	}
+	i := rand.IntN(len(nodes))
+	j := rand.IntN(len(nodes) - 1)
+	if j >= i {
+		j++
+	}
+	a, b := nodes[i], nodes[j]
+	if p2cCost(b) < p2cCost(a) {
+		return b
+	}
+	return a
+}
+
+// p2cCost 负载代价 = EWMA 延迟 * (在途请求 + 1)
+func p2cCost(n *Node) float64 {
+	return n.ewma() * float64(n.InFlight()+1)
+}
+
+// minBy 选出 score 最小的节点 (分数相同取靠前的)
+func minBy(nodes []*Node, score func(*Node) float64) *Node {
+	best, bestScore := nodes[0], score(nodes[0])
+	for _, n := range nodes[1:] {
+		if s := score(n); s < bestScore {
+			best, bestScore = n, s
+		}
+	}
+	return best
+}
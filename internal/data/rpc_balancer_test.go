@@ -0,0 +1,147 @@
+package data
+
+import (
+	"strings"
+	"testing"
+)
+
+// testNode 构造只用于选择策略的节点
+func testNode(name string, weight int, ewma float64, height uint64, inFlight int64) *Node {
+	n := &Node{Name: name, Weight: weight, ewmaLatency: ewma, BlockHeight: height}
+	n.inFlight.Store(inFlight)
+	return n
+}
+
+func TestNewBalancer(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"", false},
+		{BalancerLatency, false},
+		{BalancerWeightedRR, false},
+		{BalancerLeastInFlight, false},
+		{BalancerHighestBlock, false},
+		{BalancerP2CEWMA, false},
+		{"random", true},
+	}
+	for _, tt := range tests {
+		b, err := NewBalancer(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NewBalancer(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err == nil && b == nil {
+			t.Errorf("NewBalancer(%q) returned nil balancer", tt.name)
+		}
+	}
+}
+
+func TestBalancerPick(t *testing.T) {
+	tests := []struct {
+		name     string
+		balancer string
+		nodes    []*Node
+		want     string
+	}{
+		{
+			name:     "latency picks lowest ewma",
+			balancer: BalancerLatency,
+			nodes:    []*Node{testNode("a", 1, 30, 0, 0), testNode("b", 1, 10, 0, 0), testNode("c", 1, 20, 0, 0)},
+			want:     "b",
+		},
+		{
+			name:     "latency tie keeps first",
+			balancer: BalancerLatency,
+			nodes:    []*Node{testNode("a", 1, 10, 0, 0), testNode("b", 1, 10, 0, 0)},
+			want:     "a",
+		},
+		{
+			name:     "least in-flight",
+			balancer: BalancerLeastInFlight,
+			nodes:    []*Node{testNode("a", 1, 1, 0, 5), testNode("b", 1, 100, 0, 1), testNode("c", 1, 1, 0, 3)},
+			want:     "b",
+		},
+		{
+			name:     "highest block",
+			balancer: BalancerHighestBlock,
+			nodes:    []*Node{testNode("a", 1, 1, 100, 0), testNode("b", 1, 50, 102, 0), testNode("c", 1, 1, 101, 0)},
+			want:     "b",
+		},
+		{
+			name:     "highest block tie broken by latency",
+			balancer: BalancerHighestBlock,
+			nodes:    []*Node{testNode("a", 1, 50, 102, 0), testNode("b", 1, 10, 102, 0)},
+			want:     "b",
+		},
+		{
+			name:     "p2c picks lower cost of the two",
+			balancer: BalancerP2CEWMA,
+			nodes:    []*Node{testNode("a", 1, 10, 0, 9), testNode("b", 1, 20, 0, 0)},
+			want:     "b",
+		},
+		{
+			name:     "p2c single node",
+			balancer: BalancerP2CEWMA,
+			nodes:    []*Node{testNode("a", 1, 10, 0, 0)},
+			want:     "a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := NewBalancer(tt.balancer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// p2c 是随机的，多选几次结果都应一致
+			for i := 0; i < 20; i++ {
+				if got := b.Pick(tt.nodes); got.Name != tt.want {
+					t.Fatalf("Pick() = %s, want %s", got.Name, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestWeightedRoundRobin(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes []*Node
+		picks int
+		want  string // 平滑加权轮询的选择顺序
+	}{
+		{
+			name:  "smooth 5:1:1",
+			nodes: []*Node{testNode("a", 5, 0, 0, 0), testNode("b", 1, 0, 0, 0), testNode("c", 1, 0, 0, 0)},
+			picks: 7,
+			want:  "aabacaa",
+		},
+		{
+			name:  "equal weights alternate",
+			nodes: []*Node{testNode("a", 1, 0, 0, 0), testNode("b", 1, 0, 0, 0)},
+			picks: 4,
+			want:  "abab",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := NewBalancer(BalancerWeightedRR)
+			var got strings.Builder
+			for i := 0; i < tt.picks; i++ {
+				got.WriteString(b.Pick(tt.nodes).Name)
+			}
+			if got.String() != tt.want {
+				t.Errorf("picks = %s, want %s", got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWeightedRoundRobinForgetsRemovedNodes(t *testing.T) {
+	a, b, c := testNode("a", 1, 0, 0, 0), testNode("b", 1, 0, 0, 0), testNode("c", 1, 0, 0, 0)
+	wrr := &weightedRRBalancer{current: make(map[*Node]int)}
+	wrr.Pick([]*Node{a, b, c})
+	wrr.Pick([]*Node{a, b})
+	if _, ok := wrr.current[c]; ok {
+		t.Error("removed node still has a weight entry")
+	}
+}
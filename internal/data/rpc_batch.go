@@ -0,0 +1,431 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+)
+
+const (
+	defaultBatchWindow  = 2 * time.Millisecond // 默认攒批窗口
+	defaultMaxBatchSize = 20                   // 默认单批最多 20 个调用
+	defaultBatchTimeout = 30 * time.Second     // 调用者没有截止时间时，批量请求的超时
+)
+
+// 这些方法有副作用或返回与调用者绑定的状态，不能合并相同请求
+var nonCoalescableMethods = map[string]bool{
+	"eth_sendRawTransaction":          true,
+	"eth_sendTransaction":             true,
+	"eth_sign":                        true,
+	"eth_signTransaction":             true,
+	"eth_newFilter":                   true,
+	"eth_newBlockFilter":              true,
+	"eth_newPendingTransactionFilter": true,
+	"eth_uninstallFilter":             true,
+	"eth_getFilterChanges":            true,
+}
+
+// batchPolicy 批量/合并参数 (已填充默认值)
+type batchPolicy struct {
+	window   time.Duration // 攒批窗口，<= 0 表示不攒批
+	maxSize  int           // 单批上限 (服务商限制)
+	coalesce bool          // 是否合并相同的在途请求
+}
+
+func newBatchPolicy(c config.BatchConfig, nodeConf config.NodeConfig) batchPolicy {
+	p := batchPolicy{
+		window:   c.Window,
+		maxSize:  c.MaxSize,
+		coalesce: !c.DisableCoalesce,
+	}
+	if p.window <= 0 {
+		p.window = defaultBatchWindow
+	}
+	if p.maxSize <= 0 {
+		p.maxSize = defaultMaxBatchSize
+	}
+	// 节点级上限优先 (有的服务商不支持或限制批量大小)
+	if nodeConf.MaxBatchSize > 0 {
+		p.maxSize = nodeConf.MaxBatchSize
+	}
+	if c.Disable || p.maxSize <= 1 {
+		p.window = 0
+		p.maxSize = 1
+	}
+	return p
+}
+
+// jsonrpcMessage JSON-RPC 请求/响应 (只解析需要的字段)
+type jsonrpcMessage struct {
+	Version string          `json:"jsonrpc,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
+// batchTransport 对 ethclient 透明的 HTTP 层批量器
+// 1. 合并：同一时刻完全相同的读请求 (method + params) 只发一次，结果分发给所有调用者
+// 2. 攒批：窗口期内的并发调用合并成一个 JSON-RPC batch 请求发出，按 id 拆分响应
+// ethclient 自己发出的 batch 请求原样透传
+type batchTransport struct {
+	base   http.RoundTripper
+	policy batchPolicy
+	name   string     // 节点名称，仅用于日志
+	quota  *nodeQuota // 限流与额度 (nil 表示不限)
+
+	mu      sync.Mutex
+	pending []*batchCall         // 当前窗口内攒的调用
+	timer   *time.Timer          // 当前窗口的定时器
+	flights map[string]*inFlight // 合并中的在途请求
+}
+
+// batchCall 一个等待发送的调用
+type batchCall struct {
+	req  *http.Request
+	msg  jsonrpcMessage
+	body []byte
+	done chan batchResult
+}
+
+type batchResult struct {
+	status int
+	header http.Header
+	body   []byte
+	err    error
+}
+
+// inFlight 一个正在执行、可以被合并的请求
+type inFlight struct {
+	done   chan struct{}
+	result batchResult
+}
+
+func newBatchTransport(name string, policy batchPolicy, quota *nodeQuota) *batchTransport {
+	return &batchTransport{
+		base:    http.DefaultTransport,
+		policy:  policy,
+		name:    name,
+		quota:   quota,
+		flights: make(map[string]*inFlight),
+	}
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *batchTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodPost || req.Body == nil {
+		return t.base.RoundTrip(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	// 已经是 batch 或无法解析的请求：原样发送 (每个调用都要计费)
+	var msg jsonrpcMessage
+	if len(bytes.TrimSpace(body)) == 0 || bytes.TrimSpace(body)[0] != '{' || json.Unmarshal(body, &msg) != nil || msg.Method == "" {
+		var batch []jsonrpcMessage
+		_ = json.Unmarshal(body, &batch)
+		for _, m := range batch {
+			if err := t.quota.acquire(req.Context(), m.Method); err != nil {
+				return nil, err
+			}
+		}
+		return t.send(req, body)
+	}
+
+	res := t.coalesced(req, msg, body)
+	if res.err != nil {
+		return nil, res.err
+	}
+	header := res.header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Del("Content-Length")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", res.status, http.StatusText(res.status)),
+		StatusCode:    res.status,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(res.body)),
+		ContentLength: int64(len(res.body)),
+		Request:       req,
+	}, nil
+}
+
+// coalesced 合并相同的在途请求，第一个调用者负责真正发送 (跟随者不占用令牌、不计费)
+func (t *batchTransport) coalesced(req *http.Request, msg jsonrpcMessage, body []byte) batchResult {
+	if !t.policy.coalesce || nonCoalescableMethods[msg.Method] {
+		return t.enqueue(req, msg, body)
+	}
+
+	key := msg.Method + string(msg.Params)
+	t.mu.Lock()
+	if f, ok := t.flights[key]; ok {
+		t.mu.Unlock()
+		select {
+		case <-f.done:
+			// 发起者自己取消了，跟随者不受影响，自己再发一次
+			if f.result.err != nil && req.Context().Err() == nil && isContextErr(f.result.err) {
+				return t.enqueue(req, msg, body)
+			}
+			return withID(f.result, msg.ID)
+		case <-req.Context().Done():
+			return batchResult{err: req.Context().Err()}
+		}
+	}
+	f := &inFlight{done: make(chan struct{})}
+	t.flights[key] = f
+	t.mu.Unlock()
+
+	f.result = t.enqueue(req, msg, body)
+
+	t.mu.Lock()
+	delete(t.flights, key)
+	t.mu.Unlock()
+	close(f.done)
+	return f.result
+}
+
+// enqueue 放入当前窗口，等待批量发送的结果
+// 只有真正发往节点的调用会走到这里，限流和计费在这里做
+func (t *batchTransport) enqueue(req *http.Request, msg jsonrpcMessage, body []byte) batchResult {
+	if err := t.quota.acquire(req.Context(), msg.Method); err != nil {
+		return batchResult{err: err}
+	}
+	if t.policy.window <= 0 {
+		return t.single(req, body)
+	}
+
+	call := &batchCall{req: req, msg: msg, body: body, done: make(chan batchResult, 1)}
+
+	t.mu.Lock()
+	t.pending = append(t.pending, call)
+	switch {
+	case len(t.pending) >= t.policy.maxSize:
+		// 攒满了立即发送
+		batch := t.takeLocked()
+		t.mu.Unlock()
+		go t.flush(batch)
+	case len(t.pending) == 1:
+		t.timer = time.AfterFunc(t.policy.window, func() {
+			t.mu.Lock()
+			batch := t.takeLocked()
+			t.mu.Unlock()
+			t.flush(batch)
+		})
+		t.mu.Unlock()
+	default:
+		t.mu.Unlock()
+	}
+
+	select {
+	case res := <-call.done:
+		return res
+	case <-req.Context().Done():
+		return batchResult{err: req.Context().Err()}
+	}
+}
+
+// takeLocked 取出当前窗口的全部调用 (调用方持有锁)
+func (t *batchTransport) takeLocked() []*batchCall {
+	batch := t.pending
+	t.pending = nil
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+	return batch
+}
+
+// flush 发送一批调用并按 id 分发响应
+func (t *batchTransport) flush(batch []*batchCall) {
+	if len(batch) == 0 {
+		return
+	}
+	// 只有一个调用时按普通请求发送，兼容不支持 batch 的节点
+	if len(batch) == 1 {
+		batch[0].done <- t.single(batch[0].req, batch[0].body)
+		return
+	}
+
+	bodies := make([]json.RawMessage, len(batch))
+	for i, c := range batch {
+		bodies[i] = c.body
+	}
+	payload, _ := json.Marshal(bodies)
+
+	ctx, cancel := batchContext(batch)
+	defer cancel()
+	first := batch[0].req
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, first.URL.String(), bytes.NewReader(payload))
+	if err != nil {
+		fail(batch, batchResult{err: err})
+		return
+	}
+	req.Header = first.Header.Clone()
+
+	res := t.do(req)
+	if res.err != nil || res.status != http.StatusOK {
+		// 整批失败 (网络错误 / 429 / 5xx)：每个调用者都拿到同样的错误
+		fail(batch, res)
+		return
+	}
+
+	var responses []jsonrpcMessage
+	if err := json.Unmarshal(res.body, &responses); err != nil {
+		// 节点没有按 batch 格式返回 (通常是单个错误对象)，原样给每个调用者
+		fail(batch, res)
+		return
+	}
+	byID := make(map[string]jsonrpcMessage, len(responses))
+	for _, r := range responses {
+		byID[string(r.ID)] = r
+	}
+	for _, c := range batch {
+		r, ok := byID[string(c.msg.ID)]
+		if !ok {
+			c.done <- batchResult{err: fmt.Errorf("batch response missing id %s for %s", c.msg.ID, c.msg.Method)}
+			continue
+		}
+		out, _ := json.Marshal(r)
+		c.done <- batchResult{status: http.StatusOK, header: res.header, body: out}
+	}
+}
+
+// batchContext 批量请求的 ctx：不跟随任何一个调用者的取消 (调用者自己的 ctx 只影响它自己的等待)，
+// 截止时间取调用者中最晚的，没有截止时间的调用者按 defaultBatchTimeout 计
+func batchContext(batch []*batchCall) (context.Context, context.CancelFunc) {
+	var deadline time.Time
+	for _, c := range batch {
+		d, ok := c.req.Context().Deadline()
+		if !ok {
+			d = time.Now().Add(defaultBatchTimeout)
+		}
+		if d.After(deadline) {
+			deadline = d
+		}
+	}
+	return context.WithDeadline(context.WithoutCancel(batch[0].req.Context()), deadline)
+}
+
+// single 直接发送单个请求
+func (t *batchTransport) single(orig *http.Request, body []byte) batchResult {
+	req := orig.Clone(orig.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	return t.do(req)
+}
+
+// send 原样发送 (不参与合并和攒批)
+func (t *batchTransport) send(orig *http.Request, body []byte) (*http.Response, error) {
+	req := orig.Clone(orig.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	resp, err := t.base.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		t.quota.throttle(t.name, parseRetryAfter(resp.Header))
+	}
+	return resp, err
+}
+
+func (t *batchTransport) do(req *http.Request) batchResult {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return batchResult{err: err}
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return batchResult{err: err}
+	}
+	t.observe(resp.StatusCode, resp.Header, body)
+	return batchResult{status: resp.StatusCode, header: resp.Header, body: body}
+}
+
+// observe 根据响应识别服务商限流 (HTTP 429 或 JSON-RPC 限流错误码)
+func (t *batchTransport) observe(status int, header http.Header, body []byte) {
+	if t.quota == nil {
+		return
+	}
+	if status == http.StatusTooManyRequests {
+		t.quota.throttle(t.name, parseRetryAfter(header))
+		return
+	}
+	if status == http.StatusOK && bytes.Contains(body, []byte(`"error"`)) && hasRateLimitError(body) {
+		t.quota.throttle(t.name, parseRetryAfter(header))
+		return
+	}
+	if status == http.StatusOK {
+		t.quota.recover()
+	}
+}
+
+// hasRateLimitError 响应 (单个或 batch) 中是否有限流错误
+func hasRateLimitError(body []byte) bool {
+	type rpcError struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	type response struct {
+		Error *rpcError `json:"error"`
+	}
+	var list []response
+	if err := json.Unmarshal(body, &list); err != nil {
+		var single response
+		if err := json.Unmarshal(body, &single); err != nil {
+			return false
+		}
+		list = []response{single}
+	}
+	for _, r := range list {
+		if r.Error == nil || isLogRangeMessage(r.Error.Message) {
+			// -32005 也用于 eth_getLogs 结果过多，那是请求的问题，不是限流
+			continue
+		}
+		if r.Error.Code == rpcCodeLimitExceeded || r.Error.Code == rpcCodeTooManyReqs || isRateLimitMessage(r.Error.Message) {
+			return true
+		}
+	}
+	return false
+}
+
+func fail(batch []*batchCall, res batchResult) {
+	for _, c := range batch {
+		c.done <- res
+	}
+}
+
+// withID 把合并得到的响应改写成跟随者自己的 id
+func withID(res batchResult, id json.RawMessage) batchResult {
+	if res.err != nil || res.status != http.StatusOK {
+		return res
+	}
+	var msg jsonrpcMessage
+	if err := json.Unmarshal(res.body, &msg); err != nil {
+		return res
+	}
+	msg.ID = id
+	out, _ := json.Marshal(msg)
+	return batchResult{status: res.status, header: res.header, body: out}
+}
+
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// isHTTPURL 只有 HTTP 端点才走批量器
+func isHTTPURL(url string) bool {
+	return strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")
+}
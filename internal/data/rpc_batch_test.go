@@ -0,0 +1,248 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+)
+
+// recordingRPC 记录收到的 HTTP 请求数和 JSON-RPC 调用数，每个调用返回 "<method>:<params>"
+type recordingRPC struct {
+	mu    sync.Mutex
+	posts int
+	calls int
+}
+
+func (s *recordingRPC) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	reply := func(m jsonrpcMessage) jsonrpcMessage {
+		result, _ := json.Marshal(m.Method + ":" + string(m.Params))
+		return jsonrpcMessage{Version: "2.0", ID: m.ID, Result: result}
+	}
+
+	s.mu.Lock()
+	s.posts++
+	s.mu.Unlock()
+	if body = bytes.TrimSpace(body); len(body) > 0 && body[0] == '[' {
+		var batch []jsonrpcMessage
+		_ = json.Unmarshal(body, &batch)
+		out := make([]jsonrpcMessage, 0, len(batch))
+		for _, m := range batch {
+			out = append(out, reply(m))
+		}
+		s.mu.Lock()
+		s.calls += len(batch)
+		s.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(out)
+		return
+	}
+	var m jsonrpcMessage
+	_ = json.Unmarshal(body, &m)
+	s.mu.Lock()
+	s.calls++
+	s.mu.Unlock()
+	_ = json.NewEncoder(w).Encode(reply(m))
+}
+
+func TestNewBatchPolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		conf config.BatchConfig
+		node config.NodeConfig
+		want batchPolicy
+	}{
+		{"defaults", config.BatchConfig{}, config.NodeConfig{}, batchPolicy{window: defaultBatchWindow, maxSize: defaultMaxBatchSize, coalesce: true}},
+		{"node limit wins", config.BatchConfig{MaxSize: 50}, config.NodeConfig{MaxBatchSize: 10}, batchPolicy{window: defaultBatchWindow, maxSize: 10, coalesce: true}},
+		{"node without batch support", config.BatchConfig{}, config.NodeConfig{MaxBatchSize: 1}, batchPolicy{window: 0, maxSize: 1, coalesce: true}},
+		{"disabled", config.BatchConfig{Disable: true, DisableCoalesce: true}, config.NodeConfig{}, batchPolicy{window: 0, maxSize: 1, coalesce: false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newBatchPolicy(tt.conf, tt.node); got != tt.want {
+				t.Errorf("newBatchPolicy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBatchTransport(t *testing.T) {
+	const window = 50 * time.Millisecond
+	tests := []struct {
+		name      string
+		policy    batchPolicy
+		calls     []string // method，相同的 method 参数也相同
+		wantPosts int
+		wantCalls int
+	}{
+		{
+			name:      "concurrent calls share one batch",
+			policy:    batchPolicy{window: window, maxSize: 20, coalesce: true},
+			calls:     []string{"eth_blockNumber", "eth_chainId", "eth_gasPrice"},
+			wantPosts: 1,
+			wantCalls: 3,
+		},
+		{
+			name:      "max size splits batches",
+			policy:    batchPolicy{window: window, maxSize: 2, coalesce: true},
+			calls:     []string{"eth_blockNumber", "eth_chainId", "eth_gasPrice", "net_version"},
+			wantPosts: 2,
+			wantCalls: 4,
+		},
+		{
+			name:      "identical calls coalesce",
+			policy:    batchPolicy{window: window, maxSize: 20, coalesce: true},
+			calls:     []string{"eth_blockNumber", "eth_blockNumber", "eth_blockNumber", "eth_blockNumber"},
+			wantPosts: 1,
+			wantCalls: 1,
+		},
+		{
+			name:      "writes are never coalesced",
+			policy:    batchPolicy{window: window, maxSize: 20, coalesce: true},
+			calls:     []string{"eth_sendRawTransaction", "eth_sendRawTransaction", "eth_sendRawTransaction"},
+			wantPosts: 1,
+			wantCalls: 3,
+		},
+		{
+			name:      "coalescing disabled",
+			policy:    batchPolicy{window: window, maxSize: 20, coalesce: false},
+			calls:     []string{"eth_blockNumber", "eth_blockNumber"},
+			wantPosts: 1,
+			wantCalls: 2,
+		},
+		{
+			name:      "batching disabled",
+			policy:    batchPolicy{window: 0, maxSize: 1, coalesce: false},
+			calls:     []string{"eth_blockNumber", "eth_chainId", "eth_gasPrice"},
+			wantPosts: 3,
+			wantCalls: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &recordingRPC{}
+			srv := httptest.NewServer(rec)
+			defer srv.Close()
+			client := &http.Client{Transport: newBatchTransport("test", tt.policy, nil)}
+
+			var wg sync.WaitGroup
+			errs := make(chan error, len(tt.calls))
+			for i, method := range tt.calls {
+				wg.Add(1)
+				go func(id int, method string) {
+					defer wg.Done()
+					errs <- postCall(client, srv.URL, id, method)
+				}(i+1, method)
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if rec.posts != tt.wantPosts || rec.calls != tt.wantCalls {
+				t.Errorf("server saw %d posts / %d calls, want %d / %d", rec.posts, rec.calls, tt.wantPosts, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestBatchTransportPassesThroughBatches(t *testing.T) {
+	rec := &recordingRPC{}
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+	client := &http.Client{Transport: newBatchTransport("test", batchPolicy{window: time.Millisecond, maxSize: 20, coalesce: true}, nil)}
+
+	body := `[{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]},{"jsonrpc":"2.0","id":2,"method":"eth_chainId","params":[]}]`
+	resp, err := client.Post(srv.URL, "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var out []jsonrpcMessage
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 || rec.posts != 1 || rec.calls != 2 {
+		t.Errorf("got %d responses, server saw %d posts / %d calls, want 2 / 1 / 2", len(out), rec.posts, rec.calls)
+	}
+}
+
+func TestBatchContextDeadline(t *testing.T) {
+	now := time.Now()
+	call := func(deadline time.Duration) *batchCall {
+		ctx := context.Background()
+		if deadline > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithDeadline(ctx, now.Add(deadline))
+			t.Cleanup(cancel)
+		}
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "http://node", nil)
+		return &batchCall{req: req}
+	}
+
+	tests := []struct {
+		name  string
+		batch []*batchCall
+		min   time.Duration // 截止时间 (相对 now) 的范围
+		max   time.Duration
+	}{
+		{name: "latest caller deadline", batch: []*batchCall{call(time.Second), call(5 * time.Second), call(2 * time.Second)}, min: 5 * time.Second, max: 5 * time.Second},
+		{name: "caller without deadline", batch: []*batchCall{call(time.Second), call(0)}, min: defaultBatchTimeout, max: defaultBatchTimeout + time.Second},
+		{name: "caller deadline beyond default", batch: []*batchCall{call(0), call(time.Minute)}, min: time.Minute, max: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := batchContext(tt.batch)
+			defer cancel()
+			deadline, ok := ctx.Deadline()
+			if !ok {
+				t.Fatal("batch context has no deadline")
+			}
+			if d := deadline.Sub(now); d < tt.min || d > tt.max {
+				t.Errorf("deadline = now + %v, want between %v and %v", d, tt.min, tt.max)
+			}
+		})
+	}
+
+	// 调用者取消不影响批量请求
+	ctx, cancelCaller := context.WithTimeout(context.Background(), time.Second)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, "http://node", nil)
+	batchCtx, cancel := batchContext([]*batchCall{{req: req}, call(time.Second)})
+	defer cancel()
+	cancelCaller()
+	if err := batchCtx.Err(); err != nil {
+		t.Errorf("batch context canceled with caller: %v", err)
+	}
+}
+
+// postCall 发送一个 JSON-RPC 调用，校验响应的 id 和结果属于自己
+func postCall(client *http.Client, url string, id int, method string) error {
+	body := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":%q,"params":["0x1"]}`, id, method)
+	resp, err := client.Post(url, "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var msg jsonrpcMessage
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		return fmt.Errorf("call %d: %w", id, err)
+	}
+	var result string
+	_ = json.Unmarshal(msg.Result, &result)
+	if string(msg.ID) != fmt.Sprint(id) || result != method+`:["0x1"]` {
+		return fmt.Errorf("call %d (%s): got id %s result %q", id, method, msg.ID, result)
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package data
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+	"github.com/zy99978455-otw/go-micro-template/pkg/global"
+)
+
+// BreakerState 熔断器状态
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // 正常放行
+	BreakerOpen                         // 熔断：拒绝所有请求，等待冷却
+	BreakerHalfOpen                     // 半开：放少量试探请求
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText 让状态在 JSON 里显示为字符串
+func (s BreakerState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+const (
+	defaultBreakerConsecutiveFailures = 5
+	defaultBreakerErrorRate           = 0.5
+	defaultBreakerMinRequests         = 20
+	defaultBreakerWindowSize          = 100
+	defaultBreakerOpenTimeout         = 10 * time.Second
+	defaultBreakerMaxOpenTimeout      = 5 * time.Minute
+	defaultBreakerHalfOpenRequests    = 3
+	maxBreakerTransitions             = 20 // 每个节点保留最近 20 次状态变化
+)
+
+// BreakerTransition 一次状态变化记录
+type BreakerTransition struct {
+	From   BreakerState `json:"from"`
+	To     BreakerState `json:"to"`
+	Reason string       `json:"reason"`
+	At     time.Time    `json:"at"`
+}
+
+// breakerPolicy 熔断参数 (已填充默认值)
+type breakerPolicy struct {
+	consecutiveFailures int
+	errorRate           float64
+	minRequests         int
+	windowSize          int
+	openTimeout         time.Duration
+	maxOpenTimeout      time.Duration
+	halfOpenRequests    int
+}
+
+func newBreakerPolicy(c config.CircuitBreakerConfig) breakerPolicy {
+	p := breakerPolicy{
+		consecutiveFailures: c.ConsecutiveFailures,
+		errorRate:           c.ErrorRate,
+		minRequests:         c.MinRequests,
+		windowSize:          c.WindowSize,
+		openTimeout:         c.OpenTimeout,
+		maxOpenTimeout:      c.MaxOpenTimeout,
+		halfOpenRequests:    c.HalfOpenRequests,
+	}
+	if p.consecutiveFailures <= 0 {
+		p.consecutiveFailures = defaultBreakerConsecutiveFailures
+	}
+	if p.errorRate <= 0 || p.errorRate > 1 {
+		p.errorRate = defaultBreakerErrorRate
+	}
+	if p.minRequests <= 0 {
+		p.minRequests = defaultBreakerMinRequests
+	}
+	if p.windowSize <= 0 {
+		p.windowSize = defaultBreakerWindowSize
+	}
+	if p.minRequests > p.windowSize {
+		p.minRequests = p.windowSize
+	}
+	if p.openTimeout <= 0 {
+		p.openTimeout = defaultBreakerOpenTimeout
+	}
+	if p.maxOpenTimeout < p.openTimeout {
+		p.maxOpenTimeout = defaultBreakerMaxOpenTimeout
+		if p.maxOpenTimeout < p.openTimeout {
+			p.maxOpenTimeout = p.openTimeout
+		}
+	}
+	if p.halfOpenRequests <= 0 {
+		p.halfOpenRequests = defaultBreakerHalfOpenRequests
+	}
+	return p
+}
+
+// circuitBreaker 节点级熔断器
+// 1. closed: 连续失败次数或窗口内错误率超过阈值 -> open
+// 2. open: 冷却时间到 -> half_open (冷却时间随连续熔断次数指数增长)
+// 3. half_open: 只放 halfOpenRequests 个试探请求，全部成功 -> closed，任一失败 -> open
+type circuitBreaker struct {
+	name   string // 节点名称，仅用于日志
+	policy breakerPolicy
+
+	mu          sync.Mutex
+	state       BreakerState
+	consecutive int    // 连续失败次数
+	window      []bool // 最近 windowSize 次结果 (true = 失败)，环形缓冲
+	windowPos   int
+	windowFails int
+	openedAt    time.Time
+	cooldown    time.Duration
+	trips       int // 连续熔断次数 (决定下一次冷却时间)
+	trialsOut   int // 半开状态下已放出的试探请求
+	trialsOK    int // 半开状态下已成功的试探请求
+	transitions []BreakerTransition
+}
+
+func newCircuitBreaker(name string, policy breakerPolicy) *circuitBreaker {
+	return &circuitBreaker{
+		name:   name,
+		policy: policy,
+		window: make([]bool, 0, policy.windowSize),
+	}
+}
+
+// State 当前状态 (open 且冷却已结束时视为 half_open)
+func (b *circuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// Ready 是否可能放行请求 (不占用试探名额，用于筛选候选节点)
+func (b *circuitBreaker) Ready() bool {
+	return b.State() != BreakerOpen
+}
+
+// Closed 是否处于正常状态
+func (b *circuitBreaker) Closed() bool {
+	return b.State() == BreakerClosed
+}
+
+// Allow 申请放行一个请求；半开状态下会占用一个试探名额，调用方必须随后调用 Record
+func (b *circuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerClosed:
+		return true
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.transition(BreakerHalfOpen, "cool-down elapsed")
+		fallthrough
+	case BreakerHalfOpen:
+		if b.trialsOut >= b.policy.halfOpenRequests {
+			return false
+		}
+		b.trialsOut++
+		return true
+	}
+	return false
+}
+
+// Record 上报一次请求结果 (健康检查和真实请求共用)
+func (b *circuitBreaker) Record(success bool, reason string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		// 冷却期内的结果不参与计算
+		return
+	case BreakerHalfOpen:
+		if !success {
+			b.trip("half-open trial failed: " + reason)
+			return
+		}
+		b.trialsOK++
+		if b.trialsOK >= b.policy.halfOpenRequests {
+			b.trips = 0
+			b.resetCounters()
+			b.transition(BreakerClosed, "half-open trials succeeded")
+		}
+		return
+	}
+
+	// closed: 更新连续失败和滑动窗口
+	b.push(!success)
+	if success {
+		b.consecutive = 0
+		return
+	}
+	b.consecutive++
+
+	if b.consecutive >= b.policy.consecutiveFailures {
+		b.trip("consecutive failures: " + reason)
+		return
+	}
+	if total := len(b.window); total >= b.policy.minRequests &&
+		float64(b.windowFails)/float64(total) >= b.policy.errorRate {
+		b.trip("error rate exceeded: " + reason)
+	}
+}
+
+// Release 归还半开试探名额 (请求被调用方取消，结果不计入统计)
+func (b *circuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerHalfOpen && b.trialsOut > 0 {
+		b.trialsOut--
+	}
+}
+
+// Transitions 最近的状态变化记录 (旧 -> 新)
+func (b *circuitBreaker) Transitions() []BreakerTransition {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]BreakerTransition(nil), b.transitions...)
+}
+
+// push 写入滑动窗口
+func (b *circuitBreaker) push(failed bool) {
+	if len(b.window) < b.policy.windowSize {
+		b.window = append(b.window, failed)
+	} else {
+		if b.window[b.windowPos] {
+			b.windowFails--
+		}
+		b.window[b.windowPos] = failed
+		b.windowPos = (b.windowPos + 1) % b.policy.windowSize
+	}
+	if failed {
+		b.windowFails++
+	}
+}
+
+// trip 进入 open 状态，冷却时间指数增长
+func (b *circuitBreaker) trip(reason string) {
+	b.cooldown = b.policy.openTimeout << b.trips
+	if b.cooldown <= 0 || b.cooldown > b.policy.maxOpenTimeout {
+		b.cooldown = b.policy.maxOpenTimeout
+	} else {
+		b.trips++
+	}
+	b.openedAt = time.Now()
+	b.resetCounters()
+	b.transition(BreakerOpen, reason)
+}
+
+func (b *circuitBreaker) resetCounters() {
+	b.consecutive = 0
+	b.window = b.window[:0]
+	b.windowPos = 0
+	b.windowFails = 0
+	b.trialsOut = 0
+	b.trialsOK = 0
+}
+
+// transition 切换状态并记录日志 (调用方持有锁)
+func (b *circuitBreaker) transition(to BreakerState, reason string) {
+	from := b.state
+	b.state = to
+	if to == BreakerHalfOpen {
+		b.trialsOut, b.trialsOK = 0, 0
+	}
+
+	b.transitions = append(b.transitions, BreakerTransition{From: from, To: to, Reason: reason, At: time.Now()})
+	if len(b.transitions) > maxBreakerTransitions {
+		b.transitions = b.transitions[len(b.transitions)-maxBreakerTransitions:]
+	}
+
+	if global.Log == nil {
+		return
+	}
+	switch to {
+	case BreakerOpen:
+		global.Log.Warnf("🔌 [RPC] Breaker %s: %s -> %s (cool-down %v), reason: %s", b.name, from, to, b.cooldown, reason)
+	default:
+		global.Log.Infof("🔌 [RPC] Breaker %s: %s -> %s, reason: %s", b.name, from, to, reason)
+	}
+}
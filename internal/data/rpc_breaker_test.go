@@ -0,0 +1,197 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+)
+
+func TestNewBreakerPolicyDefaults(t *testing.T) {
+	tests := []struct {
+		name string
+		conf config.CircuitBreakerConfig
+		want breakerPolicy
+	}{
+		{
+			name: "empty config uses defaults",
+			want: breakerPolicy{
+				consecutiveFailures: defaultBreakerConsecutiveFailures,
+				errorRate:           defaultBreakerErrorRate,
+				minRequests:         defaultBreakerMinRequests,
+				windowSize:          defaultBreakerWindowSize,
+				openTimeout:         defaultBreakerOpenTimeout,
+				maxOpenTimeout:      defaultBreakerMaxOpenTimeout,
+				halfOpenRequests:    defaultBreakerHalfOpenRequests,
+			},
+		},
+		{
+			name: "min requests capped by window, invalid error rate reset",
+			conf: config.CircuitBreakerConfig{ErrorRate: 1.5, MinRequests: 50, WindowSize: 10},
+			want: breakerPolicy{
+				consecutiveFailures: defaultBreakerConsecutiveFailures,
+				errorRate:           defaultBreakerErrorRate,
+				minRequests:         10,
+				windowSize:          10,
+				openTimeout:         defaultBreakerOpenTimeout,
+				maxOpenTimeout:      defaultBreakerMaxOpenTimeout,
+				halfOpenRequests:    defaultBreakerHalfOpenRequests,
+			},
+		},
+		{
+			name: "max open timeout never below open timeout",
+			conf: config.CircuitBreakerConfig{OpenTimeout: 10 * time.Minute, MaxOpenTimeout: time.Minute},
+			want: breakerPolicy{
+				consecutiveFailures: defaultBreakerConsecutiveFailures,
+				errorRate:           defaultBreakerErrorRate,
+				minRequests:         defaultBreakerMinRequests,
+				windowSize:          defaultBreakerWindowSize,
+				openTimeout:         10 * time.Minute,
+				maxOpenTimeout:      10 * time.Minute,
+				halfOpenRequests:    defaultBreakerHalfOpenRequests,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newBreakerPolicy(tt.conf); got != tt.want {
+				t.Errorf("newBreakerPolicy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// breakerStep 对熔断器的一次操作及操作后期望的状态
+type breakerStep struct {
+	op   string // ok / fail: Record；allow / deny: Allow 的期望结果；cool: 等待冷却结束
+	want BreakerState
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	policy := breakerPolicy{
+		consecutiveFailures: 3,
+		errorRate:           0.5,
+		minRequests:         4,
+		windowSize:          4,
+		openTimeout:         20 * time.Millisecond,
+		maxOpenTimeout:      time.Second,
+		halfOpenRequests:    2,
+	}
+	tests := []struct {
+		name   string
+		window int // 覆盖 windowSize / minRequests (0 = 用上面的 policy)
+		steps  []breakerStep
+	}{
+		{
+			name: "consecutive failures trip",
+			steps: []breakerStep{
+				{"fail", BreakerClosed}, {"fail", BreakerClosed}, {"fail", BreakerOpen}, {"deny", BreakerOpen},
+			},
+		},
+		{
+			name:   "success resets consecutive failures",
+			window: 100,
+			steps: []breakerStep{
+				{"fail", BreakerClosed}, {"fail", BreakerClosed}, {"ok", BreakerClosed},
+				{"fail", BreakerClosed}, {"fail", BreakerClosed}, {"allow", BreakerClosed}, {"fail", BreakerOpen},
+			},
+		},
+		{
+			name: "error rate trips once min requests reached",
+			steps: []breakerStep{
+				{"fail", BreakerClosed}, {"ok", BreakerClosed}, {"fail", BreakerClosed}, {"fail", BreakerOpen},
+			},
+		},
+		{
+			name: "half-open trials succeed and close",
+			steps: []breakerStep{
+				{"fail", BreakerClosed}, {"fail", BreakerClosed}, {"fail", BreakerOpen},
+				{"cool", BreakerHalfOpen},
+				{"allow", BreakerHalfOpen}, {"allow", BreakerHalfOpen}, {"deny", BreakerHalfOpen},
+				{"ok", BreakerHalfOpen}, {"ok", BreakerClosed}, {"allow", BreakerClosed},
+			},
+		},
+		{
+			name: "half-open trial failure reopens",
+			steps: []breakerStep{
+				{"fail", BreakerClosed}, {"fail", BreakerClosed}, {"fail", BreakerOpen},
+				{"cool", BreakerHalfOpen}, {"allow", BreakerHalfOpen}, {"fail", BreakerOpen}, {"deny", BreakerOpen},
+				{"cool", BreakerHalfOpen}, {"allow", BreakerHalfOpen},
+			},
+		},
+		{
+			name: "results while open are ignored",
+			steps: []breakerStep{
+				{"fail", BreakerClosed}, {"fail", BreakerClosed}, {"fail", BreakerOpen},
+				{"ok", BreakerOpen}, {"ok", BreakerOpen}, {"deny", BreakerOpen},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := policy
+			if tt.window > 0 {
+				p.windowSize, p.minRequests = tt.window, tt.window
+			}
+			b := newCircuitBreaker("test", p)
+			for i, s := range tt.steps {
+				switch s.op {
+				case "ok":
+					b.Record(true, "")
+				case "fail":
+					b.Record(false, "test failure")
+				case "allow", "deny":
+					if got := b.Allow(); got != (s.op == "allow") {
+						t.Fatalf("step %d: Allow() = %v, want %v", i, got, s.op == "allow")
+					}
+				case "cool":
+					waitCooldown(t, b)
+				default:
+					t.Fatalf("step %d: unknown op %q", i, s.op)
+				}
+				if got := b.State(); got != s.want {
+					t.Fatalf("step %d (%s): state = %s, want %s", i, s.op, got, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerCooldownBackoff(t *testing.T) {
+	policy := newBreakerPolicy(config.CircuitBreakerConfig{ConsecutiveFailures: 1, OpenTimeout: time.Second, MaxOpenTimeout: 3 * time.Second})
+	b := newCircuitBreaker("test", policy)
+
+	// 半开试探失败会重新熔断，冷却时间翻倍直到上限
+	want := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}
+	for i, w := range want {
+		if i == 0 {
+			b.Record(false, "fail")
+		} else {
+			b.mu.Lock()
+			b.openedAt = time.Now().Add(-b.cooldown)
+			b.mu.Unlock()
+			if !b.Allow() {
+				t.Fatalf("trip %d: half-open trial not allowed", i)
+			}
+			b.Record(false, "fail")
+		}
+		b.mu.Lock()
+		got := b.cooldown
+		b.mu.Unlock()
+		if got != w {
+			t.Errorf("trip %d: cooldown = %s, want %s", i, got, w)
+		}
+	}
+}
+
+// waitCooldown 等待熔断冷却结束 (State 变为 half_open)
+func waitCooldown(t *testing.T, b *circuitBreaker) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for b.State() == BreakerOpen {
+		if time.Now().After(deadline) {
+			t.Fatal("breaker did not leave open state")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
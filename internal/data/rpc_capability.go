@@ -0,0 +1,351 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+	"github.com/zy99978455-otw/go-micro-template/pkg/global"
+)
+
+// defaultArchiveDepth 全节点默认只保留最近 128 个区块的状态，更早的状态查询需要归档节点
+const defaultArchiveDepth = 128
+
+// CapabilityState 能力状态
+type CapabilityState int8
+
+const (
+	CapabilityUnknown CapabilityState = iota // 未声明且未探测出结果：可以尝试，失败后学习
+	CapabilityYes
+	CapabilityNo
+)
+
+func (s CapabilityState) String() string {
+	switch s {
+	case CapabilityYes:
+		return "yes"
+	case CapabilityNo:
+		return "no"
+	default:
+		return "unknown"
+	}
+}
+
+func (s CapabilityState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func capabilityOf(b bool) CapabilityState {
+	if b {
+		return CapabilityYes
+	}
+	return CapabilityNo
+}
+
+// Capabilities 节点能力
+type Capabilities struct {
+	Archive     CapabilityState `json:"archive"`
+	Trace       CapabilityState `json:"trace"`
+	Debug       CapabilityState `json:"debug"`
+	MaxLogRange uint64          `json:"max_log_range,omitempty"` // 0 = 不限
+	WSS         bool            `json:"wss"`
+}
+
+// newCapabilities 根据配置声明初始化 (未声明的项为 unknown，连接后自动探测)
+func newCapabilities(c config.CapabilitiesConfig, wssURL string) Capabilities {
+	caps := Capabilities{MaxLogRange: c.MaxLogRange, WSS: wssURL != ""}
+	if c.Archive != nil {
+		caps.Archive = capabilityOf(*c.Archive)
+	}
+	if c.Trace != nil {
+		caps.Trace = capabilityOf(*c.Trace)
+	}
+	if c.Debug != nil {
+		caps.Debug = capabilityOf(*c.Debug)
+	}
+	return caps
+}
+
+// capRequirement 一次调用对节点能力的要求
+type capRequirement struct {
+	archive  bool
+	trace    bool
+	debug    bool
+	logRange uint64 // eth_getLogs 的区块跨度
+}
+
+// satisfies 节点能力是否满足要求；confirmed = false 表示有 unknown 项 (可以尝试，但优先选确定支持的节点)
+func (c Capabilities) satisfies(req capRequirement) (ok, confirmed bool) {
+	confirmed = true
+	for _, need := range []struct {
+		required bool
+		state    CapabilityState
+	}{{req.archive, c.Archive}, {req.trace, c.Trace}, {req.debug, c.Debug}} {
+		if !need.required {
+			continue
+		}
+		switch need.state {
+		case CapabilityNo:
+			return false, false
+		case CapabilityUnknown:
+			confirmed = false
+		}
+	}
+	if c.MaxLogRange > 0 && req.logRange > c.MaxLogRange {
+		return false, false
+	}
+	return true, confirmed
+}
+
+// ================= 调用 -> 能力要求 =================
+
+// require 返回带能力要求的门面副本
+func (c *ChainClient) require(req capRequirement) *ChainClient {
+	cp := *c
+	cp.req = req
+	return &cp
+}
+
+// stateAt 查询 blockNumber 的状态：比链头早 defaultArchiveDepth 个块以上需要归档节点
+func (c *ChainClient) stateAt(blockNumber *big.Int) capRequirement {
+	if blockNumber == nil || blockNumber.Sign() < 0 {
+		return capRequirement{}
+	}
+	head := c.mgr.bestHeight(c.chainID)
+	return capRequirement{archive: head > blockNumber.Uint64()+defaultArchiveDepth}
+}
+
+// logsRange eth_getLogs 的区块跨度
+func (c *ChainClient) logsRange(q ethereum.FilterQuery) capRequirement {
+	if q.BlockHash != nil || q.FromBlock == nil || q.FromBlock.Sign() < 0 {
+		return capRequirement{}
+	}
+	to := c.mgr.bestHeight(c.chainID)
+	if q.ToBlock != nil && q.ToBlock.Sign() >= 0 {
+		to = q.ToBlock.Uint64()
+	}
+	from := q.FromBlock.Uint64()
+	if to < from {
+		return capRequirement{}
+	}
+	return capRequirement{logRange: to - from + 1}
+}
+
+// namespaceOf debug_* / trace_* 方法需要对应的命名空间
+func namespaceOf(method string) capRequirement {
+	return capRequirement{
+		debug: strings.HasPrefix(method, "debug_"),
+		trace: strings.HasPrefix(method, "trace_"),
+	}
+}
+
+// CallRaw 调用任意 JSON-RPC 方法 (如 debug_traceTransaction / trace_block)，只会发给开放了对应命名空间的节点
+func (c *ChainClient) CallRaw(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	return c.require(namespaceOf(method)).call(ctx, method, func(ctx context.Context, cl *ethclient.Client) error {
+		return cl.Client().CallContext(ctx, result, method, args...)
+	})
+}
+
+// bestHeight 链上已知的最高高度 (来自健康检查)
+func (m *RPCManager) bestHeight(chainID int64) uint64 {
+	m.mu.RLock()
+	nodes := m.chainNodes[chainID]
+	m.mu.RUnlock()
+
+	var best uint64
+	for _, n := range nodes {
+		best = max(best, n.height())
+	}
+	return best
+}
+
+// logRangeLimit 该链 eth_getLogs 单次跨度上限：可用节点 max_log_range (配置或学习到的) 中最小的非 0 值，都不限时返回 0
+// 隔离、摘流、不健康、熔断中的节点不会被路由到，不参与计算
+func (m *RPCManager) logRangeLimit(chainID int64) uint64 {
+	m.mu.RLock()
+	nodes := m.chainNodes[chainID]
+	m.mu.RUnlock()
+
+	var limit uint64
+	for _, n := range nodes {
+		if !n.available() {
+			continue
+		}
+		if r := n.capabilities().MaxLogRange; r > 0 && (limit == 0 || r < limit) {
+			limit = r
+		}
+	}
+	return limit
+}
+
+// logChunkSize eth_getLogs 需要拆分时每段的区块数
+// 有不限跨度的可用节点时返回 0 (不拆分，交给该节点)；否则取可用节点中最大的上限，由能满足的节点执行
+func (m *RPCManager) logChunkSize(chainID int64) uint64 {
+	m.mu.RLock()
+	nodes := m.chainNodes[chainID]
+	m.mu.RUnlock()
+
+	var size uint64
+	for _, n := range nodes {
+		if !n.available() {
+			continue
+		}
+		r := n.capabilities().MaxLogRange
+		if r == 0 {
+			return 0
+		}
+		size = max(size, r)
+	}
+	return size
+}
+
+// ================= 自动探测与学习 =================
+
+// detectCapabilities 连接 (重连) 后探测未声明的能力
+// 1. 归档：查询 1 号区块的余额，报缺少状态说明是裁剪过的全节点
+// 2. 命名空间：优先 rpc_modules，不支持时分别试探 debug / trace 的轻量方法
+func (m *RPCManager) detectCapabilities(ctx context.Context, n *Node, client *ethclient.Client) {
+	n.mu.RLock()
+	declared := n.capsDeclared
+	before := n.caps
+	n.mu.RUnlock()
+
+	detected := before
+	if declared.Archive == nil {
+		_, err := client.BalanceAt(ctx, common.Address{}, big.NewInt(1))
+		switch {
+		case err == nil:
+			detected.Archive = CapabilityYes
+		case isMissingStateError(err):
+			detected.Archive = CapabilityNo
+		}
+	}
+
+	if declared.Debug == nil || declared.Trace == nil {
+		var modules map[string]string
+		modErr := client.Client().CallContext(ctx, &modules, "rpc_modules")
+		if declared.Debug == nil {
+			if modErr == nil {
+				_, ok := modules["debug"]
+				detected.Debug = capabilityOf(ok)
+			} else {
+				detected.Debug = probeMethod(ctx, client, "debug_getBadBlocks")
+			}
+		}
+		if declared.Trace == nil {
+			if modErr == nil {
+				_, ok := modules["trace"]
+				detected.Trace = capabilityOf(ok)
+			} else {
+				detected.Trace = probeMethod(ctx, client, "trace_block", "0x0")
+			}
+		}
+	}
+	if ctx.Err() != nil {
+		return
+	}
+
+	n.mu.Lock()
+	n.caps = detected
+	n.mu.Unlock()
+	if detected != before && global.Log != nil {
+		global.Log.Infof("🧭 [RPC] Node %s (chain %d) capabilities: archive=%s trace=%s debug=%s", n.Name, n.ChainID, detected.Archive, detected.Trace, detected.Debug)
+	}
+}
+
+// probeMethod 方法不存在 (-32601 或明确的不支持) 说明没有开放该命名空间；其他结果 (包括参数错误) 说明方法存在
+func probeMethod(ctx context.Context, client *ethclient.Client, method string, args ...interface{}) CapabilityState {
+	var raw interface{}
+	err := client.Client().CallContext(ctx, &raw, method, args...)
+	if err == nil {
+		return CapabilityYes
+	}
+	if isMethodUnsupported(err) {
+		return CapabilityNo
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return CapabilityYes
+	}
+	return CapabilityUnknown
+}
+
+// learn 调用因能力不足失败时记下来，之后不再把同类请求发给该节点 (配置声明的能力不覆盖)
+// eth_getLogs 跨度超限时把 max_log_range 降到本次跨度的一半，之后更大的查询会被拆分或发给其他节点
+func (n *Node) learn(req capRequirement, err error) {
+	n.mu.Lock()
+	before := n.caps
+	if req.archive && n.capsDeclared.Archive == nil && isMissingStateError(err) {
+		n.caps.Archive = CapabilityNo
+	}
+	if req.logRange > 1 && isLogRangeMessage(err.Error()) {
+		if limit := req.logRange / 2; n.caps.MaxLogRange == 0 || limit < n.caps.MaxLogRange {
+			n.caps.MaxLogRange = limit
+		}
+	}
+	if isMethodUnsupported(err) {
+		if req.debug && n.capsDeclared.Debug == nil {
+			n.caps.Debug = CapabilityNo
+		}
+		if req.trace && n.capsDeclared.Trace == nil {
+			n.caps.Trace = CapabilityNo
+		}
+	}
+	after := n.caps
+	n.mu.Unlock()
+
+	if after != before && global.Log != nil {
+		global.Log.Infof("🧭 [RPC] Node %s (chain %d) capabilities learned: archive=%s trace=%s debug=%s max_log_range=%d", n.Name, n.ChainID, after.Archive, after.Trace, after.Debug, after.MaxLogRange)
+	}
+}
+
+func (n *Node) capabilities() Capabilities {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.caps
+}
+
+// ================= 错误识别 =================
+
+// 裁剪节点查询历史状态时的常见报错
+var missingStateMessages = []string{
+	"missing trie node",
+	"state not available",
+	"state is not available",
+	"historical state",
+	"pruned",
+	"state histories haven't been fully indexed",
+}
+
+func isMissingStateError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range missingStateMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMethodUnsupported 节点不支持 (或服务商未开放) 该方法
+func isMethodUnsupported(err error) bool {
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == rpcCodeMethodNotFound {
+		return true
+	}
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "method not found") || strings.Contains(msg, "does not exist") ||
+		strings.Contains(msg, "not supported") || strings.Contains(msg, "not available on")
+}
@@ -0,0 +1,44 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+)
+
+func TestLogRangeIgnoresUnavailableNodes(t *testing.T) {
+	tests := []struct {
+		name      string
+		ranges    []uint64 // 各节点的 max_log_range (0 = 不限)
+		out       int      // 退出轮换 (摘流) 的节点
+		wantChunk uint64
+		wantLimit uint64
+	}{
+		{name: "all available", ranges: []uint64{0, 1000}, out: -1, wantChunk: 0, wantLimit: 1000},
+		{name: "unlimited node drained", ranges: []uint64{0, 1000}, out: 0, wantChunk: 1000, wantLimit: 1000},
+		{name: "smallest limit drained", ranges: []uint64{500, 2000}, out: 0, wantChunk: 2000, wantLimit: 2000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := config.ChainConfig{ChainID: 1}
+			for _, r := range tt.ranges {
+				srv := newTestRPCServer(t, 1, nil)
+				chain.Nodes = append(chain.Nodes, config.NodeConfig{RpcUrl: srv.URL, Capabilities: config.CapabilitiesConfig{MaxLogRange: r}})
+			}
+			mgr := startTestManager(t, chain)
+			if tt.out >= 0 {
+				n := mgr.chainNodes[1][tt.out]
+				n.mu.Lock()
+				n.Draining = true
+				n.mu.Unlock()
+			}
+
+			if got := mgr.logChunkSize(1); got != tt.wantChunk {
+				t.Errorf("logChunkSize() = %d, want %d", got, tt.wantChunk)
+			}
+			if got := mgr.logRangeLimit(1); got != tt.wantLimit {
+				t.Errorf("logRangeLimit() = %d, want %d", got, tt.wantLimit)
+			}
+		})
+	}
+}
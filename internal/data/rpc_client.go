@@ -0,0 +1,259 @@
+package data
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/zy99978455-otw/go-micro-template/pkg/global"
+)
+
+const defaultMaxAttempts = 3 // 默认最多尝试 3 个节点
+
+// ChainClient 链级别的 RPC 客户端门面
+// 方法签名与 ethclient 保持一致，内部按该链的负载均衡策略选节点，
+// 幂等调用遇到网络/节点错误时自动换下一个节点重试，结果回报给节点健康统计
+// 通过 Data.GetChainClient 获取时带 Redis 缓存 (见 chain_cache.go)
+type ChainClient struct {
+	mgr     *RPCManager
+	chainID int64
+	cache   *ChainCache    // nil 表示不缓存
+	req     capRequirement // 对节点能力的要求 (见 rpc_capability.go)
+}
+
+// Client 获取指定链的客户端门面
+func (m *RPCManager) Client(chainID int64) *ChainClient {
+	return &ChainClient{mgr: m, chainID: chainID}
+}
+
+// ChainID 返回该门面对应的链 ID
+func (c *ChainClient) ChainID() int64 {
+	return c.chainID
+}
+
+// ================= 读接口 (幂等，可跨节点重试，可对冲) =================
+
+func (c *ChainClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.cache.blockNumber(ctx, c.chainID, func() (uint64, error) {
+		return read(ctx, c, "eth_blockNumber", func(ctx context.Context, cl *ethclient.Client) (uint64, error) {
+			return cl.BlockNumber(ctx)
+		})
+	})
+}
+
+func (c *ChainClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return read(ctx, c, "eth_getBlockByNumber", func(ctx context.Context, cl *ethclient.Client) (*types.Header, error) {
+		return cl.HeaderByNumber(ctx, number)
+	})
+}
+
+func (c *ChainClient) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return c.cache.headerByHash(ctx, c.chainID, hash, func() (*types.Header, error) {
+		return read(ctx, c, "eth_getBlockByHash", func(ctx context.Context, cl *ethclient.Client) (*types.Header, error) {
+			return cl.HeaderByHash(ctx, hash)
+		})
+	})
+}
+
+func (c *ChainClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	return c.cache.blockByNumber(ctx, c, number, func() (*types.Block, error) {
+		return read(ctx, c, "eth_getBlockByNumber", func(ctx context.Context, cl *ethclient.Client) (*types.Block, error) {
+			return cl.BlockByNumber(ctx, number)
+		})
+	})
+}
+
+func (c *ChainClient) BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return c.cache.blockByHash(ctx, c.chainID, hash, func() (*types.Block, error) {
+		return read(ctx, c, "eth_getBlockByHash", func(ctx context.Context, cl *ethclient.Client) (*types.Block, error) {
+			return cl.BlockByHash(ctx, hash)
+		})
+	})
+}
+
+func (c *ChainClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return read(ctx, c.require(c.stateAt(blockNumber)), "eth_getBalance", func(ctx context.Context, cl *ethclient.Client) (*big.Int, error) {
+		return cl.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+func (c *ChainClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return read(ctx, c.require(c.stateAt(blockNumber)), "eth_getTransactionCount", func(ctx context.Context, cl *ethclient.Client) (uint64, error) {
+		return cl.NonceAt(ctx, account, blockNumber)
+	})
+}
+
+func (c *ChainClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return read(ctx, c, "eth_getTransactionCount", func(ctx context.Context, cl *ethclient.Client) (uint64, error) {
+		return cl.PendingNonceAt(ctx, account)
+	})
+}
+
+func (c *ChainClient) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return c.cache.code(ctx, c, account, blockNumber, func() ([]byte, error) {
+		return read(ctx, c.require(c.stateAt(blockNumber)), "eth_getCode", func(ctx context.Context, cl *ethclient.Client) ([]byte, error) {
+			return cl.CodeAt(ctx, account, blockNumber)
+		})
+	})
+}
+
+func (c *ChainClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return read(ctx, c.require(c.stateAt(blockNumber)), "eth_call", func(ctx context.Context, cl *ethclient.Client) ([]byte, error) {
+		return cl.CallContract(ctx, msg, blockNumber)
+	})
+}
+
+func (c *ChainClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return read(ctx, c, "eth_estimateGas", func(ctx context.Context, cl *ethclient.Client) (uint64, error) {
+		return cl.EstimateGas(ctx, msg)
+	})
+}
+
+func (c *ChainClient) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return read(ctx, c, "eth_gasPrice", func(ctx context.Context, cl *ethclient.Client) (*big.Int, error) {
+		return cl.SuggestGasPrice(ctx)
+	})
+}
+
+func (c *ChainClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return read(ctx, c, "eth_maxPriorityFeePerGas", func(ctx context.Context, cl *ethclient.Client) (*big.Int, error) {
+		return cl.SuggestGasTipCap(ctx)
+	})
+}
+
+// FilterLogs 区块跨度超过节点的 max_log_range (配置或学习到的) 时按段查询后合并
+// 节点报跨度超限时会学到更小的上限 (见 Node.learn)，随后按新上限拆分重试
+func (c *ChainClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	span := c.logsRange(q).logRange
+	if size := c.mgr.logChunkSize(c.chainID); size > 0 && span > size {
+		return c.filterLogsChunked(ctx, q, size)
+	}
+	logs, err := c.filterLogs(ctx, q)
+	if err != nil && isLogRangeMessage(err.Error()) {
+		if size := c.mgr.logChunkSize(c.chainID); size > 0 && span > size {
+			return c.filterLogsChunked(ctx, q, size)
+		}
+	}
+	return logs, err
+}
+
+func (c *ChainClient) filterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	return read(ctx, c.require(c.logsRange(q)), "eth_getLogs", func(ctx context.Context, cl *ethclient.Client) ([]types.Log, error) {
+		return cl.FilterLogs(ctx, q)
+	})
+}
+
+// filterLogsChunked 按 size 个区块一段顺序查询；最后一段保留原来的 ToBlock (如 latest)
+func (c *ChainClient) filterLogsChunked(ctx context.Context, q ethereum.FilterQuery, size uint64) ([]types.Log, error) {
+	from := q.FromBlock.Uint64()
+	end := from + c.logsRange(q).logRange - 1
+
+	var logs []types.Log
+	for from <= end {
+		cq := q
+		cq.FromBlock = new(big.Int).SetUint64(from)
+		if to := from + size - 1; to < end {
+			cq.ToBlock = new(big.Int).SetUint64(to)
+		}
+		part, err := c.FilterLogs(ctx, cq)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, part...)
+		from += size
+	}
+	return logs, nil
+}
+
+func (c *ChainClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	type result struct {
+		tx        *types.Transaction
+		isPending bool
+	}
+	r, err := read(ctx, c, "eth_getTransactionByHash", func(ctx context.Context, cl *ethclient.Client) (result, error) {
+		tx, isPending, err := cl.TransactionByHash(ctx, hash)
+		return result{tx, isPending}, err
+	})
+	return r.tx, r.isPending, err
+}
+
+func (c *ChainClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return c.cache.receipt(ctx, c, txHash, func() (*types.Receipt, error) {
+		return read(ctx, c, "eth_getTransactionReceipt", func(ctx context.Context, cl *ethclient.Client) (*types.Receipt, error) {
+			return cl.TransactionReceipt(ctx, txHash)
+		})
+	})
+}
+
+// ================= 写接口 =================
+
+// SendTransaction 广播已签名交易
+// 同一笔签名交易重复广播是安全的：网络错误时换节点重试，节点回复 "already known" 视为成功
+// 重试时节点回复 "nonce too low" / "replacement transaction underpriced"，可能是前一次广播其实已送达：
+// 向该节点按哈希确认，查到这笔交易即视为成功，否则返回原错误 (执行类错误，不再换节点)
+func (c *ChainClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	attempts := 0
+	return c.call(ctx, "eth_sendRawTransaction", func(ctx context.Context, cl *ethclient.Client) error {
+		attempts++
+		err := cl.SendTransaction(ctx, tx)
+		if isAlreadyKnown(err) {
+			return nil
+		}
+		if attempts > 1 && isNonceConflict(err) {
+			if found, _, lookupErr := cl.TransactionByHash(ctx, tx.Hash()); lookupErr == nil && found != nil {
+				if global.Log != nil {
+					global.Log.Infof("📨 [RPC] chain %d tx %s was delivered by an earlier attempt (%v)", c.chainID, tx.Hash().Hex(), err)
+				}
+				return nil
+			}
+		}
+		return err
+	})
+}
+
+// ================= 重试核心 =================
+
+// call 选节点执行 fn；遇到网络/节点错误或限流会换一个没试过的节点重试，
+// 执行类错误 (revert 等) 和调用方取消直接返回；method 用于指标
+func (c *ChainClient) call(ctx context.Context, method string, fn func(ctx context.Context, cl *ethclient.Client) error) error {
+	err := c.callWith(ctx, method, newTriedNodes(), fn)
+	c.mgr.metrics.calls.WithLabelValues(chainLabel(c.chainID), method, classifyError(ctx, err).String()).Inc()
+	return err
+}
+
+// callWith 同 call，已尝试的节点记录在 tried 中 (对冲请求共享，见 rpc_hedge.go)；不计入 rpc_calls_total
+func (c *ChainClient) callWith(ctx context.Context, method string, tried *triedNodes, fn func(ctx context.Context, cl *ethclient.Client) error) error {
+	attempts := c.mgr.maxAttempts(c.chainID)
+
+	var lastErr error
+	for i := 0; i < attempts; i++ {
+		node, err := c.mgr.pickNode(c.chainID, true, tried.snapshot(), c.req)
+		if err != nil {
+			// 一个节点都没试过就没有可用节点，直接返回选择错误；否则返回上一次调用的错误
+			if lastErr == nil {
+				return err
+			}
+			return lastErr
+		}
+		tried.add(node)
+
+		err = c.mgr.execute(ctx, node, method, fn)
+		class := classifyError(ctx, err)
+		switch class {
+		case errClassNone, errClassExecution, errClassCanceled:
+			return err
+		case errClassUnsupported:
+			// 能力不足 (裁剪节点 / 未开放命名空间)：记下来，之后同类请求不再发给它
+			node.learn(c.req, err)
+		}
+		lastErr = err
+		c.mgr.metrics.failovers.WithLabelValues(chainLabel(c.chainID), method, node.Name, class.String()).Inc()
+		if global.Log != nil {
+			global.Log.Warnf("🔁 [RPC] chain %d node %s failed, trying next node: %v", c.chainID, node.Name, err)
+		}
+	}
+	return lastErr
+}
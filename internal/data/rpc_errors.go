@@ -0,0 +1,147 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// errClass RPC 错误分类，决定是否重试以及是否算作节点故障
+type errClass int
+
+const (
+	errClassNone        errClass = iota // 成功 (包括 NotFound 这类正常应答)
+	errClassExecution                   // 执行类错误 (revert / 参数错误 / 余额不足)：节点是好的，换节点结果也一样
+	errClassUnsupported                 // 节点不支持该方法：换节点重试，但不算节点故障
+	errClassRateLimited                 // 服务商限流 / 额度用完：换节点重试，属于背压而不是节点故障
+	errClassCanceled                    // 调用方取消或超时：直接返回
+	errClassTransport                   // 网络 / 节点内部错误：换节点重试，计入节点故障
+)
+
+// JSON-RPC 错误码
+const (
+	rpcCodeExecutionReverted = 3
+	rpcCodeInvalidParams     = -32602
+	rpcCodeMethodNotFound    = -32601
+)
+
+// 节点返回 -32000 时，这些消息说明是请求本身的问题，而不是节点的问题
+var executionErrMessages = []string{
+	"execution reverted",
+	"revert",
+	"invalid opcode",
+	"out of gas",
+	"gas required exceeds",
+	"insufficient funds",
+	"nonce too low",
+	"nonce too high",
+	"replacement transaction underpriced",
+	"transaction underpriced",
+	"already known",
+	"invalid sender",
+	"intrinsic gas too low",
+	"exceeds block gas limit",
+	"max fee per gas less than block base fee",
+}
+
+// classifyError 对一次调用的结果分类
+func classifyError(ctx context.Context, err error) errClass {
+	if err == nil || errors.Is(err, ethereum.NotFound) {
+		return errClassNone
+	}
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return errClassCanceled
+	}
+	if errors.Is(err, errBudgetExhausted) {
+		return errClassRateLimited
+	}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests {
+		return errClassRateLimited
+	}
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		// eth_getLogs 区块跨度 / 结果数超限 (有的服务商也用 -32005)：换节点或拆分查询，不是限流也不是节点故障
+		if isLogRangeMessage(rpcErr.Error()) {
+			return errClassUnsupported
+		}
+		switch rpcErr.ErrorCode() {
+		case rpcCodeLimitExceeded, rpcCodeTooManyReqs:
+			return errClassRateLimited
+		case rpcCodeExecutionReverted, rpcCodeInvalidParams:
+			return errClassExecution
+		case rpcCodeMethodNotFound:
+			return errClassUnsupported
+		}
+		if isExecutionMessage(rpcErr.Error()) {
+			return errClassExecution
+		}
+		if isRateLimitMessage(rpcErr.Error()) {
+			return errClassRateLimited
+		}
+		// 裁剪节点查不到历史状态：换归档节点重试，不算节点故障
+		if isMissingStateError(rpcErr) {
+			return errClassUnsupported
+		}
+		return errClassTransport
+	}
+
+	// revert 时 ethclient 也可能只返回带 data 的错误
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) && isExecutionMessage(dataErr.Error()) {
+		return errClassExecution
+	}
+
+	return errClassTransport
+}
+
+// eth_getLogs 查询范围过大时服务商的常见报错
+var logRangeErrMessages = []string{
+	"query returned more than",   // Infura: query returned more than 10000 results
+	"block range",                // block range is too wide / exceed maximum block range / block range too large
+	"log response size exceeded", // Alchemy
+	"response size exceeded",
+	"is limited to a", // QuickNode: eth_getLogs is limited to a 10,000 range
+	"range is too large",
+	"range too large",
+}
+
+// isLogRangeMessage eth_getLogs 的区块跨度或结果数超过节点限制
+func isLogRangeMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, m := range logRangeErrMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func isExecutionMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, m := range executionErrMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAlreadyKnown 广播交易时节点已经有这笔交易 (重试广播时视为成功)
+func isAlreadyKnown(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "already known")
+}
+
+// isNonceConflict 广播交易时 nonce 已被占用 (可能正是同一笔交易，需要按哈希确认)
+func isNonceConflict(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce too low") || strings.Contains(msg, "replacement transaction underpriced")
+}
@@ -0,0 +1,110 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testRPCError 模拟节点返回的 JSON-RPC 错误 (实现 rpc.Error / rpc.DataError)
+type testRPCError struct {
+	code int
+	msg  string
+	data any
+}
+
+func (e testRPCError) Error() string  { return e.msg }
+func (e testRPCError) ErrorCode() int { return e.code }
+func (e testRPCError) ErrorData() any { return e.data }
+
+func TestClassifyError(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want errClass
+	}{
+		{"nil", nil, nil, errClassNone},
+		{"not found", nil, ethereum.NotFound, errClassNone},
+		{"caller canceled", canceled, errors.New("anything"), errClassCanceled},
+		{"context canceled error", nil, fmt.Errorf("post: %w", context.Canceled), errClassCanceled},
+		{"budget exhausted", nil, fmt.Errorf("node a: %w", errBudgetExhausted), errClassRateLimited},
+		{"http 429", nil, rpc.HTTPError{StatusCode: http.StatusTooManyRequests, Status: "429 Too Many Requests"}, errClassRateLimited},
+		{"http 502", nil, rpc.HTTPError{StatusCode: http.StatusBadGateway, Status: "502 Bad Gateway"}, errClassTransport},
+		{"-32005 rate limit", nil, testRPCError{code: rpcCodeLimitExceeded, msg: "daily request count exceeded, request rate limited"}, errClassRateLimited},
+		{"-32005 too many results", nil, testRPCError{code: rpcCodeLimitExceeded, msg: "query returned more than 10000 results"}, errClassUnsupported},
+		{"-32602 alchemy log size", nil, testRPCError{code: rpcCodeInvalidParams, msg: "Log response size exceeded. You can make eth_getLogs requests with up to a 2K block range"}, errClassUnsupported},
+		{"-32000 block range too wide", nil, testRPCError{code: -32000, msg: "block range is too wide"}, errClassUnsupported},
+		{"json-rpc 429", nil, testRPCError{code: rpcCodeTooManyReqs, msg: "slow down"}, errClassRateLimited},
+		{"rate limit message", nil, testRPCError{code: -32000, msg: "Too Many Requests"}, errClassRateLimited},
+		{"revert code", nil, testRPCError{code: rpcCodeExecutionReverted, msg: "execution reverted", data: "0x08c379a0"}, errClassExecution},
+		{"invalid params", nil, testRPCError{code: rpcCodeInvalidParams, msg: "invalid argument 0"}, errClassExecution},
+		{"nonce too low", nil, testRPCError{code: -32000, msg: "nonce too low: next nonce 5, tx nonce 4"}, errClassExecution},
+		{"method not found", nil, testRPCError{code: rpcCodeMethodNotFound, msg: "the method trace_block does not exist"}, errClassUnsupported},
+		{"missing trie node", nil, testRPCError{code: -32000, msg: "missing trie node 1a2b (path ) state is not available"}, errClassUnsupported},
+		{"internal error", nil, testRPCError{code: -32603, msg: "internal error"}, errClassTransport},
+		{"network error", nil, errors.New("dial tcp: connection refused"), errClassTransport},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			if got := classifyError(ctx, tt.err); got != tt.want {
+				t.Errorf("classifyError(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasRateLimitError(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want bool
+	}{
+		{"result", `{"jsonrpc":"2.0","id":1,"result":"0x1"}`, false},
+		{"-32005 rate limit", `{"jsonrpc":"2.0","id":1,"error":{"code":-32005,"message":"project ID request rate exceeded"}}`, true},
+		{"-32005 too many results", `{"jsonrpc":"2.0","id":1,"error":{"code":-32005,"message":"query returned more than 10000 results"}}`, false},
+		{"429 code", `{"jsonrpc":"2.0","id":1,"error":{"code":429,"message":"slow down"}}`, true},
+		{"rate limit message", `{"jsonrpc":"2.0","id":1,"error":{"code":-32000,"message":"rate limit reached"}}`, true},
+		{"execution error", `{"jsonrpc":"2.0","id":1,"error":{"code":3,"message":"execution reverted"}}`, false},
+		{"batch with one limited", `[{"jsonrpc":"2.0","id":1,"result":"0x1"},{"jsonrpc":"2.0","id":2,"error":{"code":-32005,"message":"limit exceeded"}}]`, true},
+		{"batch with log range only", `[{"jsonrpc":"2.0","id":1,"error":{"code":-32005,"message":"query returned more than 10000 results"}}]`, false},
+		{"not json", `<html>bad gateway</html>`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasRateLimitError([]byte(tt.body)); got != tt.want {
+				t.Errorf("hasRateLimitError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsNonceConflict(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("nonce too low"), true},
+		{errors.New("replacement transaction underpriced"), true},
+		{errors.New("already known"), false},
+		{errors.New("insufficient funds for gas * price + value"), false},
+	}
+	for _, tt := range tests {
+		if got := isNonceConflict(tt.err); got != tt.want {
+			t.Errorf("isNonceConflict(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
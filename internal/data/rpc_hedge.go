@@ -0,0 +1,269 @@
+package data
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+	"github.com/zy99978455-otw/go-micro-template/pkg/global"
+)
+
+const (
+	defaultHedgePercentile = 0.95
+	defaultHedgeMinDelay   = 10 * time.Millisecond
+	defaultHedgeMaxDelay   = time.Second
+	defaultHedgeBudget     = 10.0 // 对冲请求最多占 10%
+	hedgeWindowSize        = 128  // 每个方法保留的延迟样本数
+	hedgeMinSamples        = 16   // 样本不足时使用 maxDelay
+	hedgeMaxTokens         = 10.0 // 预算桶上限，允许短时间内集中对冲
+)
+
+// hedger 一条链的对冲策略
+// 1. 延迟：按方法统计最近的成功延迟，取分位数作为对冲延迟 (夹在 [minDelay, maxDelay])
+// 2. 预算：每个请求存入 budget% 个令牌，每次对冲消耗 1 个，保证对冲流量不超过预算比例
+type hedger struct {
+	percentile float64
+	minDelay   time.Duration
+	maxDelay   time.Duration
+	budget     float64
+	methods    map[string]bool // 为空表示所有读方法
+
+	mu      sync.Mutex
+	tokens  float64
+	windows map[string]*latencyWindow
+}
+
+// newHedger 未开启时返回 nil
+func newHedger(c config.HedgingConfig) *hedger {
+	if !c.Enable {
+		return nil
+	}
+	h := &hedger{
+		percentile: c.Percentile,
+		minDelay:   c.MinDelay,
+		maxDelay:   c.MaxDelay,
+		budget:     c.BudgetPercent,
+		methods:    make(map[string]bool, len(c.Methods)),
+		windows:    make(map[string]*latencyWindow),
+	}
+	if h.percentile <= 0 || h.percentile >= 1 {
+		h.percentile = defaultHedgePercentile
+	}
+	if h.minDelay <= 0 {
+		h.minDelay = defaultHedgeMinDelay
+	}
+	if h.maxDelay <= 0 {
+		h.maxDelay = defaultHedgeMaxDelay
+	}
+	if h.maxDelay < h.minDelay {
+		h.maxDelay = h.minDelay
+	}
+	if h.budget <= 0 {
+		h.budget = defaultHedgeBudget
+	}
+	for _, m := range c.Methods {
+		h.methods[m] = true
+	}
+	return h
+}
+
+// enabled 该方法是否允许对冲
+func (h *hedger) enabled(method string) bool {
+	return h != nil && (len(h.methods) == 0 || h.methods[method])
+}
+
+// delay 对冲延迟：该方法最近延迟的分位数
+func (h *hedger) delay(method string) time.Duration {
+	h.mu.Lock()
+	w := h.windows[method]
+	h.mu.Unlock()
+	if w == nil {
+		return h.maxDelay
+	}
+	d, ok := w.percentile(h.percentile)
+	if !ok {
+		return h.maxDelay
+	}
+	return min(max(d, h.minDelay), h.maxDelay)
+}
+
+// observe 记录一次成功调用的延迟
+func (h *hedger) observe(method string, d time.Duration) {
+	h.mu.Lock()
+	w := h.windows[method]
+	if w == nil {
+		w = &latencyWindow{}
+		h.windows[method] = w
+	}
+	h.mu.Unlock()
+	w.add(d)
+}
+
+// deposit 每个请求按预算比例存入令牌
+func (h *hedger) deposit() {
+	h.mu.Lock()
+	h.tokens = math.Min(h.tokens+h.budget/100, hedgeMaxTokens)
+	h.mu.Unlock()
+}
+
+// allow 预算允许时消耗一个令牌
+func (h *hedger) allow() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.tokens < 1 {
+		return false
+	}
+	h.tokens--
+	return true
+}
+
+// latencyWindow 固定大小的延迟环形缓冲
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples [hedgeWindowSize]time.Duration
+	next    int
+	count   int
+}
+
+func (w *latencyWindow) add(d time.Duration) {
+	w.mu.Lock()
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % hedgeWindowSize
+	w.count = min(w.count+1, hedgeWindowSize)
+	w.mu.Unlock()
+}
+
+func (w *latencyWindow) percentile(p float64) (time.Duration, bool) {
+	w.mu.Lock()
+	if w.count < hedgeMinSamples {
+		w.mu.Unlock()
+		return 0, false
+	}
+	sorted := slices.Clone(w.samples[:w.count])
+	w.mu.Unlock()
+
+	slices.Sort(sorted)
+	return sorted[int(p*float64(len(sorted)-1))], true
+}
+
+// ================= 对冲调用 =================
+
+// hedger 获取链的对冲策略 (未开启时为 nil)
+func (m *RPCManager) hedger(chainID int64) *hedger {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.hedgers[chainID]
+}
+
+// triedNodes 并发安全的已尝试节点集合 (主请求与对冲请求共享，避免打到同一个节点)
+type triedNodes struct {
+	mu    sync.Mutex
+	nodes map[*Node]bool
+}
+
+func newTriedNodes() *triedNodes {
+	return &triedNodes{nodes: make(map[*Node]bool)}
+}
+
+func (t *triedNodes) add(n *Node) {
+	t.mu.Lock()
+	t.nodes[n] = true
+	t.mu.Unlock()
+}
+
+func (t *triedNodes) snapshot() map[*Node]bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make(map[*Node]bool, len(t.nodes))
+	for n := range t.nodes {
+		out[n] = true
+	}
+	return out
+}
+
+// read 读请求入口：开启对冲时走 hedged，否则直接 call
+func read[T any](ctx context.Context, c *ChainClient, method string, fn func(ctx context.Context, cl *ethclient.Client) (T, error)) (T, error) {
+	h := c.mgr.hedger(c.chainID)
+	if !h.enabled(method) {
+		var out T
+		err := c.call(ctx, method, func(ctx context.Context, cl *ethclient.Client) (err error) {
+			out, err = fn(ctx, cl)
+			return err
+		})
+		return out, err
+	}
+	return hedged(ctx, c, h, method, fn)
+}
+
+// hedged 对冲调用
+// 1. 先向一个节点发请求
+// 2. 超过该方法的延迟分位数仍未返回，且预算允许时，向另一个节点再发一次
+// 3. 先成功的一方胜出，取消另一方；一方失败时等待另一方
+func hedged[T any](ctx context.Context, c *ChainClient, h *hedger, method string, fn func(ctx context.Context, cl *ethclient.Client) (T, error)) (T, error) {
+	type result struct {
+		value   T
+		err     error
+		elapsed time.Duration
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // 胜出后取消另一方
+
+	tried := newTriedNodes()
+	results := make(chan result, 2)
+	launch := func() {
+		go func() {
+			start := time.Now()
+			var out T
+			err := c.callWith(ctx, method, tried, func(ctx context.Context, cl *ethclient.Client) (err error) {
+				out, err = fn(ctx, cl)
+				return err
+			})
+			results <- result{value: out, err: err, elapsed: time.Since(start)}
+		}()
+	}
+
+	h.deposit()
+	launch()
+	pending := 1
+
+	timer := time.NewTimer(h.delay(method))
+	defer timer.Stop()
+
+	// 最终结果计入 rpc_calls_total
+	done := func(r result) (T, error) {
+		c.mgr.metrics.calls.WithLabelValues(chainLabel(c.chainID), method, classifyError(ctx, r.err).String()).Inc()
+		return r.value, r.err
+	}
+
+	for {
+		select {
+		case r := <-results:
+			pending--
+			switch classifyError(ctx, r.err) {
+			case errClassNone, errClassExecution:
+				h.observe(method, r.elapsed)
+				return done(r)
+			case errClassCanceled:
+				return done(r)
+			}
+			// 这一路失败了：另一路还在跑就等它
+			if pending == 0 {
+				return done(r)
+			}
+		case <-timer.C:
+			if pending == 1 && h.allow() {
+				if global.Log != nil {
+					global.Log.Debugf("🪁 [RPC] chain %d %s slow, hedging to another node", c.chainID, method)
+				}
+				c.mgr.metrics.hedges.WithLabelValues(chainLabel(c.chainID), method).Inc()
+				launch()
+				pending++
+			}
+		}
+	}
+}
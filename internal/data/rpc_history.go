@@ -0,0 +1,467 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+	"github.com/zy99978455-otw/go-micro-template/pkg/global"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultHistoryInterval   = time.Minute
+	defaultHistoryRetention  = 90 * 24 * time.Hour
+	defaultLatencyTargetMs   = 500
+	historyMaxSamples        = 2048 // 每个快照周期每个节点最多保留的延迟样本 (超过后蓄水池抽样)
+	historyPruneEvery        = 60   // 每 60 次快照清理一次过期数据
+	historyWriteTimeout      = 10 * time.Second
+	historyMigrateRetryDelay = 30 * time.Second
+)
+
+// RPCNodeSnapshot 节点健康快照 (表 rpc_node_snapshots)
+// 每个快照周期每个节点一行：快照时刻的状态 + 本周期内的检查、请求统计
+type RPCNodeSnapshot struct {
+	ID       uint64 `gorm:"primaryKey" json:"id"`
+	ChainID  int64  `gorm:"not null;index:idx_rpc_snapshot_node,priority:1" json:"chain_id"`
+	Node     string `gorm:"size:128;not null;index:idx_rpc_snapshot_node,priority:2" json:"node"`
+	Provider string `gorm:"size:128;not null;index:idx_rpc_snapshot_provider,priority:1" json:"provider"`
+
+	// 快照时刻的状态
+	Healthy           bool   `json:"healthy"`
+	Available         bool   `json:"available"` // 可以接收请求 (健康、未落后、未排空、未熔断、未限流)
+	BreakerState      string `gorm:"size:16" json:"breaker_state"`
+	BlockHeight       uint64 `json:"block_height"`
+	LagBlocks         uint64 `json:"lag_blocks"`
+	CheckLatencyMs    int64  `json:"check_latency_ms"`
+	ConsecutiveErrors int    `json:"consecutive_errors"`
+
+	// 本周期内的统计
+	Checks       int64   `json:"checks"`
+	FailedChecks int64   `json:"failed_checks"`
+	Requests     int64   `json:"requests"`
+	Errors       int64   `json:"errors"` // 计入节点故障的错误 (网络 / 节点内部错误)
+	RateLimited  int64   `json:"rate_limited"`
+	Succeeded    int64   `json:"succeeded"` // 有应答的请求 (成功或执行类错误)，延迟分位数只基于这些请求
+	LatencyP50Ms float64 `json:"latency_p50_ms"`
+	LatencyP95Ms float64 `json:"latency_p95_ms"`
+	LatencyP99Ms float64 `json:"latency_p99_ms"`
+
+	IntervalSeconds int       `json:"interval_seconds"`
+	CreatedAt       time.Time `gorm:"not null;index:idx_rpc_snapshot_node,priority:3;index:idx_rpc_snapshot_provider,priority:2" json:"created_at"`
+}
+
+func (RPCNodeSnapshot) TableName() string {
+	return "rpc_node_snapshots"
+}
+
+// ================= 节点周期统计 =================
+
+// nodeWindow 两次快照之间的检查与请求统计 (快照时取走并清零，写入失败时放回)
+type nodeWindow struct {
+	mu           sync.Mutex
+	checks       int64
+	failedChecks int64
+	requests     int64
+	errors       int64
+	rateLimited  int64
+	succeeded    int64 // 有应答的请求数，即参与延迟抽样的总体大小
+	samples      []time.Duration
+}
+
+// windowStats 一个周期的统计结果
+type windowStats struct {
+	checks, failedChecks                     int64
+	requests, errors, rateLimited, succeeded int64
+	samples                                  []time.Duration // 已排序
+}
+
+// quantile 延迟分位数 (没有样本时为 0)
+func (st windowStats) quantile(p float64) time.Duration {
+	if len(st.samples) == 0 {
+		return 0
+	}
+	return st.samples[int(p*float64(len(st.samples)-1))]
+}
+
+func (w *nodeWindow) check(ok bool) {
+	w.mu.Lock()
+	w.checks++
+	if !ok {
+		w.failedChecks++
+	}
+	w.mu.Unlock()
+}
+
+// observe 记录一次请求；调用方取消的请求不计入
+func (w *nodeWindow) observe(class errClass, elapsed time.Duration) {
+	if class == errClassCanceled {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.requests++
+	switch class {
+	case errClassTransport:
+		w.errors++
+	case errClassRateLimited:
+		w.rateLimited++
+	}
+	if class != errClassNone && class != errClassExecution {
+		return
+	}
+	// 蓄水池抽样：总体只包括有应答的请求 (失败 / 限流没有延迟样本)，每个样本被保留的概率相同
+	w.succeeded++
+	if len(w.samples) < historyMaxSamples {
+		w.samples = append(w.samples, elapsed)
+	} else if i := rand.Int64N(w.succeeded); i < historyMaxSamples {
+		w.samples[i] = elapsed
+	}
+}
+
+// take 取走本周期的统计并清零
+func (w *nodeWindow) take() windowStats {
+	w.mu.Lock()
+	st := windowStats{
+		checks:       w.checks,
+		failedChecks: w.failedChecks,
+		requests:     w.requests,
+		errors:       w.errors,
+		rateLimited:  w.rateLimited,
+		succeeded:    w.succeeded,
+		samples:      w.samples,
+	}
+	w.checks, w.failedChecks, w.requests, w.errors, w.rateLimited, w.succeeded = 0, 0, 0, 0, 0, 0
+	w.samples = nil
+	w.mu.Unlock()
+
+	slices.Sort(st.samples)
+	return st
+}
+
+// restore 快照没有写入时把取走的统计放回，并入下一个周期
+// 合并后样本超过上限时随机保留 historyMaxSamples 个
+func (w *nodeWindow) restore(st windowStats) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.checks += st.checks
+	w.failedChecks += st.failedChecks
+	w.requests += st.requests
+	w.errors += st.errors
+	w.rateLimited += st.rateLimited
+	w.succeeded += st.succeeded
+	w.samples = append(w.samples, st.samples...)
+	if len(w.samples) > historyMaxSamples {
+		rand.Shuffle(len(w.samples), func(i, j int) { w.samples[i], w.samples[j] = w.samples[j], w.samples[i] })
+		w.samples = w.samples[:historyMaxSamples]
+	}
+}
+
+// ================= 快照写入 =================
+
+// NodeHistory 定期把节点健康快照写入 MySQL，并提供按服务商的 SLO 统计
+type NodeHistory struct {
+	db        *gorm.DB
+	mgr       *RPCManager
+	interval  time.Duration
+	retention time.Duration
+
+	since time.Time // 上一次成功写入的时间 (只在 loop 中访问)
+
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	startOnce sync.Once
+	closeOnce sync.Once
+}
+
+// NewNodeHistory MySQL 未配置或已关闭时返回 nil (所有方法对 nil 安全)
+func NewNodeHistory(cfg *config.AppConfig, db *gorm.DB, mgr *RPCManager) *NodeHistory {
+	if db == nil || mgr == nil || cfg == nil || cfg.NodeHistory.Disable {
+		return nil
+	}
+	h := &NodeHistory{
+		db:        db,
+		mgr:       mgr,
+		interval:  cfg.NodeHistory.Interval,
+		retention: cfg.NodeHistory.Retention,
+	}
+	if h.interval <= 0 {
+		h.interval = defaultHistoryInterval
+	}
+	if h.retention <= 0 {
+		h.retention = defaultHistoryRetention
+	}
+	return h
+}
+
+// Start 建表 (AutoMigrate) 后按间隔写快照，ctx 取消或调用 Close 时停止
+func (h *NodeHistory) Start(ctx context.Context) {
+	if h == nil {
+		return
+	}
+	h.startOnce.Do(func() {
+		ctx, h.cancel = context.WithCancel(ctx)
+		h.wg.Add(1)
+		go h.loop(ctx)
+		if global.Log != nil {
+			global.Log.Infof("🗃️ [RPC] Node history enabled (interval=%s, retention=%s)", h.interval, h.retention)
+		}
+	})
+}
+
+// Close 停止写入 (等待进行中的写入完成)
+func (h *NodeHistory) Close() {
+	if h == nil {
+		return
+	}
+	h.closeOnce.Do(func() {
+		if h.cancel != nil {
+			h.cancel()
+		}
+		h.wg.Wait()
+	})
+}
+
+func (h *NodeHistory) loop(ctx context.Context) {
+	defer h.wg.Done()
+
+	// 1. 建表：失败时稍后重试 (MySQL 可能比服务晚启动)
+	for {
+		err := h.db.WithContext(ctx).AutoMigrate(&RPCNodeSnapshot{})
+		if err == nil {
+			break
+		}
+		if global.Log != nil {
+			global.Log.Warnf("⚠️ [RPC] Node history migrate failed, retrying in %s: %v", historyMigrateRetryDelay, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(historyMigrateRetryDelay):
+		}
+	}
+
+	// 2. 定期写快照，顺带清理过期数据
+	h.since = time.Now()
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for tick := 1; ; tick++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := h.snapshot(ctx); err != nil && ctx.Err() == nil && global.Log != nil {
+			global.Log.Warnf("⚠️ [RPC] Node history snapshot failed: %v", err)
+		}
+		if tick%historyPruneEvery == 0 {
+			h.prune(ctx)
+		}
+	}
+}
+
+// snapshot 为所有节点写一行快照
+// 写入失败时周期统计放回各节点，由下一次快照一起写入 (interval_seconds 覆盖合并后的时长)
+func (h *NodeHistory) snapshot(ctx context.Context) error {
+	now := time.Now()
+	interval := h.interval
+	if !h.since.IsZero() {
+		interval = now.Sub(h.since).Round(time.Second)
+	}
+	var (
+		rows  []RPCNodeSnapshot
+		taken []*Node
+		stats []windowStats
+	)
+	for chainID, nodes := range h.mgr.allNodes() {
+		// 与落后检测同一基准：隔离、未校验的节点的高度不参与
+		best := comparableBest(nodes)
+		for _, n := range nodes {
+			st := n.Status()
+			w := n.window.take()
+			taken, stats = append(taken, n), append(stats, w)
+			rows = append(rows, RPCNodeSnapshot{
+				ChainID:           chainID,
+				Node:              st.Name,
+				Provider:          st.Provider,
+				Healthy:           st.Healthy,
+				Available:         n.available(),
+				BreakerState:      st.BreakerState.String(),
+				BlockHeight:       st.BlockHeight,
+				LagBlocks:         best - min(best, st.BlockHeight),
+				CheckLatencyMs:    st.LatencyMs,
+				ConsecutiveErrors: st.ErrorCount,
+				Checks:            w.checks,
+				FailedChecks:      w.failedChecks,
+				Requests:          w.requests,
+				Errors:            w.errors,
+				RateLimited:       w.rateLimited,
+				Succeeded:         w.succeeded,
+				LatencyP50Ms:      durationMs(w.quantile(0.50)),
+				LatencyP95Ms:      durationMs(w.quantile(0.95)),
+				LatencyP99Ms:      durationMs(w.quantile(0.99)),
+				IntervalSeconds:   int(interval / time.Second),
+				CreatedAt:         now,
+			})
+		}
+	}
+	if len(rows) == 0 {
+		h.since = now
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, historyWriteTimeout)
+	defer cancel()
+	if err := h.db.WithContext(ctx).CreateInBatches(rows, 200).Error; err != nil {
+		for i, n := range taken {
+			n.window.restore(stats[i])
+		}
+		return err
+	}
+	h.since = now
+	return nil
+}
+
+// prune 删除超过保留时长的快照
+func (h *NodeHistory) prune(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, historyWriteTimeout)
+	defer cancel()
+	res := h.db.WithContext(ctx).Where("created_at < ?", time.Now().Add(-h.retention)).Delete(&RPCNodeSnapshot{})
+	if res.Error != nil {
+		if global.Log != nil {
+			global.Log.Warnf("⚠️ [RPC] Node history prune failed: %v", res.Error)
+		}
+		return
+	}
+	if res.RowsAffected > 0 && global.Log != nil {
+		global.Log.Infof("🧹 [RPC] Node history pruned %d snapshots older than %s", res.RowsAffected, h.retention)
+	}
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// ================= SLO 统计 =================
+
+// SLOQuery SLO 统计条件
+type SLOQuery struct {
+	From            time.Time
+	To              time.Time
+	ChainID         int64   // 0 表示所有链
+	LatencyTargetMs float64 // p95 延迟目标 (默认 500ms)
+}
+
+// ProviderSLO 一个服务商在时间范围内的可用率与延迟统计
+type ProviderSLO struct {
+	Provider  string `json:"provider"`
+	Nodes     int64  `json:"nodes"`
+	Snapshots int64  `json:"snapshots"`
+
+	UptimePercent    float64 `json:"uptime_percent"`    // 健康检查成功率
+	AvailablePercent float64 `json:"available_percent"` // 快照时刻可接收请求的比例
+
+	Requests         int64   `json:"requests"`
+	Errors           int64   `json:"errors"`
+	RateLimited      int64   `json:"rate_limited"`
+	ErrorRatePercent float64 `json:"error_rate_percent"`
+
+	// 各周期延迟分位数按有应答请求数加权的平均值 (不是整个时间范围上的真实分位数)
+	AvgP50Ms float64 `json:"avg_p50_ms"`
+	AvgP95Ms float64 `json:"avg_p95_ms"`
+	AvgP99Ms float64 `json:"avg_p99_ms"`
+	// 有延迟样本的周期中 p95 不超过目标的比例 (请求全部失败的周期没有 p95，不计入)
+	LatencyTargetMs   float64 `json:"latency_target_ms"`
+	LatencySLOPercent float64 `json:"latency_slo_percent"`
+
+	AvgLagBlocks float64 `json:"avg_lag_blocks"`
+}
+
+// ProviderSLO 按服务商汇总 [From, To) 内的快照
+func (h *NodeHistory) ProviderSLO(ctx context.Context, q SLOQuery) ([]ProviderSLO, error) {
+	if h == nil {
+		return nil, fmt.Errorf("node history is disabled (mysql not configured)")
+	}
+	if q.LatencyTargetMs <= 0 {
+		q.LatencyTargetMs = defaultLatencyTargetMs
+	}
+
+	var rows []struct {
+		Provider           string
+		Nodes              int64
+		Snapshots          int64
+		Checks             int64
+		FailedChecks       int64
+		AvailableSnapshots int64
+		Requests           int64
+		Errors             int64
+		RateLimited        int64
+		Succeeded          int64
+		P50Weighted        float64
+		P95Weighted        float64
+		P99Weighted        float64
+		ActiveSnapshots    int64
+		WithinTarget       int64
+		AvgLag             float64
+	}
+	db := h.db.WithContext(ctx).Model(&RPCNodeSnapshot{}).
+		Select(`provider,
+			COUNT(DISTINCT chain_id, node) AS nodes,
+			COUNT(*) AS snapshots,
+			COALESCE(SUM(checks), 0) AS checks,
+			COALESCE(SUM(failed_checks), 0) AS failed_checks,
+			COALESCE(SUM(CASE WHEN available THEN 1 ELSE 0 END), 0) AS available_snapshots,
+			COALESCE(SUM(requests), 0) AS requests,
+			COALESCE(SUM(errors), 0) AS errors,
+			COALESCE(SUM(rate_limited), 0) AS rate_limited,
+			COALESCE(SUM(succeeded), 0) AS succeeded,
+			COALESCE(SUM(latency_p50_ms * succeeded), 0) AS p50_weighted,
+			COALESCE(SUM(latency_p95_ms * succeeded), 0) AS p95_weighted,
+			COALESCE(SUM(latency_p99_ms * succeeded), 0) AS p99_weighted,
+			COALESCE(SUM(CASE WHEN succeeded > 0 THEN 1 ELSE 0 END), 0) AS active_snapshots,
+			COALESCE(SUM(CASE WHEN succeeded > 0 AND latency_p95_ms <= ? THEN 1 ELSE 0 END), 0) AS within_target,
+			COALESCE(AVG(lag_blocks), 0) AS avg_lag`, q.LatencyTargetMs).
+		Where("created_at >= ? AND created_at < ?", q.From, q.To)
+	if q.ChainID != 0 {
+		db = db.Where("chain_id = ?", q.ChainID)
+	}
+	if err := db.Group("provider").Order("provider").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	out := make([]ProviderSLO, 0, len(rows))
+	for _, r := range rows {
+		slo := ProviderSLO{
+			Provider:          r.Provider,
+			Nodes:             r.Nodes,
+			Snapshots:         r.Snapshots,
+			UptimePercent:     percent(r.Checks-r.FailedChecks, r.Checks),
+			AvailablePercent:  percent(r.AvailableSnapshots, r.Snapshots),
+			Requests:          r.Requests,
+			Errors:            r.Errors,
+			RateLimited:       r.RateLimited,
+			ErrorRatePercent:  percent(r.Errors, r.Requests),
+			LatencyTargetMs:   q.LatencyTargetMs,
+			LatencySLOPercent: percent(r.WithinTarget, r.ActiveSnapshots),
+			AvgLagBlocks:      r.AvgLag,
+		}
+		if r.Succeeded > 0 {
+			slo.AvgP50Ms = r.P50Weighted / float64(r.Succeeded)
+			slo.AvgP95Ms = r.P95Weighted / float64(r.Succeeded)
+			slo.AvgP99Ms = r.P99Weighted / float64(r.Succeeded)
+		}
+		out = append(out, slo)
+	}
+	return out, nil
+}
+
+func percent(part, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(total)
+}
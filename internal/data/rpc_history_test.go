@@ -0,0 +1,61 @@
+package data
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestSnapshotKeepsWindowOnWriteFailure(t *testing.T) {
+	srv := newTestRPCServer(t, 1, nil)
+	mgr := startTestManager(t, config.ChainConfig{ChainID: 1, Nodes: []config.NodeConfig{{RpcUrl: srv.URL}}})
+
+	// 连不上的 MySQL：写快照必然失败
+	db, err := gorm.Open(mysql.New(mysql.Config{DSN: "u:p@tcp(127.0.0.1:1)/db?timeout=1s", SkipInitializeWithVersion: true}),
+		&gorm.Config{DisableAutomaticPing: true, Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("gorm.Open() error: %v", err)
+	}
+	h := NewNodeHistory(&config.AppConfig{}, db, mgr)
+
+	n := mgr.chainNodes[1][0]
+	n.window.take() // 丢掉启动阶段的检查和请求
+	n.window.observe(errClassNone, 10*time.Millisecond)
+	n.window.observe(errClassTransport, time.Second)
+
+	if err := h.snapshot(context.Background()); err == nil {
+		t.Fatal("snapshot() succeeded against an unreachable database")
+	}
+	n.window.observe(errClassNone, 30*time.Millisecond)
+
+	st := n.window.take()
+	if st.requests != 3 || st.errors != 1 || st.succeeded != 2 || len(st.samples) != 2 {
+		t.Fatalf("window after failed write: requests=%d errors=%d succeeded=%d samples=%d, want 3/1/2/2",
+			st.requests, st.errors, st.succeeded, len(st.samples))
+	}
+	if lo, hi := st.quantile(0), st.quantile(1); lo != 10*time.Millisecond || hi != 30*time.Millisecond {
+		t.Errorf("latency range = [%s, %s], want [10ms, 30ms]", lo, hi)
+	}
+}
+
+func TestWindowRestoreCapsSamples(t *testing.T) {
+	var w nodeWindow
+	for range historyMaxSamples {
+		w.observe(errClassNone, time.Millisecond)
+	}
+	st := w.take()
+	for range historyMaxSamples {
+		w.observe(errClassNone, time.Second)
+	}
+	w.restore(st)
+
+	st = w.take()
+	if st.succeeded != 2*historyMaxSamples || len(st.samples) != historyMaxSamples {
+		t.Fatalf("succeeded=%d samples=%d, want %d/%d", st.succeeded, len(st.samples), 2*historyMaxSamples, historyMaxSamples)
+	}
+}
@@ -0,0 +1,87 @@
+package data
+
+import (
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+	"github.com/zy99978455-otw/go-micro-template/pkg/global"
+)
+
+const (
+	defaultMaxBlockLag    = 10 // 默认最多允许落后 10 个块
+	defaultStallIntervals = 3  // 默认连续 3 次检查不出块视为卡住
+)
+
+// lagPolicy 落后检测阈值
+type lagPolicy struct {
+	maxLag         uint64
+	stallIntervals int
+}
+
+func newLagPolicy(chainConf config.ChainConfig) lagPolicy {
+	p := lagPolicy{
+		maxLag:         chainConf.MaxBlockLag,
+		stallIntervals: chainConf.StallIntervals,
+	}
+	if p.maxLag == 0 {
+		p.maxLag = defaultMaxBlockLag
+	}
+	if p.stallIntervals <= 0 {
+		p.stallIntervals = defaultStallIntervals
+	}
+	return p
+}
+
+// evaluateLag 横向比较同一条链上的节点高度
+// 1. 以健康节点中观测到的最高高度为基准 (被隔离、未通过链校验或还没有高度的节点不参与比较)
+// 2. 落后超过 maxLag，或高度连续 stallIntervals 次不增长且落后于基准的节点被降级
+// 3. 已降级的节点追上后自动恢复
+func (m *RPCManager) evaluateLag(chainID int64, nodes []*Node) {
+	m.mu.RLock()
+	policy := newLagPolicy(m.chains[chainID])
+	m.mu.RUnlock()
+
+	best := comparableBest(nodes)
+	for _, n := range nodes {
+		n.mu.Lock()
+		if !n.comparableLocked() {
+			n.mu.Unlock()
+			continue
+		}
+		lag := best - n.BlockHeight
+		stalled := n.stallCount >= policy.stallIntervals && lag > 0
+		lagging := lag > policy.maxLag || stalled
+		changed := lagging != n.Lagging
+		n.Lagging = lagging
+		height, stallCount := n.BlockHeight, n.stallCount
+		n.mu.Unlock()
+
+		if !changed || global.Log == nil {
+			continue
+		}
+		if lagging {
+			global.Log.Warnf("🐢 [RPC] Node lagging, demoted: %s (chain %d), height=%d best=%d lag=%d stall=%d",
+				n.Name, chainID, height, best, lag, stallCount)
+		} else {
+			global.Log.Infof("✅ [RPC] Node caught up, restored: %s (chain %d), height=%d best=%d",
+				n.Name, chainID, height, best)
+		}
+	}
+}
+
+// comparableBest 落后比较的基准：可比较节点中的最高高度 (落后检测、指标和健康历史共用)
+func comparableBest(nodes []*Node) uint64 {
+	var best uint64
+	for _, n := range nodes {
+		n.mu.RLock()
+		if n.comparableLocked() && n.BlockHeight > best {
+			best = n.BlockHeight
+		}
+		n.mu.RUnlock()
+	}
+	return best
+}
+
+// comparableLocked 节点高度可以参与落后比较：健康、已通过链校验、未被隔离且已有高度 (调用方持有 n.mu)
+// 隔离节点可能连的是别的链，高度没有可比性
+func (n *Node) comparableLocked() bool {
+	return n.IsHealthy && n.verified && !n.Quarantined && n.BlockHeight > 0
+}
@@ -3,32 +3,76 @@ package data
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/redis/go-redis/v9"
 	"github.com/zy99978455-otw/go-micro-template/pkg/config" // 引入 config 包
 	"github.com/zy99978455-otw/go-micro-template/pkg/global" // 仅用于日志
 )
 
 // Node 代表一个具体的 RPC 节点
 type Node struct {
-	URL         string
-	ChainID     int64
-	Client      *ethclient.Client
-	
+	Name    string
+	URL     string
+	WssURL  string
+	ChainID int64
+	Client  *ethclient.Client
+
+	Weight   int               // 权重 (负载均衡用)
+	Priority string            // primary / backup
+	Labels   map[string]string // 自定义标签
+	Provider string            // 服务商 (labels.provider，未配置时取 URL 的域名)，仲裁读按它区分独立来源
+
+	transport *batchTransport // HTTP 层的批量/合并器 (重连时复用)
+	quota     *nodeQuota      // 限流与额度 (非 HTTP 节点为 nil)
+
+	caps         Capabilities              // 节点能力 (声明 + 自动探测 + 失败学习)
+	capsDeclared config.CapabilitiesConfig // 配置声明的能力 (不会被探测结果覆盖)
+
 	IsHealthy   bool
 	Latency     time.Duration
 	BlockHeight uint64
 	ErrorCount  int
-	
-	mu          sync.RWMutex
+	Lagging     bool // 落后于链上最高高度 (或长时间不出块)，被降级
+
+	Draining bool // 排空中：不再接收新请求，进行中的请求继续完成 (管理接口设置)
+	removed  bool // 已从管理器移除：健康检查不再重连
+
+	Quarantined      bool // eth_chainId / genesis 与配置不符，被隔离
+	QuarantineReason string
+	verified         bool // 当前连接是否已通过链校验 (重连后需重新校验)
+
+	breaker     *circuitBreaker // 熔断器 (健康检查 + 真实请求共同驱动)
+	stallCount  int             // 高度连续未增长的检查次数
+	ewmaLatency float64         // 延迟的指数加权平均 (纳秒)，健康检查和真实请求都会更新
+	inFlight    atomic.Int64    // 正在执行的请求数
+	window      nodeWindow      // 两次历史快照之间的检查与请求统计 (见 rpc_history.go)
+
+	mu sync.RWMutex
 }
 
 // RPCManager 管理多链的所有节点
 type RPCManager struct {
 	chainNodes map[int64][]*Node
-	mu sync.RWMutex
+	balancers  map[int64]Balancer // 每条链独立的节点选择策略
+	chains     map[int64]config.ChainConfig
+	health     map[int64]healthPolicy // 每条链独立的健康检查策略
+	hedgers    map[int64]*hedger      // 每条链的对冲策略 (未开启为 nil)
+	metrics    *rpcMetrics            // Prometheus 指标 (RPCManager 本身实现 prometheus.Collector)
+	quotaRedis *redis.Client          // CU 计数持久化 (见 PersistQuota，nil 表示只在内存中计数)
+	nodeHooks  []func(chainID int64)  // 运行时增删节点后的回调 (见 OnNodesChanged)
+	mu         sync.RWMutex
+
+	// 生命周期：Start 启动后台健康检查，Close 停止并释放连接
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup // 跟踪所有后台 goroutine (包括进行中的健康检查)
+	startOnce sync.Once
+	closeOnce sync.Once
 }
 
 // ================= 2. 初始化逻辑 =================
@@ -37,155 +81,648 @@ type RPCManager struct {
 func NewRPCManager(cfg *config.AppConfig) *RPCManager {
 	mgr := &RPCManager{
 		chainNodes: make(map[int64][]*Node),
+		balancers:  make(map[int64]Balancer),
+		chains:     make(map[int64]config.ChainConfig),
+		health:     make(map[int64]healthPolicy),
+		hedgers:    make(map[int64]*hedger),
+		metrics:    newRPCMetrics(),
 	}
 
 	// 1. 遍历配置，初始化连接
 	// 🔥 使用传入的 cfg，不再使用 global.AppConfig
 	if cfg != nil && len(cfg.Chains) > 0 {
 		for _, chainConf := range cfg.Chains {
-			
-			// 尝试初始连接
-			client, err := ethclient.Dial(chainConf.RpcUrl)
-			isHealthy := false
-			if err == nil {
-				isHealthy = true 
-			} else {
+			// 节点选择策略：配置错误时退回默认策略，不影响启动
+			balancer, err := NewBalancer(chainConf.Balancer)
+			if err != nil {
 				if global.Log != nil {
-					global.Log.Warnf("⚠️ [RPC] Init failed for chain %d (%s): %v", chainConf.ChainID, chainConf.RpcUrl, err)
-				} else {
-					fmt.Printf("⚠️ [RPC] Init failed for chain %d (%s): %v\n", chainConf.ChainID, chainConf.RpcUrl, err)
+					global.Log.Warnf("⚠️ [RPC] chain %d: %v, fallback to %s", chainConf.ChainID, err, defaultBalancerName)
 				}
+				balancer, _ = NewBalancer(defaultBalancerName)
 			}
+			mgr.balancers[chainConf.ChainID] = balancer
+			mgr.chains[chainConf.ChainID] = chainConf
 
-			node := &Node{
-				URL:       chainConf.RpcUrl,
-				ChainID:   chainConf.ChainID,
-				Client:    client,
-				IsHealthy: isHealthy,
+			// 健康检查策略：配置错误时退回默认探针
+			policy, err := newHealthPolicy(chainConf.HealthCheck)
+			if err != nil {
+				if global.Log != nil {
+					global.Log.Warnf("⚠️ [RPC] chain %d: %v, fallback to %s", chainConf.ChainID, err, ProbeBlockNumber)
+				}
+				policy.probe = ProbeBlockNumber
 			}
+			mgr.health[chainConf.ChainID] = policy
+			mgr.hedgers[chainConf.ChainID] = newHedger(chainConf.Hedging)
+
+			// 每条链可以配置多个节点 (主备 + 权重)
+			for _, nodeConf := range chainConf.Endpoints() {
+				node := newNode(chainConf.ChainID, nodeConf, newBatchPolicy(chainConf.Batch, nodeConf))
+				node.breaker = newCircuitBreaker(node.Name, newBreakerPolicy(chainConf.CircuitBreaker))
+				mgr.chainNodes[chainConf.ChainID] = append(mgr.chainNodes[chainConf.ChainID], node)
 
-			mgr.chainNodes[chainConf.ChainID] = append(mgr.chainNodes[chainConf.ChainID], node)
-            
-            if global.Log != nil {
-                global.Log.Infof("✅ [RPC] Added node for chain %d: %s", chainConf.ChainID, chainConf.RpcUrl)
-            }
+				if global.Log != nil {
+					global.Log.Infof("✅ [RPC] Added node for chain %d: %s (%s, weight=%d)", chainConf.ChainID, node.Name, node.Priority, node.Weight)
+				}
+			}
 		}
 	}
 
-	// 2. 启动后台健康检查
-	go mgr.startHealthCheckLoop()
-
+	// 2. 后台健康检查由 Start 启动，这里只负责初始化
 	return mgr
 }
 
+// Start 启动后台健康检查 (每条链一个循环)，ctx 取消或调用 Close 时停止 (多次调用只生效一次)
+func (m *RPCManager) Start(ctx context.Context) {
+	m.startOnce.Do(func() {
+		ctx, cancel := context.WithCancel(ctx)
+		m.mu.Lock()
+		m.cancel = cancel
+		chainIDs := make([]int64, 0, len(m.chainNodes))
+		for chainID := range m.chainNodes {
+			chainIDs = append(chainIDs, chainID)
+		}
+		m.mu.Unlock()
+
+		// 0. 从 Redis 恢复 CU 用量 (重启前的计数)，之后定期同步
+		if m.quotaRedis != nil {
+			m.syncQuotas(ctx)
+			m.wg.Add(1)
+			go func() {
+				defer m.wg.Done()
+				m.quotaSyncLoop(ctx)
+			}()
+		}
+
+		// 1. 启动时同步做一轮检查，保证 Start 返回后节点都已通过链校验
+		//    关闭了 initial_probe 的链只做校验，不做完整探测
+		var boot sync.WaitGroup
+		for _, chainID := range chainIDs {
+			boot.Add(1)
+			go func(chainID int64) {
+				defer boot.Done()
+				m.bootCheck(ctx, chainID)
+			}(chainID)
+		}
+		boot.Wait()
+
+		// 2. 每条链一个后台循环
+		for _, chainID := range chainIDs {
+			m.wg.Add(1)
+			go func(chainID int64) {
+				defer m.wg.Done()
+				m.startHealthCheckLoop(ctx, chainID)
+			}(chainID)
+		}
+	})
+}
+
+// Close 停止后台任务，等待进行中的健康检查结束，然后关闭所有节点连接
+func (m *RPCManager) Close() {
+	m.closeOnce.Do(func() {
+		// 未 Start 也可以 Close，之后的 Start 不再生效
+		m.startOnce.Do(func() {})
+
+		m.mu.RLock()
+		cancel := m.cancel
+		m.mu.RUnlock()
+		if cancel != nil {
+			cancel()
+		}
+		m.wg.Wait()
+
+		m.mu.RLock()
+		defer m.mu.RUnlock()
+		for _, nodes := range m.chainNodes {
+			for _, n := range nodes {
+				n.mu.Lock()
+				if n.Client != nil {
+					n.Client.Close()
+					n.Client = nil
+				}
+				n.IsHealthy = false
+				n.mu.Unlock()
+			}
+		}
+		if global.Log != nil {
+			global.Log.Info("✅ [RPC] RPCManager closed")
+		}
+	})
+}
+
+// newNode 根据节点配置创建 Node，并尝试初始连接
+func newNode(chainID int64, nodeConf config.NodeConfig, batch batchPolicy) *Node {
+	url := nodeConf.ResolvedRpcUrl()
+	var quota *nodeQuota
+	if isHTTPURL(url) {
+		quota = newNodeQuota(nodeConf.RateLimit)
+	}
+	transport := newBatchTransport(nodeConf.Name, batch, quota)
+
+	// 尝试初始连接
+	client, err := dialNode(context.Background(), url, transport)
+	isHealthy := false
+	if err == nil {
+		isHealthy = true
+	} else {
+		if global.Log != nil {
+			global.Log.Warnf("⚠️ [RPC] Init failed for chain %d (%s): %v", chainID, nodeConf.Name, err)
+		} else {
+			fmt.Printf("⚠️ [RPC] Init failed for chain %d (%s): %v\n", chainID, nodeConf.Name, err)
+		}
+	}
+
+	return &Node{
+		Name:      nodeConf.Name,
+		URL:       url,
+		WssURL:    nodeConf.ResolvedWssUrl(),
+		ChainID:   chainID,
+		Client:    client,
+		Weight:    nodeConf.Weight,
+		Priority:  nodeConf.Priority,
+		Labels:    nodeConf.Labels,
+		Provider:  nodeProvider(nodeConf.Labels, url),
+		IsHealthy: isHealthy,
+		transport: transport,
+		quota:     quota,
+
+		caps:         newCapabilities(nodeConf.Capabilities, nodeConf.WssUrl),
+		capsDeclared: nodeConf.Capabilities,
+	}
+}
+
+// dialNode 建立节点连接；HTTP 端点挂上批量/合并器，其他协议直接拨号
+func dialNode(ctx context.Context, url string, transport *batchTransport) (*ethclient.Client, error) {
+	if !isHTTPURL(url) || transport == nil {
+		return ethclient.DialContext(ctx, url)
+	}
+	rc, err := rpc.DialOptions(ctx, url, rpc.WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(rc), nil
+}
+
 // ================= 3. 健康检查核心逻辑 =================
 
-func (m *RPCManager) startHealthCheckLoop() {
-	// 每 30 秒检查一次
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
+func (m *RPCManager) startHealthCheckLoop(ctx context.Context, chainID int64) {
+	m.mu.RLock()
+	policy := m.health[chainID]
+	m.mu.RUnlock()
+
+	// 每个周期的间隔 = interval + 随机抖动
+	timer := time.NewTimer(policy.nextDelay())
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+			m.checkChain(ctx, chainID)
+			timer.Reset(policy.nextDelay())
+		}
+	}
+}
+
+// bootCheck 启动时的首轮检查：立即探测一次，不用等第一个周期
+func (m *RPCManager) bootCheck(ctx context.Context, chainID int64) {
+	m.mu.RLock()
+	policy := m.health[chainID]
+	nodes := append([]*Node(nil), m.chainNodes[chainID]...)
+	m.mu.RUnlock()
 
-	for range ticker.C {
-		m.checkAllNodes()
+	if policy.initialProbe {
+		m.checkChain(ctx, chainID)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(n *Node) {
+			defer wg.Done()
+			if client := n.client(); client != nil {
+				vctx, cancel := context.WithTimeout(withProbe(ctx), policy.timeout)
+				defer cancel()
+				if m.applyVerification(n, verifyNode(vctx, n, client, m.genesisHash(chainID))) {
+					m.detectCapabilities(vctx, n, client)
+				}
+			}
+		}(node)
 	}
+	wg.Wait()
 }
 
-func (m *RPCManager) checkAllNodes() {
+// checkChain 并发检查一条链的所有节点，然后横向比较高度
+func (m *RPCManager) checkChain(ctx context.Context, chainID int64) {
 	m.mu.RLock()
 	// 复制一份节点列表，避免在检查时长时间持有锁
-	// 这里其实可以直接遍历，因为 map 只有初始化时才写，后面基本只读。
-	// 但为了严谨，我们还是在锁内只做简单的遍历。
-	
-    var allNodes []*Node
-    for _, nodes := range m.chainNodes {
-        allNodes = append(allNodes, nodes...)
-    }
+	nodes := append([]*Node(nil), m.chainNodes[chainID]...)
+	policy := m.health[chainID]
 	m.mu.RUnlock()
 
 	var wg sync.WaitGroup
-	for _, node := range allNodes {
+	for _, node := range nodes {
 		wg.Add(1)
 		go func(n *Node) {
 			defer wg.Done()
-			m.checkOneNode(n)
+			m.checkOneNode(ctx, n, policy)
 		}(node)
 	}
 	wg.Wait()
+
+	// 关闭过程中被取消的检查结果不可信，不做横向比较
+	if ctx.Err() != nil {
+		return
+	}
+
+	// 所有节点都拿到最新高度后，再横向比较
+	m.evaluateLag(chainID, nodes)
 }
 
-func (m *RPCManager) checkOneNode(n *Node) {
-	// 设置 5 秒超时
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (m *RPCManager) checkOneNode(parent context.Context, n *Node, policy healthPolicy) {
+	// 超时时间按链配置 (默认 5 秒)；健康检查不占用限流令牌和 CU 额度
+	ctx, cancel := context.WithTimeout(withProbe(parent), policy.timeout)
 	defer cancel()
 
 	start := time.Now()
-	
+
 	// 如果 client 为空（初始化失败），尝试重连
-	if n.Client == nil {
-		client, err := ethclient.Dial(n.URL)
+	n.mu.RLock()
+	client := n.Client
+	// 首次连接、重连、从不健康恢复时都要重新校验链 ID
+	needVerify := !n.verified || !n.IsHealthy
+	n.mu.RUnlock()
+	if client == nil {
+		var err error
+		client, err = dialNode(ctx, n.URL, n.transport)
 		if err != nil {
-			m.markUnhealthy(n, err)
+			m.probeFailed(n, err)
+			return
+		}
+		n.mu.Lock()
+		if n.removed {
+			// 重连期间节点被移除
+			n.mu.Unlock()
+			client.Close()
 			return
 		}
 		n.Client = client
+		n.verified = false
+		n.mu.Unlock()
+		needVerify = true
 	}
 
-	// 核心检查：获取区块高度
-	// BlockNumber 返回的是 uint64
-	height, err := n.Client.BlockNumber(ctx)
+	if needVerify {
+		err := verifyNode(ctx, n, client, m.genesisHash(n.ChainID))
+		if parent.Err() != nil {
+			return
+		}
+		if !m.applyVerification(n, err) {
+			return
+		}
+		// 连接 (重连) 后探测节点能力
+		m.detectCapabilities(ctx, n, client)
+	}
+
+	// 核心检查：按链配置的探针检查 (都会顺带获取区块高度)
+	height, err := runProbe(ctx, policy, client)
 	latency := time.Since(start)
 
 	if err != nil {
-		m.markUnhealthy(n, err)
+		// 管理器关闭导致的失败不计入节点统计
+		if parent.Err() != nil {
+			return
+		}
+		m.probeFailed(n, err)
 		return
 	}
+	// 健康检查也会作为半开状态的试探请求
+	if n.breaker.Allow() {
+		n.breaker.Record(true, "health check")
+	}
 
 	// 标记为健康
 	n.mu.Lock()
+	if height <= n.BlockHeight {
+		n.stallCount++
+	} else {
+		n.stallCount = 0
+	}
 	n.IsHealthy = true
 	n.Latency = latency
 	n.BlockHeight = height
 	n.ErrorCount = 0
 	n.mu.Unlock()
+
+	n.observeLatency(latency)
+	n.window.check(true)
+}
+
+// probeFailed 健康检查 / 链校验失败
+// 服务商限流 (429) 或额度用完是背压而不是节点故障：保持节点当前的健康状态，也不计入熔断器
+func (m *RPCManager) probeFailed(n *Node, err error) {
+	if classifyError(context.Background(), err) == errClassRateLimited {
+		if global.Log != nil {
+			global.Log.Debugf("🚦 [RPC] Health check of %s (chain %d) rate limited, keep current state: %v", n.Name, n.ChainID, err)
+		}
+		return
+	}
+	m.markUnhealthy(n, err)
 }
 
 func (m *RPCManager) markUnhealthy(n *Node, err error) {
+	n.window.check(false)
+	if n.breaker.Allow() {
+		n.breaker.Record(false, err.Error())
+	}
+
 	n.mu.Lock()
 	n.IsHealthy = false
 	n.ErrorCount++
 	currentErrCount := n.ErrorCount
 	n.mu.Unlock()
-	
+
 	// 只有连续错误多次才打印 Error 日志，避免刷屏
 	if currentErrCount <= 3 && global.Log != nil {
-		global.Log.Warnf("⚠️ [RPC] Node unhealthy: %s, Err: %v", n.URL, err)
+		global.Log.Warnf("⚠️ [RPC] Node unhealthy: %s (chain %d), Err: %v", n.Name, n.ChainID, err)
 	}
 }
 
 // ================= 4. 对外接口 =================
 
 // GetClient 获取指定链的一个最佳节点
+// 调用方拿到的是裸 client，无法上报结果，所以只会选熔断器处于 closed 状态的节点
+// 需要自动重试/故障转移时请使用 Client(chainID)
 func (m *RPCManager) GetClient(chainID int64) (*ethclient.Client, error) {
+	node, err := m.pickNode(chainID, false, nil, capRequirement{})
+	if err != nil {
+		return nil, err
+	}
+	return node.client(), nil
+}
+
+// Do 选一个节点执行 fn (不重试)，并记录在途请求数、延迟和熔断结果
+func (m *RPCManager) Do(ctx context.Context, chainID int64, fn func(ctx context.Context, client *ethclient.Client) error) error {
+	node, err := m.pickNode(chainID, true, nil, capRequirement{})
+	if err != nil {
+		return err
+	}
+	return m.execute(ctx, node, "custom", fn)
+}
+
+// execute 在指定节点上执行 fn，并把结果回报给节点统计
+// node 必须是 pickNode(trial = true) 选出来的 (已占用熔断器名额)；method 仅用于指标
+func (m *RPCManager) execute(ctx context.Context, node *Node, method string, fn func(ctx context.Context, client *ethclient.Client) error) error {
+	client := node.client()
+	if client == nil {
+		// 管理器已关闭 (或节点被移除) 的瞬间被选中
+		node.breaker.Release()
+		return fmt.Errorf("rpc node %s is closed", node.Name)
+	}
+
+	node.inFlight.Add(1)
+	defer node.inFlight.Add(-1)
+
+	start := time.Now()
+	err := fn(ctx, client)
+	class := classifyError(ctx, err)
+	elapsed := time.Since(start)
+	m.metrics.observeNode(node, method, class, elapsed)
+	node.window.observe(class, elapsed)
+	switch class {
+	case errClassNone, errClassExecution:
+		// 节点正常应答 (revert 也是正常应答)
+		node.observeLatency(time.Since(start))
+		node.breaker.Record(true, "")
+	case errClassCanceled, errClassUnsupported, errClassRateLimited:
+		// 不算节点的错 (限流是背压)，但要归还半开试探名额
+		node.breaker.Release()
+	default:
+		node.breaker.Record(false, err.Error())
+	}
+	return err
+}
+
+// genesisHash 该链配置的创世区块哈希 (为空表示不校验)
+func (m *RPCManager) genesisHash(chainID int64) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.chains[chainID].GenesisHash
+}
+
+// maxAttempts 故障转移时最多尝试的节点数
+func (m *RPCManager) maxAttempts(chainID int64) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if n := m.chains[chainID].MaxAttempts; n > 0 {
+		return n
+	}
+	return defaultMaxAttempts
+}
+
+// pickNode 先按优先级筛出候选节点 (主节点全挂了再用备用节点)，再交给该链的策略选择
+// trial = true 时允许选中半开状态的节点 (占用试探名额，调用方必须上报结果)
+// exclude 中的节点不参与选择 (重试时排除已经失败的节点)
+// req 为调用对节点能力的要求 (归档 / trace / debug / 日志跨度)，不满足的节点不参与选择
+func (m *RPCManager) pickNode(chainID int64, trial bool, exclude map[*Node]bool, req capRequirement) (*Node, error) {
 	m.mu.RLock()
 	nodes, ok := m.chainNodes[chainID]
+	balancer := m.balancers[chainID]
 	m.mu.RUnlock()
 
 	if !ok || len(nodes) == 0 {
 		return nil, fmt.Errorf("chain %d not configured", chainID)
 	}
 
-	// 简单的负载均衡策略：选第一个健康的
-	// 进阶策略：可以遍历 nodes，找 latency 最小的
-	for _, node := range nodes {
-		node.mu.RLock()
-		isHealthy := node.IsHealthy
-        client := node.Client
-		node.mu.RUnlock()
+	incapable := false
+	for _, priority := range []string{config.NodePriorityPrimary, config.NodePriorityBackup} {
+		var candidates, confirmed []*Node
+		for _, node := range nodes {
+			if node.Priority != priority || exclude[node] || !node.available() {
+				continue
+			}
+			if !trial && !node.breaker.Closed() {
+				continue
+			}
+			ok, known := node.capabilities().satisfies(req)
+			if !ok {
+				incapable = true
+				continue
+			}
+			candidates = append(candidates, node)
+			if known {
+				confirmed = append(confirmed, node)
+			}
+		}
+
+		// 确定具备能力的节点优先，能力未知的节点作为兜底 (失败后会学习)
+		if len(confirmed) > 0 {
+			candidates = confirmed
+		}
 
-		if isHealthy && client != nil {
-			return client, nil
+		// 令牌桶还有余量的节点优先，避免请求在限流器上排队
+		candidates = preferWithTokens(candidates)
+
+		// 半开节点的试探名额可能已被占满，选中后申请失败就换一个
+		for len(candidates) > 0 {
+			node := balancer.Pick(candidates)
+			if node.breaker.Allow() {
+				return node, nil
+			}
+			candidates = removeNode(candidates, node)
 		}
 	}
 
+	if incapable {
+		return nil, fmt.Errorf("no healthy node on chain %d can serve this request (archive=%v trace=%v debug=%v log_range=%d)",
+			chainID, req.archive, req.trace, req.debug, req.logRange)
+	}
 	return nil, fmt.Errorf("no healthy node available for chain %d", chainID)
-}
\ No newline at end of file
+}
+
+// NodeStatus 节点状态快照 (对外展示用，不包含带 Key 的 URL)
+type NodeStatus struct {
+	Name               string              `json:"name"`
+	ChainID            int64               `json:"chain_id"`
+	Priority           string              `json:"priority"`
+	Weight             int                 `json:"weight"`
+	Labels             map[string]string   `json:"labels,omitempty"`
+	Provider           string              `json:"provider"`
+	Healthy            bool                `json:"healthy"`
+	Draining           bool                `json:"draining"`
+	Lagging            bool                `json:"lagging"`
+	Quarantined        bool                `json:"quarantined"`
+	QuarantineReason   string              `json:"quarantine_reason,omitempty"`
+	LatencyMs          int64               `json:"latency_ms"`
+	BlockHeight        uint64              `json:"block_height"`
+	ErrorCount         int                 `json:"error_count"`
+	InFlight           int64               `json:"in_flight"`
+	BreakerState       BreakerState        `json:"breaker_state"`
+	Capabilities       Capabilities        `json:"capabilities"`
+	Quota              *QuotaStatus        `json:"quota,omitempty"`
+	BreakerTransitions []BreakerTransition `json:"breaker_transitions,omitempty"`
+}
+
+// NodeStatuses 查询指定链所有节点的状态
+func (m *RPCManager) NodeStatuses(chainID int64) []NodeStatus {
+	m.mu.RLock()
+	nodes := append([]*Node(nil), m.chainNodes[chainID]...)
+	m.mu.RUnlock()
+
+	statuses := make([]NodeStatus, 0, len(nodes))
+	for _, n := range nodes {
+		statuses = append(statuses, n.Status())
+	}
+	return statuses
+}
+
+// allNodes 所有链的节点 (复制出的快照)
+func (m *RPCManager) allNodes() map[int64][]*Node {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	chains := make(map[int64][]*Node, len(m.chainNodes))
+	for chainID, nodes := range m.chainNodes {
+		chains[chainID] = append([]*Node(nil), nodes...)
+	}
+	return chains
+}
+
+// ================= 5. Node 辅助方法 =================
+
+// available 节点当前是否可以接收请求
+func (n *Node) available() bool {
+	n.mu.RLock()
+	healthy := n.IsHealthy && !n.Lagging && !n.Quarantined && !n.Draining && n.verified && n.Client != nil
+	n.mu.RUnlock()
+	// 429 退避中 / 额度用完的节点暂不使用
+	return healthy && n.breaker.Ready() && !n.quota.blocked()
+}
+
+// preferWithTokens 有令牌余量的节点优先；全部没有余量时原样返回 (排队等待)
+func preferWithTokens(nodes []*Node) []*Node {
+	var ready []*Node
+	for _, n := range nodes {
+		if n.quota.hasTokens() {
+			ready = append(ready, n)
+		}
+	}
+	if len(ready) == 0 {
+		return nodes
+	}
+	return ready
+}
+
+// removeNode 从候选列表中去掉一个节点 (返回新切片，不修改原切片)
+func removeNode(nodes []*Node, target *Node) []*Node {
+	out := make([]*Node, 0, len(nodes))
+	for _, n := range nodes {
+		if n != target {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// Status 节点状态快照
+func (n *Node) Status() NodeStatus {
+	n.mu.RLock()
+	st := NodeStatus{
+		Name:             n.Name,
+		ChainID:          n.ChainID,
+		Priority:         n.Priority,
+		Weight:           n.Weight,
+		Labels:           n.Labels,
+		Provider:         n.Provider,
+		Healthy:          n.IsHealthy,
+		Draining:         n.Draining,
+		Lagging:          n.Lagging,
+		Quarantined:      n.Quarantined,
+		QuarantineReason: n.QuarantineReason,
+		LatencyMs:        n.Latency.Milliseconds(),
+		BlockHeight:      n.BlockHeight,
+		ErrorCount:       n.ErrorCount,
+		Capabilities:     n.caps,
+	}
+	n.mu.RUnlock()
+
+	st.InFlight = n.InFlight()
+	st.BreakerState = n.breaker.State()
+	st.BreakerTransitions = n.breaker.Transitions()
+	st.Quota = n.quota.status()
+	return st
+}
+
+func (n *Node) client() *ethclient.Client {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.Client
+}
+
+// InFlight 当前在途请求数
+func (n *Node) InFlight() int64 {
+	return n.inFlight.Load()
+}
+
+func (n *Node) height() uint64 {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.BlockHeight
+}
+
+func (n *Node) ewma() float64 {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.ewmaLatency
+}
+
+// observeLatency 更新延迟的 EWMA
+func (n *Node) observeLatency(latency time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.ewmaLatency == 0 {
+		n.ewmaLatency = float64(latency)
+		return
+	}
+	n.ewmaLatency = ewmaDecay*float64(latency) + (1-ewmaDecay)*n.ewmaLatency
+}
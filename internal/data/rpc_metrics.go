@@ -0,0 +1,150 @@
+package data
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// rpcMetrics RPC 层的 Prometheus 指标
+// 计数 / 直方图在调用路径上实时记录；节点状态类的 gauge 在抓取时从节点快照生成 (见 Collect)
+type rpcMetrics struct {
+	requests  *prometheus.CounterVec   // 每个节点上的一次调用 (按错误分类)
+	latency   *prometheus.HistogramVec // 每个节点上的调用延迟
+	calls     *prometheus.CounterVec   // 门面层的一次调用 (重试 / 对冲后的最终结果)
+	failovers *prometheus.CounterVec   // 换节点重试
+	hedges    *prometheus.CounterVec   // 发出的对冲请求
+	quorum    *prometheus.CounterVec   // 仲裁读结果
+
+	inFlight     *prometheus.Desc
+	healthy      *prometheus.Desc
+	available    *prometheus.Desc
+	height       *prometheus.Desc
+	lag          *prometheus.Desc
+	checkLatency *prometheus.Desc
+	errorCount   *prometheus.Desc
+	breaker      *prometheus.Desc
+}
+
+func newRPCMetrics() *rpcMetrics {
+	nodeLabels := []string{"chain_id", "node", "provider"}
+	return &rpcMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rpc_node_requests_total",
+			Help: "JSON-RPC calls sent to a node, by method and result (ok, execution, unsupported, rate_limited, canceled, transport).",
+		}, []string{"chain_id", "node", "provider", "method", "result"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "rpc_node_request_duration_seconds",
+			Help:    "Latency of JSON-RPC calls sent to a node.",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"chain_id", "node", "provider", "method"}),
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rpc_calls_total",
+			Help: "Calls through the chain client after retries and hedging, by method and final result.",
+		}, []string{"chain_id", "method", "result"}),
+		failovers: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rpc_failovers_total",
+			Help: "Calls retried on another node after a node failed, by the node that failed.",
+		}, []string{"chain_id", "method", "node", "reason"}),
+		hedges: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rpc_hedged_requests_total",
+			Help: "Hedged requests sent because the first node was slower than the latency percentile.",
+		}, []string{"chain_id", "method"}),
+		quorum: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rpc_quorum_reads_total",
+			Help: "Quorum reads, by method and result (agreed, failed).",
+		}, []string{"chain_id", "method", "result"}),
+
+		inFlight:     prometheus.NewDesc("rpc_node_in_flight_requests", "Requests currently executing on the node.", nodeLabels, nil),
+		healthy:      prometheus.NewDesc("rpc_node_healthy", "1 if the last health check succeeded.", nodeLabels, nil),
+		available:    prometheus.NewDesc("rpc_node_available", "1 if the node can take requests (healthy, verified, not lagging, draining, quarantined, open or rate limited).", nodeLabels, nil),
+		height:       prometheus.NewDesc("rpc_node_block_height", "Latest block height seen by the health check.", nodeLabels, nil),
+		lag:          prometheus.NewDesc("rpc_node_lag_blocks", "Blocks behind the best node of the chain.", nodeLabels, nil),
+		checkLatency: prometheus.NewDesc("rpc_node_health_check_latency_seconds", "Latency of the last successful health check.", nodeLabels, nil),
+		errorCount:   prometheus.NewDesc("rpc_node_consecutive_errors", "Consecutive failed health checks.", nodeLabels, nil),
+		breaker:      prometheus.NewDesc("rpc_node_circuit_state", "Circuit breaker state: 1 for the current state, 0 otherwise.", append(nodeLabels, "state"), nil),
+	}
+}
+
+// errClass 在指标里的名称
+func (c errClass) String() string {
+	switch c {
+	case errClassNone:
+		return "ok"
+	case errClassExecution:
+		return "execution"
+	case errClassUnsupported:
+		return "unsupported"
+	case errClassRateLimited:
+		return "rate_limited"
+	case errClassCanceled:
+		return "canceled"
+	default:
+		return "transport"
+	}
+}
+
+func chainLabel(chainID int64) string {
+	return strconv.FormatInt(chainID, 10)
+}
+
+// observeNode 记录一次节点调用
+func (mt *rpcMetrics) observeNode(n *Node, method string, class errClass, elapsed time.Duration) {
+	chain := chainLabel(n.ChainID)
+	mt.requests.WithLabelValues(chain, n.Name, n.Provider, method, class.String()).Inc()
+	if class != errClassCanceled {
+		mt.latency.WithLabelValues(chain, n.Name, n.Provider, method).Observe(elapsed.Seconds())
+	}
+}
+
+// ================= prometheus.Collector =================
+
+// Describe 实现 prometheus.Collector
+func (m *RPCManager) Describe(ch chan<- *prometheus.Desc) {
+	mt := m.metrics
+	for _, c := range []prometheus.Collector{mt.requests, mt.latency, mt.calls, mt.failovers, mt.hedges, mt.quorum} {
+		c.Describe(ch)
+	}
+	for _, d := range []*prometheus.Desc{mt.inFlight, mt.healthy, mt.available, mt.height, mt.lag, mt.checkLatency, mt.errorCount, mt.breaker} {
+		ch <- d
+	}
+}
+
+// Collect 实现 prometheus.Collector：节点状态在抓取时现算，不需要后台维护
+func (m *RPCManager) Collect(ch chan<- prometheus.Metric) {
+	mt := m.metrics
+	for _, c := range []prometheus.Collector{mt.requests, mt.latency, mt.calls, mt.failovers, mt.hedges, mt.quorum} {
+		c.Collect(ch)
+	}
+
+	chains := m.allNodes()
+	gauge := func(desc *prometheus.Desc, v float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, labels...)
+	}
+	for chainID, nodes := range chains {
+		// 与落后检测同一基准：隔离、未校验的节点的高度不参与
+		best := comparableBest(nodes)
+		for _, n := range nodes {
+			st := n.Status()
+			labels := []string{chainLabel(chainID), st.Name, st.Provider}
+			gauge(mt.inFlight, float64(st.InFlight), labels...)
+			gauge(mt.healthy, boolValue(st.Healthy), labels...)
+			gauge(mt.available, boolValue(n.available()), labels...)
+			gauge(mt.height, float64(st.BlockHeight), labels...)
+			gauge(mt.lag, float64(best-min(best, st.BlockHeight)), labels...)
+			gauge(mt.checkLatency, float64(st.LatencyMs)/1000, labels...)
+			gauge(mt.errorCount, float64(st.ErrorCount), labels...)
+			for _, s := range []BreakerState{BreakerClosed, BreakerHalfOpen, BreakerOpen} {
+				gauge(mt.breaker, boolValue(st.BreakerState == s), append(labels, s.String())...)
+			}
+		}
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,52 @@
+package data
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+)
+
+func TestMetricsLagIgnoresQuarantinedNodes(t *testing.T) {
+	chain := config.ChainConfig{ChainID: 1}
+	for _, name := range []string{"a", "b", "c"} {
+		srv := newTestRPCServer(t, 1, nil)
+		chain.Nodes = append(chain.Nodes, config.NodeConfig{Name: name, RpcUrl: srv.URL})
+	}
+	mgr := startTestManager(t, chain)
+
+	// c 被隔离 (可能连的是别的链)，高度远超其他节点，不能作为基准
+	for i, h := range []uint64{100, 95, 5000} {
+		n := mgr.chainNodes[1][i]
+		n.mu.Lock()
+		n.BlockHeight = h
+		n.Quarantined = n.Name == "c"
+		n.mu.Unlock()
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(mgr)
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error: %v", err)
+	}
+	got := map[string]float64{}
+	for _, f := range families {
+		if f.GetName() != "rpc_node_lag_blocks" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "node" {
+					got[l.GetValue()] = m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	want := map[string]float64{"a": 0, "b": 5, "c": 0}
+	for name, lag := range want {
+		if got[name] != lag {
+			t.Errorf("lag of %s = %v, want %v", name, got[name], lag)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+)
+
+// 健康检查探针类型 (对应 chains[].health_check.probe 配置)
+const (
+	ProbeBlockNumber = "block_number" // eth_blockNumber (默认)
+	ProbeSyncing     = "syncing"      // eth_syncing：同步中的节点视为不健康
+	ProbePeerCount   = "peer_count"   // net_peerCount：对等节点数低于 min_peers 视为不健康
+	ProbeCustom      = "custom"       // 自定义 JSON-RPC 方法：调用成功即健康
+)
+
+const (
+	defaultHealthInterval = 30 * time.Second
+	defaultHealthTimeout  = 5 * time.Second
+	defaultMinPeers       = 1
+)
+
+// healthPolicy 健康检查参数 (已填充默认值)
+type healthPolicy struct {
+	interval     time.Duration
+	timeout      time.Duration
+	jitter       time.Duration
+	initialProbe bool
+	probe        string
+	method       string
+	params       []interface{}
+	minPeers     uint64
+}
+
+func newHealthPolicy(c config.HealthCheckConfig) (healthPolicy, error) {
+	p := healthPolicy{
+		interval:     c.Interval,
+		timeout:      c.Timeout,
+		jitter:       c.Jitter,
+		initialProbe: c.InitialProbe == nil || *c.InitialProbe,
+		probe:        c.Probe,
+		method:       c.Method,
+		params:       c.Params,
+		minPeers:     c.MinPeers,
+	}
+	if p.interval <= 0 {
+		p.interval = defaultHealthInterval
+	}
+	if p.timeout <= 0 {
+		p.timeout = defaultHealthTimeout
+	}
+	if p.jitter < 0 {
+		p.jitter = 0
+	}
+	if p.minPeers == 0 {
+		p.minPeers = defaultMinPeers
+	}
+
+	switch p.probe {
+	case "":
+		p.probe = ProbeBlockNumber
+	case ProbeBlockNumber, ProbeSyncing, ProbePeerCount:
+	case ProbeCustom:
+		if p.method == "" {
+			return p, fmt.Errorf("health check probe %q requires method", ProbeCustom)
+		}
+	default:
+		return p, fmt.Errorf("unknown health check probe %q", p.probe)
+	}
+	return p, nil
+}
+
+// nextDelay 下一次检查的等待时间 = interval + [0, jitter)
+// 加抖动是为了避免多个实例同时打同一个节点
+func (p healthPolicy) nextDelay() time.Duration {
+	if p.jitter <= 0 {
+		return p.interval
+	}
+	return p.interval + rand.N(p.jitter)
+}
+
+// runProbe 执行探针，返回节点当前高度
+// 所有探针都会获取区块高度 (落后检测依赖它)，非默认探针额外做对应的检查
+func runProbe(ctx context.Context, p healthPolicy, client *ethclient.Client) (uint64, error) {
+	height, err := client.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	switch p.probe {
+	case ProbeSyncing:
+		progress, err := client.SyncProgress(ctx)
+		if err != nil {
+			return height, err
+		}
+		if progress != nil {
+			return height, fmt.Errorf("node is syncing: current=%d highest=%d", progress.CurrentBlock, progress.HighestBlock)
+		}
+	case ProbePeerCount:
+		var peers hexutil.Uint64
+		if err := client.Client().CallContext(ctx, &peers, "net_peerCount"); err != nil {
+			return height, err
+		}
+		if uint64(peers) < p.minPeers {
+			return height, fmt.Errorf("peer count %d below minimum %d", peers, p.minPeers)
+		}
+	case ProbeCustom:
+		var result json.RawMessage
+		if err := client.Client().CallContext(ctx, &result, p.method, p.params...); err != nil {
+			return height, err
+		}
+	}
+	return height, nil
+}
@@ -0,0 +1,364 @@
+package data
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+	"github.com/zy99978455-otw/go-micro-template/pkg/global"
+)
+
+const defaultQuorumNodes = 3
+
+// quorumPolicy 仲裁读策略
+type quorumPolicy struct {
+	nodes    int  // 参与仲裁的节点数 N
+	required int  // 至少 M 个一致
+	penalize bool // 与多数不一致的节点计入熔断失败
+}
+
+func newQuorumPolicy(c config.QuorumConfig) quorumPolicy {
+	p := quorumPolicy{nodes: c.Nodes, required: c.Required, penalize: !c.DisablePenalty}
+	if p.nodes <= 0 {
+		p.nodes = defaultQuorumNodes
+	}
+	if p.required <= 0 {
+		p.required = p.nodes/2 + 1
+	}
+	if p.required > p.nodes {
+		p.required = p.nodes
+	}
+	return p
+}
+
+// QuorumAnswer 单个节点的应答
+type QuorumAnswer struct {
+	Node     string `json:"node"`
+	Provider string `json:"provider"`
+	Value    string `json:"value,omitempty"` // 应答的规范化表示，相同表示视为一致
+	Error    string `json:"error,omitempty"` // 调用失败 (不参与投票)
+}
+
+// QuorumError 仲裁失败：独立服务商不足，或一致的应答不足 M 个
+type QuorumError struct {
+	ChainID  int64
+	Method   string
+	Required int
+	Agreed   int  // 最大一致组的节点数
+	Tied     bool // 有多个同样大的一致组
+	Answers  []QuorumAnswer
+}
+
+func (e *QuorumError) Error() string {
+	if e.Tied {
+		return fmt.Sprintf("quorum not reached for %s on chain %d: tie between groups of %d across %d nodes", e.Method, e.ChainID, e.Agreed, len(e.Answers))
+	}
+	return fmt.Sprintf("quorum not reached for %s on chain %d: %d/%d agreed across %d nodes", e.Method, e.ChainID, e.Agreed, e.Required, len(e.Answers))
+}
+
+// QuorumClient 仲裁读客户端：同一调用发给多个不同服务商的节点，至少 M 个一致才接受
+// 适用于打款前的余额校验等不能信任单一服务商的场景 (不走缓存)
+type QuorumClient struct {
+	c *ChainClient
+}
+
+// Quorum 获取该链的仲裁读客户端
+func (c *ChainClient) Quorum() *QuorumClient {
+	return &QuorumClient{c: c}
+}
+
+// BalanceAt 仲裁查询余额；blockNumber 为 nil 时固定到参与节点共同达到的高度，避免因高度不同而不一致
+func (q *QuorumClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return quorum(ctx, q.c.require(q.c.stateAt(blockNumber)), "eth_getBalance", blockNumber, true,
+		func(ctx context.Context, cl *ethclient.Client, block *big.Int) (*big.Int, error) {
+			return cl.BalanceAt(ctx, account, block)
+		},
+		func(v *big.Int) string { return v.String() })
+}
+
+// CallContract 仲裁只读调用；blockNumber 为 nil 时同 BalanceAt 固定高度
+func (q *QuorumClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return quorum(ctx, q.c.require(q.c.stateAt(blockNumber)), "eth_call", blockNumber, true,
+		func(ctx context.Context, cl *ethclient.Client, block *big.Int) ([]byte, error) {
+			return cl.CallContract(ctx, msg, block)
+		},
+		hexutil.Encode)
+}
+
+// TransactionReceipt 仲裁查询交易回执
+func (q *QuorumClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return quorum(ctx, q.c, "eth_getTransactionReceipt", nil, false,
+		func(ctx context.Context, cl *ethclient.Client, _ *big.Int) (*types.Receipt, error) {
+			return cl.TransactionReceipt(ctx, txHash)
+		},
+		receiptDigest)
+}
+
+// TokenBalance 仲裁查询 ERC-20 balanceOf(account)；blockNumber 为 nil 时同 BalanceAt 固定高度
+func (q *QuorumClient) TokenBalance(ctx context.Context, token, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return tokenBalance(ctx, q.CallContract, token, account, blockNumber)
+}
+
+// BlockHashByNumber 仲裁查询指定高度的区块哈希；number 为 nil 时固定到参与节点共同达到的高度 (各节点的 latest 不同)
+func (q *QuorumClient) BlockHashByNumber(ctx context.Context, number *big.Int) (common.Hash, error) {
+	return quorum(ctx, q.c, "eth_getBlockByNumber", number, true,
+		func(ctx context.Context, cl *ethclient.Client, block *big.Int) (common.Hash, error) {
+			h, err := cl.HeaderByNumber(ctx, block)
+			if err != nil {
+				return common.Hash{}, err
+			}
+			return h.Hash(), nil
+		},
+		common.Hash.Hex)
+}
+
+// quorum 仲裁核心
+// 1. 每个服务商选一个可用节点，最多 N 个；不足 M 个直接失败
+// 2. 查询状态 (pin) 且未指定 block 时，固定到参与节点当前共同达到的高度
+// 3. 并发调用，按规范化结果分组 (NotFound、revert 也算有效应答)，网络错误不参与投票
+// 4. 最大组达到 M 个且没有平票即接受，并惩罚与多数不一致的节点；否则返回带每个节点应答的 *QuorumError
+func quorum[T any](ctx context.Context, c *ChainClient, method string, block *big.Int, pin bool,
+	fn func(ctx context.Context, cl *ethclient.Client, block *big.Int) (T, error), key func(T) string) (T, error) {
+	var zero T
+	policy := c.mgr.quorumPolicy(c.chainID)
+	nodes := c.mgr.quorumNodes(c.chainID, policy.nodes, c.req)
+	if len(nodes) < policy.required {
+		answers := make([]QuorumAnswer, 0, len(nodes))
+		for _, n := range nodes {
+			answers = append(answers, QuorumAnswer{Node: n.Name, Provider: n.Provider})
+		}
+		c.mgr.metrics.quorum.WithLabelValues(chainLabel(c.chainID), method, "failed").Inc()
+		return zero, &QuorumError{ChainID: c.chainID, Method: method, Required: policy.required, Answers: answers}
+	}
+
+	// 固定高度：现查参与节点的最新高度取最低值 (健康检查记录的高度可能已过时)
+	if pin && block == nil {
+		if h := commonHeight(ctx, c.mgr, nodes); h > 0 {
+			block = new(big.Int).SetUint64(h)
+		}
+	}
+
+	type vote struct {
+		value T
+		err   error
+		key   string
+		ok    bool // 是否参与投票
+	}
+	votes := make([]vote, len(nodes))
+	var wg sync.WaitGroup
+	for i, n := range nodes {
+		wg.Add(1)
+		go func(i int, n *Node) {
+			defer wg.Done()
+			var v vote
+			v.err = c.mgr.execute(ctx, n, method, func(ctx context.Context, cl *ethclient.Client) (err error) {
+				v.value, err = fn(ctx, cl, block)
+				return err
+			})
+			switch {
+			case v.err == nil:
+				v.key, v.ok = key(v.value), true
+			case errors.Is(v.err, ethereum.NotFound):
+				v.key, v.ok = "not found", true
+			case classifyError(ctx, v.err) == errClassExecution:
+				v.key, v.ok = executionKey(v.err), true
+			}
+			votes[i] = v
+		}(i, n)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+
+	// 计票
+	tally := make(map[string]int)
+	best := ""
+	for _, v := range votes {
+		if v.ok {
+			tally[v.key]++
+			if tally[v.key] > tally[best] {
+				best = v.key
+			}
+		}
+	}
+	// 平票：达到 M 个的组不止一个 (M 不超过半数时可能出现)，不接受任何一组
+	tied := false
+	if tally[best] >= policy.required {
+		for k, n := range tally {
+			if k != best && n == tally[best] {
+				tied = true
+			}
+		}
+	}
+
+	if tally[best] < policy.required || tied {
+		answers := make([]QuorumAnswer, len(nodes))
+		for i, n := range nodes {
+			answers[i] = QuorumAnswer{Node: n.Name, Provider: n.Provider}
+			if votes[i].ok {
+				answers[i].Value = votes[i].key
+			} else if votes[i].err != nil {
+				answers[i].Error = votes[i].err.Error()
+			}
+		}
+		c.mgr.metrics.quorum.WithLabelValues(chainLabel(c.chainID), method, "failed").Inc()
+		if global.Log != nil {
+			global.Log.Warnf("⚖️ [RPC] chain %d %s quorum not reached (%d/%d agreed, tied=%v)", c.chainID, method, tally[best], policy.required, tied)
+		}
+		return zero, &QuorumError{ChainID: c.chainID, Method: method, Required: policy.required, Agreed: tally[best], Tied: tied, Answers: answers}
+	}
+
+	// 达成仲裁：惩罚少数派，返回多数派的结果
+	c.mgr.metrics.quorum.WithLabelValues(chainLabel(c.chainID), method, "agreed").Inc()
+	var result vote
+	for i, v := range votes {
+		if !v.ok {
+			continue
+		}
+		if v.key == best {
+			result = v
+			continue
+		}
+		if global.Log != nil {
+			global.Log.Warnf("⚖️ [RPC] chain %d node %s (%s) disagrees with quorum on %s: got %s, majority %s", c.chainID, nodes[i].Name, nodes[i].Provider, method, v.key, best)
+		}
+		if policy.penalize {
+			nodes[i].breaker.Record(false, "quorum disagreement on "+method)
+		}
+	}
+	return result.value, result.err
+}
+
+// quorumPolicy 获取链的仲裁策略
+func (m *RPCManager) quorumPolicy(chainID int64) quorumPolicy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return newQuorumPolicy(m.chains[chainID].Quorum)
+}
+
+// quorumNodes 每个服务商选一个可用节点 (主节点优先，其次延迟低)，最多 limit 个
+// 只选熔断器 closed 且能力满足 req 的节点，不占用半开试探名额
+func (m *RPCManager) quorumNodes(chainID int64, limit int, req capRequirement) []*Node {
+	m.mu.RLock()
+	nodes := append([]*Node(nil), m.chainNodes[chainID]...)
+	m.mu.RUnlock()
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		pi, pj := nodes[i].Priority == config.NodePriorityPrimary, nodes[j].Priority == config.NodePriorityPrimary
+		if pi != pj {
+			return pi
+		}
+		return nodes[i].ewma() < nodes[j].ewma()
+	})
+
+	seen := make(map[string]bool)
+	picked := make([]*Node, 0, limit)
+	for _, n := range nodes {
+		if len(picked) == limit {
+			break
+		}
+		if seen[n.Provider] || !n.available() || !n.breaker.Closed() {
+			continue
+		}
+		if ok, _ := n.capabilities().satisfies(req); !ok {
+			continue
+		}
+		seen[n.Provider] = true
+		picked = append(picked, n)
+	}
+	return picked
+}
+
+// nodeProvider 节点所属服务商：优先取 labels.provider，否则取 URL 的域名
+func nodeProvider(labels map[string]string, rawURL string) string {
+	if p := labels["provider"]; p != "" {
+		return p
+	}
+	if u, err := url.Parse(rawURL); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return rawURL
+}
+
+// commonHeight 并发查询各节点的 eth_blockNumber，返回最低值；都查询失败时退回健康检查记录的高度
+func commonHeight(ctx context.Context, m *RPCManager, nodes []*Node) uint64 {
+	heights := make([]uint64, len(nodes))
+	var wg sync.WaitGroup
+	for i, n := range nodes {
+		wg.Add(1)
+		go func(i int, n *Node) {
+			defer wg.Done()
+			_ = m.execute(ctx, n, "eth_blockNumber", func(ctx context.Context, cl *ethclient.Client) (err error) {
+				heights[i], err = cl.BlockNumber(ctx)
+				return err
+			})
+		}(i, n)
+	}
+	wg.Wait()
+
+	var lowest uint64
+	for _, h := range heights {
+		if h > 0 && (lowest == 0 || h < lowest) {
+			lowest = h
+		}
+	}
+	if lowest == 0 {
+		return minHeight(nodes)
+	}
+	return lowest
+}
+
+// executionKey 执行类错误的投票键：错误码 + revert data (消息文本各服务商不同，不参与比较)
+func executionKey(err error) string {
+	code := 0
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		code = rpcErr.ErrorCode()
+	}
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) && dataErr.ErrorData() != nil {
+		return fmt.Sprintf("error: code=%d data=%v", code, dataErr.ErrorData())
+	}
+	return fmt.Sprintf("error: code=%d", code)
+}
+
+func minHeight(nodes []*Node) uint64 {
+	var lowest uint64
+	for _, n := range nodes {
+		if h := n.height(); h > 0 && (lowest == 0 || h < lowest) {
+			lowest = h
+		}
+	}
+	return lowest
+}
+
+// receiptDigest 回执的规范化表示：只取共识字段 (状态、区块哈希、gas 用量、日志的地址 / topics / data)
+// effectiveGasPrice、blob 字段、logIndex 等由节点推算或各服务商返回不一致的字段不参与比较
+func receiptDigest(r *types.Receipt) string {
+	var buf []byte
+	for _, l := range r.Logs {
+		buf = append(buf, l.Address.Bytes()...)
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(l.Topics)))
+		for _, topic := range l.Topics {
+			buf = append(buf, topic.Bytes()...)
+		}
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(l.Data)))
+		buf = append(buf, l.Data...)
+	}
+	return fmt.Sprintf("block=%s status=%d gas_used=%d logs=%d digest=%s",
+		r.BlockHash.Hex(), r.Status, r.GasUsed, len(r.Logs), crypto.Keccak256Hash(buf).Hex()[:18])
+}
@@ -0,0 +1,235 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+)
+
+// quorumTestNode 仲裁测试节点：eth_blockNumber 返回 height，eth_getBalance 返回 balance，并记录收到的区块参数
+type quorumTestNode struct {
+	height  uint64
+	balance int64
+
+	mu     sync.Mutex
+	blocks []string
+}
+
+func (n *quorumTestNode) handle(method string, params []json.RawMessage) (any, *rpcTestError, bool) {
+	switch method {
+	case "eth_blockNumber":
+		return fmt.Sprintf("0x%x", n.height), nil, true
+	case "eth_getBalance":
+		n.record(params[1])
+		return fmt.Sprintf("0x%x", n.balance), nil, true
+	case "eth_getBlockByNumber":
+		var tag string
+		_ = json.Unmarshal(params[0], &tag)
+		if tag == "0x0" {
+			return quorumTestHeader(0), nil, true
+		}
+		n.record(params[0])
+		number := n.height
+		if tag != "latest" {
+			number = new(big.Int).SetBytes(common.FromHex(tag)).Uint64()
+		}
+		return quorumTestHeader(number), nil, true
+	}
+	return nil, nil, false
+}
+
+func (n *quorumTestNode) record(param json.RawMessage) {
+	var block string
+	_ = json.Unmarshal(param, &block)
+	n.mu.Lock()
+	n.blocks = append(n.blocks, block)
+	n.mu.Unlock()
+}
+
+func (n *quorumTestNode) reset() {
+	n.mu.Lock()
+	n.blocks = nil
+	n.mu.Unlock()
+}
+
+// quorumTestHeader 同一高度在所有节点上返回相同的区块
+func quorumTestHeader(number uint64) *types.Header {
+	return &types.Header{Number: new(big.Int).SetUint64(number), Difficulty: big.NewInt(1)}
+}
+
+// startQuorumManager 每个测试节点属于不同的服务商 (labels.provider)；返回前清空启动探测留下的记录
+func startQuorumManager(t *testing.T, quorum config.QuorumConfig, nodes []*quorumTestNode) *RPCManager {
+	t.Helper()
+	chain := config.ChainConfig{ChainID: 1, Quorum: quorum}
+	for i, n := range nodes {
+		srv := newTestRPCServer(t, 1, n.handle)
+		chain.Nodes = append(chain.Nodes, config.NodeConfig{
+			Name:   fmt.Sprintf("node-%d", i),
+			RpcUrl: srv.URL,
+			Labels: map[string]string{"provider": fmt.Sprintf("provider-%d", i)},
+		})
+	}
+	mgr := startTestManager(t, chain)
+	for _, n := range nodes {
+		n.reset()
+	}
+	return mgr
+}
+
+func TestQuorumBalanceAt(t *testing.T) {
+	tests := []struct {
+		name     string
+		quorum   config.QuorumConfig
+		balances []int64
+		want     int64
+		wantErr  *QuorumError // 只比较 Agreed / Tied
+	}{
+		{name: "all agree", balances: []int64{7, 7, 7}, want: 7},
+		{name: "majority wins", balances: []int64{7, 7, 8}, want: 7},
+		{name: "no majority", balances: []int64{7, 8, 9}, wantErr: &QuorumError{Agreed: 1}},
+		{
+			name:     "tie is rejected",
+			quorum:   config.QuorumConfig{Nodes: 4, Required: 2},
+			balances: []int64{7, 7, 8, 8},
+			wantErr:  &QuorumError{Agreed: 2, Tied: true},
+		},
+		{
+			name:     "too few providers",
+			quorum:   config.QuorumConfig{Nodes: 3, Required: 3},
+			balances: []int64{7, 7},
+			wantErr:  &QuorumError{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nodes := make([]*quorumTestNode, len(tt.balances))
+			for i, b := range tt.balances {
+				nodes[i] = &quorumTestNode{height: 100 + uint64(i), balance: b}
+			}
+			mgr := startQuorumManager(t, tt.quorum, nodes)
+
+			got, err := mgr.Client(1).Quorum().BalanceAt(context.Background(), common.Address{}, nil)
+			if tt.wantErr != nil {
+				var qErr *QuorumError
+				if !errors.As(err, &qErr) {
+					t.Fatalf("BalanceAt() error = %v, want *QuorumError", err)
+				}
+				if qErr.Agreed != tt.wantErr.Agreed || qErr.Tied != tt.wantErr.Tied {
+					t.Errorf("QuorumError agreed = %d tied = %v, want %d %v", qErr.Agreed, qErr.Tied, tt.wantErr.Agreed, tt.wantErr.Tied)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("BalanceAt() unexpected error: %v", err)
+			}
+			if got.Int64() != tt.want {
+				t.Errorf("BalanceAt() = %s, want %d", got, tt.want)
+			}
+
+			// latest 固定到参与节点中最低的高度
+			for i, n := range nodes {
+				n.mu.Lock()
+				if len(n.blocks) != 1 || n.blocks[0] != "0x64" {
+					t.Errorf("node %d got blocks %v, want [0x64]", i, n.blocks)
+				}
+				n.mu.Unlock()
+			}
+		})
+	}
+}
+
+func TestQuorumBlockHashByNumber(t *testing.T) {
+	nodes := []*quorumTestNode{{height: 100}, {height: 101}, {height: 102}}
+	mgr := startQuorumManager(t, config.QuorumConfig{}, nodes)
+
+	tests := []struct {
+		name      string
+		number    *big.Int
+		want      common.Hash
+		wantBlock string
+	}{
+		{name: "latest pinned to common height", want: quorumTestHeader(100).Hash(), wantBlock: "0x64"},
+		{name: "explicit number", number: big.NewInt(50), want: quorumTestHeader(50).Hash(), wantBlock: "0x32"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, n := range nodes {
+				n.reset()
+			}
+
+			got, err := mgr.Client(1).Quorum().BlockHashByNumber(context.Background(), tt.number)
+			if err != nil {
+				t.Fatalf("BlockHashByNumber() unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("BlockHashByNumber() = %s, want %s", got.Hex(), tt.want.Hex())
+			}
+			for i, n := range nodes {
+				n.mu.Lock()
+				if len(n.blocks) != 1 || n.blocks[0] != tt.wantBlock {
+					t.Errorf("node %d got blocks %v, want [%s]", i, n.blocks, tt.wantBlock)
+				}
+				n.mu.Unlock()
+			}
+		})
+	}
+}
+
+func TestReceiptDigest(t *testing.T) {
+	receipt := func(mutate func(r *types.Receipt)) *types.Receipt {
+		r := &types.Receipt{
+			Status:            types.ReceiptStatusSuccessful,
+			BlockHash:         common.Hash{0x01},
+			BlockNumber:       big.NewInt(100),
+			GasUsed:           21000,
+			EffectiveGasPrice: big.NewInt(1e9),
+			Logs: []*types.Log{
+				{Address: common.Address{0xaa}, Topics: []common.Hash{{0x01}, {0x02}}, Data: []byte{0x01}, Index: 3},
+			},
+		}
+		if mutate != nil {
+			mutate(r)
+		}
+		return r
+	}
+	base := receiptDigest(receipt(nil))
+
+	tests := []struct {
+		name   string
+		mutate func(r *types.Receipt)
+		same   bool
+	}{
+		{name: "derived fields ignored", same: true, mutate: func(r *types.Receipt) {
+			r.EffectiveGasPrice = big.NewInt(2e9)
+			r.CumulativeGasUsed = 99999
+			r.Logs[0].Index = 7
+			r.Logs[0].TxIndex = 1
+		}},
+		{name: "status differs", mutate: func(r *types.Receipt) { r.Status = types.ReceiptStatusFailed }},
+		{name: "block hash differs", mutate: func(r *types.Receipt) { r.BlockHash = common.Hash{0x02} }},
+		{name: "gas used differs", mutate: func(r *types.Receipt) { r.GasUsed = 21001 }},
+		{name: "log address differs", mutate: func(r *types.Receipt) { r.Logs[0].Address = common.Address{0xbb} }},
+		{name: "log topic differs", mutate: func(r *types.Receipt) { r.Logs[0].Topics[1] = common.Hash{0x03} }},
+		{name: "log data differs", mutate: func(r *types.Receipt) { r.Logs[0].Data = []byte{0x02} }},
+		{name: "topic moved into data", mutate: func(r *types.Receipt) {
+			r.Logs[0].Topics = r.Logs[0].Topics[:1]
+			r.Logs[0].Data = append(common.Hash{0x02}.Bytes(), 0x01)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := receiptDigest(receipt(tt.mutate))
+			if (got == base) != tt.same {
+				t.Errorf("receiptDigest() = %s, base %s, want same = %v", got, base, tt.same)
+			}
+		})
+	}
+}
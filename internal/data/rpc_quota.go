@@ -0,0 +1,349 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/time/rate"
+
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+	"github.com/zy99978455-otw/go-micro-template/pkg/global"
+)
+
+const (
+	defaultComputeUnits = 1                // 未配置 method_costs 的方法按 1 CU 计
+	minRateLimitBackoff = time.Second      // 收到 429 后的最短退避
+	maxRateLimitBackoff = 60 * time.Second // 退避上限
+
+	quotaSyncInterval = 5 * time.Second // CU 计数同步到 Redis 的间隔
+	quotaSyncTimeout  = 2 * time.Second
+	quotaKeyPrefix    = "rpc:quota:"
+)
+
+// errBudgetExhausted 节点的日/月额度已用完
+var errBudgetExhausted = errors.New("rpc node compute-unit budget exhausted")
+
+// nodeQuota 节点级限流与额度统计
+// 1. 令牌桶：平滑请求速率，超出时排队等待
+// 2. CU 额度：按方法计费，日/月额度用完后节点不再被选中 (UTC 自然日/月重置)
+// 3. 429 退避：服务商返回限流时暂停使用该节点一段时间，不计入节点故障
+// 配置了 Redis 时 CU 计数定期同步到 Redis (见 PersistQuota)，重启后继续累计
+type nodeQuota struct {
+	limiter       *rate.Limiter // nil 表示不限速
+	dailyBudget   uint64        // 0 表示不限
+	monthlyBudget uint64
+	costs         map[string]uint64
+	defaultCost   uint64
+
+	mu           sync.Mutex
+	day, month   string
+	usedDay      uint64
+	usedMonth    uint64
+	backoffUntil time.Time
+	backoff      time.Duration // 连续 429 时退避翻倍
+
+	// Redis 持久化 (nil 表示只在内存中计数)
+	rdb          *redis.Client
+	key          string // rpc:quota:{chain_id}:{node}
+	pendingDay   uint64 // 上次同步后新增、尚未写入 Redis 的 CU
+	pendingMonth uint64
+}
+
+func newNodeQuota(c config.RateLimitConfig) *nodeQuota {
+	q := &nodeQuota{
+		dailyBudget:   c.DailyBudget,
+		monthlyBudget: c.MonthlyBudget,
+		costs:         make(map[string]uint64, len(c.MethodCosts)),
+		defaultCost:   c.DefaultCost,
+	}
+	if c.RPS > 0 {
+		burst := c.Burst
+		if burst <= 0 {
+			burst = int(c.RPS) + 1
+		}
+		q.limiter = rate.NewLimiter(rate.Limit(c.RPS), burst)
+	}
+	if q.defaultCost == 0 {
+		q.defaultCost = defaultComputeUnits
+	}
+	// viper 会把 map 的 key 转成小写，这里统一按小写查找
+	for method, cost := range c.MethodCosts {
+		q.costs[strings.ToLower(method)] = cost
+	}
+	return q
+}
+
+// probeCtxKey 标记健康检查发出的请求
+type probeCtxKey struct{}
+
+// withProbe 标记 ctx 为健康检查：不等待令牌、不扣减额度，额度用完时也照常探测
+func withProbe(ctx context.Context) context.Context {
+	return context.WithValue(ctx, probeCtxKey{}, true)
+}
+
+func isProbe(ctx context.Context) bool {
+	probe, _ := ctx.Value(probeCtxKey{}).(bool)
+	return probe
+}
+
+// acquire 发送一个调用前：等待令牌并扣减额度 (健康检查除外)
+func (q *nodeQuota) acquire(ctx context.Context, method string) error {
+	if q == nil || isProbe(ctx) {
+		return nil
+	}
+	if q.limiter != nil {
+		if err := q.limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	cost := q.defaultCost
+	if c, ok := q.costs[strings.ToLower(method)]; ok {
+		cost = c
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rollLocked(time.Now())
+	if (q.dailyBudget > 0 && q.usedDay+cost > q.dailyBudget) ||
+		(q.monthlyBudget > 0 && q.usedMonth+cost > q.monthlyBudget) {
+		return errBudgetExhausted
+	}
+	q.usedDay += cost
+	q.usedMonth += cost
+	q.pendingDay += cost
+	q.pendingMonth += cost
+	return nil
+}
+
+// throttle 收到 429：退避 (优先使用 Retry-After)
+func (q *nodeQuota) throttle(name string, retryAfter time.Duration) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	if q.backoff == 0 {
+		q.backoff = minRateLimitBackoff
+	} else {
+		q.backoff = min(q.backoff*2, maxRateLimitBackoff)
+	}
+	wait := q.backoff
+	if retryAfter > 0 {
+		wait = min(retryAfter, maxRateLimitBackoff)
+	}
+	q.backoffUntil = time.Now().Add(wait)
+	q.mu.Unlock()
+
+	if global.Log != nil {
+		global.Log.Warnf("🚦 [RPC] Node %s rate limited by provider, backing off %v", name, wait)
+	}
+}
+
+// recover 请求成功：清除连续退避
+func (q *nodeQuota) recover() {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	q.backoff = 0
+	q.mu.Unlock()
+}
+
+// blocked 节点是否因 429 退避或额度用完而暂停使用
+func (q *nodeQuota) blocked() bool {
+	if q == nil {
+		return false
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := time.Now()
+	q.rollLocked(now)
+	if now.Before(q.backoffUntil) {
+		return true
+	}
+	return (q.dailyBudget > 0 && q.usedDay >= q.dailyBudget) ||
+		(q.monthlyBudget > 0 && q.usedMonth >= q.monthlyBudget)
+}
+
+// hasTokens 令牌桶当前是否有余量 (用于优先选择不需要排队的节点)
+func (q *nodeQuota) hasTokens() bool {
+	return q == nil || q.limiter == nil || q.limiter.Tokens() >= 1
+}
+
+// QuotaStatus 额度使用情况
+type QuotaStatus struct {
+	UsedToday     uint64    `json:"used_today"`
+	DailyBudget   uint64    `json:"daily_budget"`
+	UsedMonth     uint64    `json:"used_month"`
+	MonthlyBudget uint64    `json:"monthly_budget"`
+	Blocked       bool      `json:"blocked"`
+	BackoffUntil  time.Time `json:"backoff_until,omitempty"`
+}
+
+func (q *nodeQuota) status() *QuotaStatus {
+	if q == nil {
+		return nil
+	}
+	blocked := q.blocked()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return &QuotaStatus{
+		UsedToday:     q.usedDay,
+		DailyBudget:   q.dailyBudget,
+		UsedMonth:     q.usedMonth,
+		MonthlyBudget: q.monthlyBudget,
+		Blocked:       blocked,
+		BackoffUntil:  q.backoffUntil,
+	}
+}
+
+// rollLocked 跨天/跨月时重置计数 (UTC)
+func (q *nodeQuota) rollLocked(now time.Time) {
+	now = now.UTC()
+	if day := now.Format(time.DateOnly); day != q.day {
+		q.day = day
+		q.usedDay = 0
+		q.pendingDay = 0
+	}
+	if month := now.Format("2006-01"); month != q.month {
+		q.month = month
+		q.usedMonth = 0
+		q.pendingMonth = 0
+	}
+}
+
+// ================= CU 计数持久化 =================
+
+// PersistQuota 把配置了日/月额度的节点的 CU 计数同步到 Redis (在 Start 之前调用；rdb 为 nil 时只在内存中计数)
+// 同一 chain_id + 节点名的计数在多个实例之间共享，与服务商按账号计费一致
+func (m *RPCManager) PersistQuota(rdb *redis.Client) {
+	if rdb == nil {
+		return
+	}
+	m.mu.Lock()
+	m.quotaRedis = rdb
+	for _, nodes := range m.chainNodes {
+		for _, n := range nodes {
+			n.quota.persist(rdb, n.ChainID, n.Name)
+		}
+	}
+	m.mu.Unlock()
+}
+
+// quotaSyncLoop 定期同步 CU 计数，停止时再同步一次
+func (m *RPCManager) quotaSyncLoop(ctx context.Context) {
+	ticker := time.NewTicker(quotaSyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			m.syncQuotas(context.WithoutCancel(ctx))
+			return
+		case <-ticker.C:
+			m.syncQuotas(ctx)
+		}
+	}
+}
+
+func (m *RPCManager) syncQuotas(ctx context.Context) {
+	for _, nodes := range m.allNodes() {
+		for _, n := range nodes {
+			if err := n.quota.sync(ctx); err != nil && global.Log != nil {
+				global.Log.Warnf("⚠️ [RPC] Sync CU usage of %s to redis failed: %v", n.Name, err)
+			}
+		}
+	}
+}
+
+// persist 开启 Redis 持久化 (只对配置了额度的节点)
+func (q *nodeQuota) persist(rdb *redis.Client, chainID int64, name string) {
+	if q == nil || (q.dailyBudget == 0 && q.monthlyBudget == 0) {
+		return
+	}
+	q.mu.Lock()
+	q.rdb = rdb
+	q.key = fmt.Sprintf("%s%d:%s", quotaKeyPrefix, chainID, name)
+	q.mu.Unlock()
+}
+
+// sync 把未同步的 CU 累加到 Redis，并用 Redis 中的总数 (包括重启前和其他实例的用量) 更新本地计数
+func (q *nodeQuota) sync(ctx context.Context) error {
+	if q == nil {
+		return nil
+	}
+	q.mu.Lock()
+	if q.rdb == nil {
+		q.mu.Unlock()
+		return nil
+	}
+	q.rollLocked(time.Now())
+	rdb, day, month := q.rdb, q.day, q.month
+	deltaDay, deltaMonth := q.pendingDay, q.pendingMonth
+	q.pendingDay, q.pendingMonth = 0, 0
+	q.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, quotaSyncTimeout)
+	defer cancel()
+	dayKey, monthKey := q.key+":"+day, q.key+":"+month
+	pipe := rdb.TxPipeline()
+	dayTotal := pipe.IncrBy(ctx, dayKey, int64(deltaDay))
+	pipe.Expire(ctx, dayKey, 48*time.Hour)
+	monthTotal := pipe.IncrBy(ctx, monthKey, int64(deltaMonth))
+	pipe.Expire(ctx, monthKey, 32*24*time.Hour)
+	_, err := pipe.Exec(ctx)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err != nil {
+		// 写入失败：放回待同步计数，下次再试 (已跨天/跨月的部分丢弃)
+		if q.day == day {
+			q.pendingDay += deltaDay
+		}
+		if q.month == month {
+			q.pendingMonth += deltaMonth
+		}
+		return err
+	}
+	// 总数 = Redis 中的总数 + 同步期间新增的
+	if q.day == day {
+		q.usedDay = uint64(dayTotal.Val()) + q.pendingDay
+	}
+	if q.month == month {
+		q.usedMonth = uint64(monthTotal.Val()) + q.pendingMonth
+	}
+	return nil
+}
+
+// ================= 429 识别 =================
+
+// JSON-RPC 限流错误码 (Infura / Alchemy 等常用 -32005 或 429)
+const (
+	rpcCodeLimitExceeded = -32005
+	rpcCodeTooManyReqs   = 429
+)
+
+// parseRetryAfter 解析 Retry-After 响应头 (只支持秒数)
+func parseRetryAfter(h http.Header) time.Duration {
+	if h == nil {
+		return 0
+	}
+	secs, err := strconv.Atoi(h.Get("Retry-After"))
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// isRateLimitMessage 节点用普通错误返回限流时的兜底识别
+func isRateLimitMessage(msg string) bool {
+	msg = strings.ToLower(msg)
+	return strings.Contains(msg, "rate limit") || strings.Contains(msg, "too many requests") ||
+		strings.Contains(msg, "exceeded") && strings.Contains(msg, "capacity")
+}
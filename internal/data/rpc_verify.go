@@ -0,0 +1,82 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/zy99978455-otw/go-micro-template/pkg/global"
+)
+
+// errChainMismatch 节点实际服务的链与配置不符
+type errChainMismatch struct {
+	reason string
+}
+
+func (e *errChainMismatch) Error() string {
+	return e.reason
+}
+
+// verifyNode 校验节点服务的链是否与配置一致
+// 1. eth_chainId 必须等于配置的 chain_id
+// 2. 配置了 genesis_hash 时，0 号区块的哈希也必须一致
+// 返回 *errChainMismatch 表示节点配置错误 (需要隔离)，其他错误表示调用失败 (下次再试)
+func verifyNode(ctx context.Context, n *Node, client *ethclient.Client, genesisHash string) error {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return err
+	}
+	if chainID.Cmp(big.NewInt(n.ChainID)) != 0 {
+		return &errChainMismatch{reason: fmt.Sprintf("eth_chainId mismatch: configured %d, node serves %s", n.ChainID, chainID)}
+	}
+
+	if genesisHash == "" {
+		return nil
+	}
+	genesis, err := client.HeaderByNumber(ctx, big.NewInt(0))
+	if err != nil {
+		return err
+	}
+	if expected := common.HexToHash(genesisHash); genesis.Hash() != expected {
+		return &errChainMismatch{reason: fmt.Sprintf("genesis hash mismatch: configured %s, node serves %s", expected.Hex(), genesis.Hash().Hex())}
+	}
+	return nil
+}
+
+// applyVerification 根据校验结果更新节点的隔离状态
+// 返回 false 表示本次健康检查应当终止
+func (m *RPCManager) applyVerification(n *Node, err error) bool {
+	if mismatch, ok := err.(*errChainMismatch); ok {
+		n.mu.Lock()
+		changed := !n.Quarantined || n.QuarantineReason != mismatch.reason
+		n.Quarantined = true
+		n.QuarantineReason = mismatch.reason
+		n.verified = false
+		n.mu.Unlock()
+		n.window.check(false)
+
+		// 配置错误必须大声报出来，但同一原因只报一次
+		if changed && global.Log != nil {
+			global.Log.Errorf("🚫 [RPC] Node QUARANTINED: %s (chain %d), %s. Check chains[].nodes config!", n.Name, n.ChainID, mismatch.reason)
+		}
+		return false
+	}
+	if err != nil {
+		m.probeFailed(n, err)
+		return false
+	}
+
+	n.mu.Lock()
+	wasQuarantined := n.Quarantined
+	n.Quarantined = false
+	n.QuarantineReason = ""
+	n.verified = true
+	n.mu.Unlock()
+
+	if wasQuarantined && global.Log != nil {
+		global.Log.Infof("✅ [RPC] Node released from quarantine: %s (chain %d)", n.Name, n.ChainID)
+	}
+	return true
+}
@@ -0,0 +1,592 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+	"github.com/zy99978455-otw/go-micro-template/pkg/global"
+)
+
+const (
+	defaultSubBuffer    = 128              // 订阅通道默认缓冲
+	wsMinBackoff        = time.Second      // 重连退避起始值
+	wsMaxBackoff        = 30 * time.Second // 重连退避上限
+	wsDialTimeout       = 10 * time.Second
+	maxLogDedupeEntries = 10000 // 日志去重集合上限 (补齐时避免重复推送)
+
+	defaultLogBackfillRange = 1000 // 节点都没有 max_log_range 时，补齐日志每次查询的区块数
+)
+
+// Subscription 一个订阅：从 Chan() 读取事件，不再需要时调用 Unsubscribe
+// 断线重连、切换端点、补齐缺失区块都在内部完成，调用方看到的是连续的事件流
+type Subscription[T any] struct {
+	ch     chan T
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Chan 事件通道 (订阅结束后关闭)
+func (s *Subscription[T]) Chan() <-chan T {
+	return s.ch
+}
+
+// Unsubscribe 取消订阅并等待内部 goroutine 退出
+func (s *Subscription[T]) Unsubscribe() {
+	s.cancel()
+	<-s.done
+}
+
+// SubscriptionManager 基于 WSS 的订阅管理器
+// 每条链维护一个共享的 WSS 连接，支持 newHeads / logs / newPendingTransactions，
+// 断线后指数退避重连并在多个 WSS 端点间切换，重连后通过 HTTP 补齐断线期间的区块和日志
+type SubscriptionManager struct {
+	rpcMgr *RPCManager
+
+	mu     sync.Mutex
+	chains map[int64]*wsChain
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewSubscriptionManager 创建订阅管理器 (连接在第一次订阅时才建立)
+func NewSubscriptionManager(rpcMgr *RPCManager) *SubscriptionManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &SubscriptionManager{
+		rpcMgr: rpcMgr,
+		chains: make(map[int64]*wsChain),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	rpcMgr.OnNodesChanged(m.nodesChanged)
+	return m
+}
+
+// Close 结束所有订阅并关闭 WSS 连接
+func (m *SubscriptionManager) Close() {
+	m.closeOnce.Do(func() {
+		m.cancel()
+		m.wg.Wait()
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for _, c := range m.chains {
+			c.close()
+		}
+		if global.Log != nil {
+			global.Log.Info("✅ [WSS] SubscriptionManager closed")
+		}
+	})
+}
+
+// SubscribeNewHeads 订阅新区块头；重连后会按顺序补齐中间缺失的区块头
+// 区块头原样推送，重组检测见 HeadTracker
+func (m *SubscriptionManager) SubscribeNewHeads(chainID int64) (*Subscription[*types.Header], error) {
+	chain, err := m.chain(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return startSubscription(m, func(ctx context.Context, out chan<- *types.Header) {
+		m.runNewHeads(ctx, chain, out)
+	}), nil
+}
+
+// SubscribeLogs 订阅日志 (按 Addresses / Topics 过滤，ToBlock / BlockHash 被忽略)；
+// FromBlock 为起始高度：首次订阅时先用 eth_getLogs 补齐 [FromBlock, head] (如合约的部署区块，见 ContractRegistry.LogQuery)，
+// 为空时只推送订阅之后的日志；重连后会补齐断线期间的日志
+func (m *SubscriptionManager) SubscribeLogs(chainID int64, q ethereum.FilterQuery) (*Subscription[types.Log], error) {
+	chain, err := m.chain(chainID)
+	if err != nil {
+		return nil, err
+	}
+	var start uint64
+	if q.FromBlock != nil && q.FromBlock.Sign() > 0 {
+		start = q.FromBlock.Uint64()
+	}
+	q.FromBlock, q.ToBlock, q.BlockHash = nil, nil, nil
+	return startSubscription(m, func(ctx context.Context, out chan<- types.Log) {
+		m.runLogs(ctx, chain, q, start, out)
+	}), nil
+}
+
+// SubscribePendingTransactions 订阅内存池交易哈希 (断线期间的交易无法补齐)
+func (m *SubscriptionManager) SubscribePendingTransactions(chainID int64) (*Subscription[common.Hash], error) {
+	chain, err := m.chain(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return startSubscription(m, func(ctx context.Context, out chan<- common.Hash) {
+		m.runPendingTxs(ctx, chain, out)
+	}), nil
+}
+
+// startSubscription 启动订阅 goroutine
+func startSubscription[T any](m *SubscriptionManager, run func(ctx context.Context, out chan<- T)) *Subscription[T] {
+	ctx, cancel := context.WithCancel(m.ctx)
+	sub := &Subscription[T]{
+		ch:     make(chan T, defaultSubBuffer),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer close(sub.done)
+		defer close(sub.ch)
+		run(ctx, sub.ch)
+	}()
+	return sub
+}
+
+// chain 获取 (或创建) 链的 WSS 连接管理
+func (m *SubscriptionManager) chain(chainID int64) (*wsChain, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.ctx.Err() != nil {
+		return nil, fmt.Errorf("subscription manager closed")
+	}
+	if c, ok := m.chains[chainID]; ok {
+		return c, nil
+	}
+
+	if len(m.rpcMgr.wssEndpoints(chainID)) == 0 {
+		return nil, fmt.Errorf("chain %d has no wss_url configured", chainID)
+	}
+	c := &wsChain{chainID: chainID, endpoints: func() []string { return m.rpcMgr.wssEndpoints(chainID) }}
+	m.chains[chainID] = c
+	return c, nil
+}
+
+// nodesChanged 管理接口增删节点后调用：当前连接的端点已被移除时断开，订阅会重连到现有端点并补齐
+// 新增的端点在下次拨号时生效
+func (m *SubscriptionManager) nodesChanged(chainID int64) {
+	m.mu.Lock()
+	c := m.chains[chainID]
+	m.mu.Unlock()
+	if c != nil {
+		c.refresh()
+	}
+}
+
+// ================= 各类订阅的主循环 =================
+
+// subscribeLoop 通用的 "连接 -> 订阅 -> 读事件 -> 出错重连" 循环
+// subscribe 在新连接上建立订阅并阻塞读取事件，直到订阅出错或 ctx 取消
+func (m *SubscriptionManager) subscribeLoop(ctx context.Context, chain *wsChain, kind string,
+	subscribe func(ctx context.Context, client *rpc.Client) error) {
+	backoff := wsMinBackoff
+	for ctx.Err() == nil {
+		client, gen, err := chain.get(ctx)
+		if err != nil {
+			return // ctx 已取消
+		}
+
+		start := time.Now()
+		err = subscribe(ctx, client)
+		if ctx.Err() != nil {
+			return
+		}
+
+		// 订阅稳定运行过一段时间就重置退避
+		if time.Since(start) > wsMaxBackoff {
+			backoff = wsMinBackoff
+		}
+		if global.Log != nil {
+			global.Log.Warnf("⚠️ [WSS] chain %d %s subscription dropped, reconnecting in %v: %v", chain.chainID, kind, backoff, err)
+		}
+		chain.fail(gen)
+		if !sleepCtx(ctx, backoff) {
+			return
+		}
+		backoff = min(backoff*2, wsMaxBackoff)
+	}
+}
+
+func (m *SubscriptionManager) runNewHeads(ctx context.Context, chain *wsChain, out chan<- *types.Header) {
+	var last uint64 // 最后推送的区块号
+
+	m.subscribeLoop(ctx, chain, "newHeads", func(ctx context.Context, client *rpc.Client) error {
+		ch := make(chan *types.Header, defaultSubBuffer)
+		sub, err := ethclient.NewClient(client).SubscribeNewHead(ctx, ch)
+		if err != nil {
+			return err
+		}
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case err := <-sub.Err():
+				return err
+			case h := <-ch:
+				number := h.Number.Uint64()
+				// 1. 中间有缺口：先通过 HTTP 按顺序补齐
+				if last > 0 && number > last+1 {
+					if err := m.backfillHeads(ctx, chain.chainID, last+1, number-1, out); err != nil {
+						return err
+					}
+				}
+				if !send(ctx, out, h) {
+					return nil
+				}
+				last = number
+			}
+		}
+	})
+}
+
+// backfillHeads 通过 HTTP 补齐 [from, to] 区间的区块头
+func (m *SubscriptionManager) backfillHeads(ctx context.Context, chainID int64, from, to uint64, out chan<- *types.Header) error {
+	if global.Log != nil {
+		global.Log.Infof("🧩 [WSS] chain %d backfilling heads %d..%d over HTTP", chainID, from, to)
+	}
+	client := m.rpcMgr.Client(chainID)
+	for n := from; n <= to; n++ {
+		h, err := client.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+		if err != nil {
+			return fmt.Errorf("backfill head %d: %w", n, err)
+		}
+		if !send(ctx, out, h) {
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (m *SubscriptionManager) runLogs(ctx context.Context, chain *wsChain, q ethereum.FilterQuery, start uint64, out chan<- types.Log) {
+	checkpoint := start // 已经确认推送完整的区块号 (重连后从这里补齐)；有起始高度时首次订阅从起始高度补齐
+	seen := newLogDedupe(maxLogDedupeEntries)
+
+	deliver := func(ctx context.Context, l types.Log) bool {
+		if l.BlockNumber < start || !seen.add(l) {
+			return true
+		}
+		if l.BlockNumber > checkpoint && !l.Removed {
+			checkpoint = l.BlockNumber
+		}
+		return send(ctx, out, l)
+	}
+
+	m.subscribeLoop(ctx, chain, "logs", func(ctx context.Context, client *rpc.Client) error {
+		ch := make(chan types.Log, defaultSubBuffer)
+		sub, err := ethclient.NewClient(client).SubscribeFilterLogs(ctx, q, ch)
+		if err != nil {
+			return err
+		}
+		defer sub.Unsubscribe()
+
+		// 1. 订阅建立后，通过 HTTP 补齐 [checkpoint, head] 的日志 (没有起始高度的首次订阅只记录起点)
+		head, err := m.rpcMgr.Client(chain.chainID).BlockNumber(ctx)
+		if err != nil {
+			return fmt.Errorf("get head for log backfill: %w", err)
+		}
+		if checkpoint > 0 && head >= checkpoint {
+			if global.Log != nil {
+				global.Log.Infof("🧩 [WSS] chain %d backfilling logs %d..%d over HTTP", chain.chainID, checkpoint, head)
+			}
+			// 按块查询，每块推送完就推进 checkpoint：失败重连后从断点继续，不会反复重试同一个过大的区间
+			for from, step := checkpoint, m.logBackfillRange(chain.chainID); from <= head; from += step {
+				to := min(from+step-1, head)
+				bq := q
+				bq.FromBlock = new(big.Int).SetUint64(from)
+				bq.ToBlock = new(big.Int).SetUint64(to)
+				logs, err := m.rpcMgr.Client(chain.chainID).FilterLogs(ctx, bq)
+				if err != nil {
+					return fmt.Errorf("backfill logs %d..%d: %w", from, to, err)
+				}
+				for _, l := range logs {
+					if !deliver(ctx, l) {
+						return nil
+					}
+				}
+				checkpoint = max(checkpoint, to)
+			}
+		}
+		if head > checkpoint {
+			checkpoint = head
+		}
+
+		// 2. 之后的日志走 WSS 推送
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case err := <-sub.Err():
+				return err
+			case l := <-ch:
+				if !deliver(ctx, l) {
+					return nil
+				}
+			}
+		}
+	})
+}
+
+// logBackfillRange 补齐日志时单次 eth_getLogs 的区块数 (不超过节点的 max_log_range)
+func (m *SubscriptionManager) logBackfillRange(chainID int64) uint64 {
+	if limit := m.rpcMgr.logRangeLimit(chainID); limit > 0 {
+		return min(limit, defaultLogBackfillRange)
+	}
+	return defaultLogBackfillRange
+}
+
+func (m *SubscriptionManager) runPendingTxs(ctx context.Context, chain *wsChain, out chan<- common.Hash) {
+	m.subscribeLoop(ctx, chain, "newPendingTransactions", func(ctx context.Context, client *rpc.Client) error {
+		ch := make(chan common.Hash, defaultSubBuffer)
+		sub, err := client.EthSubscribe(ctx, ch, "newPendingTransactions")
+		if err != nil {
+			return err
+		}
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case err := <-sub.Err():
+				return err
+			case h := <-ch:
+				if !send(ctx, out, h) {
+					return nil
+				}
+			}
+		}
+	})
+}
+
+// ================= 单条链的 WSS 连接 =================
+
+// wsChain 一条链共享的 WSS 连接，断线后切换到下一个端点
+type wsChain struct {
+	chainID   int64
+	endpoints func() []string // 每次拨号时重新读取 (管理接口可能增删了节点)
+
+	mu      sync.Mutex
+	client  *rpc.Client
+	url     string        // 当前连接的端点
+	gen     int           // 连接代数：防止多个订阅对同一次断线重复切换端点
+	idx     int           // 下次拨号的端点 (按拨号时的端点列表取模)
+	dialing chan struct{} // 非 nil 表示有订阅正在拨号 (拨号结束时关闭)
+	closed  bool
+}
+
+// get 获取当前连接；没有连接时由一个订阅负责拨号，其他订阅等待拨号结果
+// 拨号和退避期间不持有锁，不会阻塞 fail() 和其他订阅
+func (c *wsChain) get(ctx context.Context) (*rpc.Client, int, error) {
+	for {
+		c.mu.Lock()
+		switch {
+		case c.closed:
+			c.mu.Unlock()
+			return nil, 0, fmt.Errorf("chain %d wss connection closed", c.chainID)
+		case c.client != nil:
+			client, gen := c.client, c.gen
+			c.mu.Unlock()
+			return client, gen, nil
+		case c.dialing != nil:
+			// 等待正在进行的拨号 (拨号的订阅被取消时，由等待者接手)
+			wait := c.dialing
+			c.mu.Unlock()
+			select {
+			case <-wait:
+				continue
+			case <-ctx.Done():
+				return nil, 0, ctx.Err()
+			}
+		}
+		done := make(chan struct{})
+		c.dialing = done
+		c.mu.Unlock()
+
+		client, url, err := c.dial(ctx)
+
+		c.mu.Lock()
+		c.dialing = nil
+		if err == nil {
+			if c.closed {
+				client.Close()
+			} else {
+				c.client, c.url = client, url
+			}
+		}
+		c.mu.Unlock()
+		close(done)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+}
+
+// dial 按端点顺序拨号直到成功 (失败则退避后换下一个端点)，ctx 取消时返回
+// 每次拨号前重新读取端点列表，已移除节点的端点不会再拨，新增节点的端点会加入轮换
+func (c *wsChain) dial(ctx context.Context) (*rpc.Client, string, error) {
+	backoff := wsMinBackoff
+	for {
+		endpoints := c.endpoints()
+		if len(endpoints) == 0 {
+			if global.Log != nil {
+				global.Log.Warnf("⚠️ [WSS] chain %d has no wss endpoints left, retry in %v", c.chainID, backoff)
+			}
+		} else {
+			c.mu.Lock()
+			idx := c.idx % len(endpoints)
+			c.mu.Unlock()
+
+			dialCtx, cancel := context.WithTimeout(ctx, wsDialTimeout)
+			client, err := rpc.DialContext(dialCtx, endpoints[idx])
+			cancel()
+			if err == nil {
+				if global.Log != nil {
+					global.Log.Infof("✅ [WSS] chain %d connected to endpoint #%d", c.chainID, idx)
+				}
+				return client, endpoints[idx], nil
+			}
+			if ctx.Err() != nil {
+				return nil, "", ctx.Err()
+			}
+			if global.Log != nil {
+				global.Log.Warnf("⚠️ [WSS] chain %d dial endpoint #%d failed, retry in %v: %v", c.chainID, idx, backoff, err)
+			}
+			c.mu.Lock()
+			c.idx = idx + 1
+			c.mu.Unlock()
+		}
+		if !sleepCtx(ctx, backoff) {
+			return nil, "", ctx.Err()
+		}
+		backoff = min(backoff*2, wsMaxBackoff)
+	}
+}
+
+// fail 标记 gen 代连接失效：关闭连接并切换到下一个端点
+func (c *wsChain) fail(gen int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen != c.gen || c.client == nil {
+		return
+	}
+	c.drop()
+}
+
+// refresh 端点列表变化后调用：当前连接的端点已被移除时断开连接
+func (c *wsChain) refresh() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client == nil || slices.Contains(c.endpoints(), c.url) {
+		return
+	}
+	if global.Log != nil {
+		global.Log.Warnf("⚠️ [WSS] chain %d endpoint removed, dropping its connection", c.chainID)
+	}
+	c.drop()
+}
+
+// drop 关闭当前连接，下次从当前端点的下一个开始拨号 (当前端点已移除时从头开始)；调用方持有 c.mu
+func (c *wsChain) drop() {
+	c.client.Close()
+	c.client = nil
+	c.gen++
+	c.idx = slices.Index(c.endpoints(), c.url) + 1
+	c.url = ""
+}
+
+func (c *wsChain) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	if c.client != nil {
+		c.client.Close()
+		c.client = nil
+	}
+}
+
+// wssEndpoints 该链所有配置了 wss_url 的节点 (主节点在前)
+func (m *RPCManager) wssEndpoints(chainID int64) []string {
+	m.mu.RLock()
+	nodes := m.chainNodes[chainID]
+	m.mu.RUnlock()
+
+	var primary, backup []string
+	for _, n := range nodes {
+		if n.WssURL == "" {
+			continue
+		}
+		if n.Priority == config.NodePriorityBackup {
+			backup = append(backup, n.WssURL)
+		} else {
+			primary = append(primary, n.WssURL)
+		}
+	}
+	return append(primary, backup...)
+}
+
+// ================= 工具函数 =================
+
+// logDedupe 记录最近推送过的日志 (blockHash + logIndex)，超过上限按先进先出淘汰
+type logDedupe struct {
+	limit int
+	set   map[logKey]struct{}
+	order []logKey
+}
+
+type logKey struct {
+	block   common.Hash
+	index   uint
+	removed bool
+}
+
+func newLogDedupe(limit int) *logDedupe {
+	return &logDedupe{limit: limit, set: make(map[logKey]struct{}, limit)}
+}
+
+// add 返回 false 表示已经推送过
+func (d *logDedupe) add(l types.Log) bool {
+	k := logKey{block: l.BlockHash, index: l.Index, removed: l.Removed}
+	if _, ok := d.set[k]; ok {
+		return false
+	}
+	d.set[k] = struct{}{}
+	d.order = append(d.order, k)
+	if len(d.order) > d.limit {
+		delete(d.set, d.order[0])
+		d.order = d.order[1:]
+	}
+	return true
+}
+
+// send 向订阅者推送事件 (订阅者处理慢时阻塞，ctx 取消时返回 false)
+func send[T any](ctx context.Context, out chan<- T, v T) bool {
+	select {
+	case out <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// sleepCtx 可被 ctx 打断的 sleep
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,78 @@
+package data
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newTestWSServer 假的 WSS 端点 (只需要能建立连接)
+func newTestWSServer(t *testing.T) string {
+	t.Helper()
+	rpcSrv := rpc.NewServer()
+	srv := httptest.NewServer(rpcSrv.WebsocketHandler([]string{"*"}))
+	t.Cleanup(func() {
+		srv.Close()
+		rpcSrv.Stop()
+	})
+	return "ws://" + strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestWSChainFollowsEndpointChanges(t *testing.T) {
+	a, b, c := newTestWSServer(t), newTestWSServer(t), newTestWSServer(t)
+
+	var mu sync.Mutex
+	endpoints := []string{a, b}
+	chain := &wsChain{chainID: 1, endpoints: func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), endpoints...)
+	}}
+	defer chain.close()
+	setEndpoints := func(urls ...string) {
+		mu.Lock()
+		endpoints = urls
+		mu.Unlock()
+		chain.refresh()
+	}
+	connected := func() string {
+		t.Helper()
+		if _, _, err := chain.get(context.Background()); err != nil {
+			t.Fatalf("get() error: %v", err)
+		}
+		chain.mu.Lock()
+		defer chain.mu.Unlock()
+		return chain.url
+	}
+
+	steps := []struct {
+		name      string
+		endpoints []string // nil 表示不变
+		fail      bool     // 模拟断线
+		want      string
+	}{
+		{name: "first endpoint", want: a},
+		{name: "unrelated endpoint added keeps connection", endpoints: []string{a, b, c}, want: a},
+		{name: "failover to next endpoint", fail: true, want: b},
+		{name: "connected endpoint removed", endpoints: []string{a, c}, want: a},
+		{name: "removed endpoint never dialled", endpoints: []string{c}, want: c},
+	}
+	for _, s := range steps {
+		if s.endpoints != nil {
+			setEndpoints(s.endpoints...)
+		}
+		if s.fail {
+			chain.mu.Lock()
+			gen := chain.gen
+			chain.mu.Unlock()
+			chain.fail(gen)
+		}
+		if got := connected(); got != s.want {
+			t.Fatalf("%s: connected to %s, want %s", s.name, got, s.want)
+		}
+	}
+}
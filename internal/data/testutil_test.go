@@ -0,0 +1,94 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+)
+
+// rpcHandler 处理一个 JSON-RPC 调用；返回 handled = false 时使用默认应答
+type rpcHandler func(method string, params []json.RawMessage) (result any, err *rpcTestError, handled bool)
+
+type rpcTestError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// newTestRPCServer 假的 JSON-RPC 节点 (支持 batch)：eth_chainId 返回 chainID，eth_blockNumber 默认返回 0x1，其余默认返回 null
+func newTestRPCServer(t *testing.T, chainID int64, handle rpcHandler) *httptest.Server {
+	t.Helper()
+	answer := func(m jsonrpcMessage) any {
+		var params []json.RawMessage
+		_ = json.Unmarshal(m.Params, &params)
+		resp := map[string]any{"jsonrpc": "2.0", "id": m.ID}
+		if handle != nil {
+			if result, rpcErr, ok := handle(m.Method, params); ok {
+				if rpcErr != nil {
+					resp["error"] = rpcErr
+				} else {
+					resp["result"] = result
+				}
+				return resp
+			}
+		}
+		switch m.Method {
+		case "eth_chainId":
+			resp["result"] = fmt.Sprintf("0x%x", chainID)
+		case "eth_blockNumber":
+			resp["result"] = "0x1"
+		default:
+			resp["result"] = nil
+		}
+		return resp
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if body = bytes.TrimSpace(body); len(body) > 0 && body[0] == '[' {
+			var batch []jsonrpcMessage
+			_ = json.Unmarshal(body, &batch)
+			out := make([]any, 0, len(batch))
+			for _, m := range batch {
+				out = append(out, answer(m))
+			}
+			_ = json.NewEncoder(w).Encode(out)
+			return
+		}
+		var m jsonrpcMessage
+		_ = json.Unmarshal(body, &m)
+		_ = json.NewEncoder(w).Encode(answer(m))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// startTestManager 启动只有一条链的 RPCManager，等所有节点通过启动校验后返回
+func startTestManager(t *testing.T, chain config.ChainConfig) *RPCManager {
+	t.Helper()
+	mgr := NewRPCManager(&config.AppConfig{Chains: []config.ChainConfig{chain}})
+	ctx, cancel := context.WithCancel(context.Background())
+	mgr.Start(ctx)
+	t.Cleanup(func() {
+		cancel()
+		mgr.Close()
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for _, n := range mgr.chainNodes[chain.ChainID] {
+		for !n.available() {
+			if time.Now().After(deadline) {
+				t.Fatalf("node %s did not become available", n.Name)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	return mgr
+}
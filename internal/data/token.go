@@ -0,0 +1,140 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ERC-20 元数据方法选择器
+var (
+	selectorName     = common.FromHex("0x06fdde03") // name()
+	selectorSymbol   = common.FromHex("0x95d89b41") // symbol()
+	selectorDecimals = common.FromHex("0x313ce567") // decimals()
+	selectorBalance  = common.FromHex("0x70a08231") // balanceOf(address)
+)
+
+const (
+	defaultNativeSymbol = "ETH"
+	nativeDecimals      = 18
+)
+
+// TokenMetadata ERC-20 Token 元数据 (不可变，长期缓存)
+type TokenMetadata struct {
+	Address  string `json:"address"`
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Decimals uint8  `json:"decimals"`
+}
+
+// TokenMetadata 查询 ERC-20 的 name / symbol / decimals
+// decimals 调用失败或返回值不合法 (不是 ABI 编码的整数或超过 255) 说明不是 ERC-20，直接返回错误 (不缓存)；
+// name / symbol 是可选方法，失败时留空 (可能只是节点临时出错，见 ChainCache.token)
+func (c *ChainClient) TokenMetadata(ctx context.Context, token common.Address) (*TokenMetadata, error) {
+	return c.cache.token(ctx, c.chainID, token, func() (*TokenMetadata, error) {
+		out, err := c.CallContract(ctx, ethereum.CallMsg{To: &token, Data: selectorDecimals}, nil)
+		if err != nil {
+			return nil, err
+		}
+		decimals, err := decodeDecimals(out)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not an ERC-20 token: %w", token.Hex(), err)
+		}
+
+		meta := &TokenMetadata{Address: token.Hex(), Decimals: decimals}
+		if out, err := c.CallContract(ctx, ethereum.CallMsg{To: &token, Data: selectorName}, nil); err == nil {
+			meta.Name = decodeABIString(out)
+		}
+		if out, err := c.CallContract(ctx, ethereum.CallMsg{To: &token, Data: selectorSymbol}, nil); err == nil {
+			meta.Symbol = decodeABIString(out)
+		}
+		return meta, nil
+	})
+}
+
+// TokenBalance 查询 ERC-20 balanceOf(account)；blockNumber 为 nil 表示 latest
+func (c *ChainClient) TokenBalance(ctx context.Context, token, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return tokenBalance(ctx, c.CallContract, token, account, blockNumber)
+}
+
+// tokenBalance 通过 call (普通读或仲裁读) 调用 balanceOf(account)
+func tokenBalance(ctx context.Context, call func(context.Context, ethereum.CallMsg, *big.Int) ([]byte, error),
+	token, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	data := append(append([]byte{}, selectorBalance...), common.LeftPadBytes(account.Bytes(), 32)...)
+	out, err := call(ctx, ethereum.CallMsg{To: &token, Data: data}, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) < 32 {
+		return nil, fmt.Errorf("%s is not an ERC-20 token: empty balanceOf()", token.Hex())
+	}
+	return new(big.Int).SetBytes(out[:32]), nil
+}
+
+// NativeSymbol 原生币符号 (chains[].symbol，默认 ETH)
+func (c *ChainClient) NativeSymbol() string {
+	c.mgr.mu.RLock()
+	defer c.mgr.mu.RUnlock()
+	if s := c.mgr.chains[c.chainID].Symbol; s != "" {
+		return s
+	}
+	return defaultNativeSymbol
+}
+
+// formatUnits 把最小单位的数值按 decimals 换算成十进制字符串，例如 (1500000, 6) -> "1.5"
+func formatUnits(v *big.Int, decimals uint8) string {
+	if decimals == 0 {
+		return v.String()
+	}
+	digits := new(big.Int).Abs(v).String()
+	d := int(decimals)
+	if len(digits) <= d {
+		digits = strings.Repeat("0", d-len(digits)+1) + digits
+	}
+
+	out := digits[:len(digits)-d]
+	if frac := strings.TrimRight(digits[len(digits)-d:], "0"); frac != "" {
+		out += "." + frac
+	}
+	if v.Sign() < 0 {
+		out = "-" + out
+	}
+	return out
+}
+
+// decodeDecimals 解码 decimals() 返回值：按 uint256 解码 (部分 Token 声明为 uint256)，超过 255 视为不合法
+func decodeDecimals(out []byte) (uint8, error) {
+	if len(out) == 0 {
+		return 0, fmt.Errorf("empty decimals()")
+	}
+	uintType, _ := abi.NewType("uint256", "", nil)
+	values, err := (abi.Arguments{{Type: uintType}}).Unpack(out)
+	if err != nil || len(values) != 1 {
+		return 0, fmt.Errorf("malformed decimals() 0x%x", out)
+	}
+	v, ok := values[0].(*big.Int)
+	if !ok || !v.IsUint64() || v.Uint64() > 255 {
+		return 0, fmt.Errorf("decimals() %v out of range", values[0])
+	}
+	return uint8(v.Uint64()), nil
+}
+
+// decodeABIString 解码 string 返回值；兼容早期 Token (如 MKR) 返回 bytes32 的写法
+func decodeABIString(out []byte) string {
+	stringType, _ := abi.NewType("string", "", nil)
+	if values, err := (abi.Arguments{{Type: stringType}}).Unpack(out); err == nil && len(values) == 1 {
+		if s, ok := values[0].(string); ok {
+			return s
+		}
+	}
+	if len(out) == 32 {
+		return string(bytes.TrimRight(out, "\x00"))
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package data
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		value    string
+		decimals uint8
+		want     string
+	}{
+		{"0", 18, "0"},
+		{"0", 0, "0"},
+		{"12345", 0, "12345"},
+		{"1500000", 6, "1.5"},
+		{"1000000", 6, "1"},
+		{"1", 6, "0.000001"},
+		{"123", 2, "1.23"},
+		{"100", 3, "0.1"},
+		{"1000000000000000000", 18, "1"},
+		{"1234567890000000000000", 18, "1234.56789"},
+		{"-1500000", 6, "-1.5"},
+		{"-1", 2, "-0.01"},
+	}
+	for _, tt := range tests {
+		v, _ := new(big.Int).SetString(tt.value, 10)
+		if got := formatUnits(v, tt.decimals); got != tt.want {
+			t.Errorf("formatUnits(%s, %d) = %q, want %q", tt.value, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeDecimals(t *testing.T) {
+	word := func(v int64) []byte { return common.LeftPadBytes(big.NewInt(v).Bytes(), 32) }
+	dirty := word(6)
+	dirty[0] = 1 // 高位非零：uint256 远超 255
+
+	tests := []struct {
+		name    string
+		out     []byte
+		want    uint8
+		wantErr bool
+	}{
+		{name: "18", out: word(18), want: 18},
+		{name: "0", out: word(0), want: 0},
+		{name: "255", out: word(255), want: 255},
+		{name: "256", out: word(256), wantErr: true},
+		{name: "upper bytes set", out: dirty, wantErr: true},
+		{name: "empty", out: nil, wantErr: true},
+		{name: "short", out: []byte{18}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeDecimals(tt.out)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("decodeDecimals() = %d, want error", got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("decodeDecimals() = %d, %v, want %d", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zy99978455-otw/go-micro-template/internal/data"
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+	"github.com/zy99978455-otw/go-micro-template/pkg/response"
+)
+
+const (
+	removeNodeTimeout = 30 * time.Second   // 移除节点时等待进行中请求完成的最长时间
+	defaultSLORange   = 7 * 24 * time.Hour // SLO 统计默认最近 7 天
+)
+
+// AdminHandler RPC 节点运行时管理接口
+type AdminHandler struct {
+	rpcMgr  *data.RPCManager
+	history *data.NodeHistory // MySQL 未配置时为 nil
+}
+
+// NewAdminHandler 构造函数
+func NewAdminHandler(rpcMgr *data.RPCManager, history *data.NodeHistory) *AdminHandler {
+	return &AdminHandler{rpcMgr: rpcMgr, history: history}
+}
+
+// adminAuth 管理接口鉴权：Authorization: Bearer <token> 或 X-Admin-Token: <token>
+func adminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		got := c.GetHeader("X-Admin-Token")
+		if auth := c.GetHeader("Authorization"); got == "" && strings.HasPrefix(auth, "Bearer ") {
+			got = strings.TrimPrefix(auth, "Bearer ")
+		}
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			response.Result(c, http.StatusUnauthorized, http.StatusUnauthorized, "unauthorized", nil)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ListNodes GET /admin/rpc/chains/:chain_id/nodes
+// 节点列表：健康、延迟、高度、错误数、熔断、排空、能力、额度
+func (h *AdminHandler) ListNodes(c *gin.Context) {
+	chainID, ok := chainIDParam(c)
+	if !ok {
+		return
+	}
+	nodes, err := h.rpcMgr.ListNodes(chainID)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	response.Success(c, nodes)
+}
+
+// AddNode POST /admin/rpc/chains/:chain_id/nodes
+// 请求体与配置文件中的 chains[].nodes[] 相同 (JSON)
+func (h *AdminHandler) AddNode(c *gin.Context) {
+	chainID, ok := chainIDParam(c)
+	if !ok {
+		return
+	}
+	var req config.NodeConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Result(c, http.StatusBadRequest, response.ERROR, err.Error(), nil)
+		return
+	}
+
+	status, err := h.rpcMgr.AddNode(c.Request.Context(), chainID, req)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	response.Success(c, status)
+}
+
+// Drain POST /admin/rpc/chains/:chain_id/nodes/:name/drain
+func (h *AdminHandler) Drain(c *gin.Context) {
+	h.setDraining(c, true)
+}
+
+// Undrain DELETE /admin/rpc/chains/:chain_id/nodes/:name/drain
+func (h *AdminHandler) Undrain(c *gin.Context) {
+	h.setDraining(c, false)
+}
+
+func (h *AdminHandler) setDraining(c *gin.Context, draining bool) {
+	chainID, ok := chainIDParam(c)
+	if !ok {
+		return
+	}
+	status, err := h.rpcMgr.SetDraining(chainID, c.Param("name"), draining)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	response.Success(c, status)
+}
+
+// CheckNode POST /admin/rpc/chains/:chain_id/nodes/:name/check
+func (h *AdminHandler) CheckNode(c *gin.Context) {
+	chainID, ok := chainIDParam(c)
+	if !ok {
+		return
+	}
+	status, err := h.rpcMgr.CheckNode(c.Request.Context(), chainID, c.Param("name"))
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	response.Success(c, status)
+}
+
+// CheckChain POST /admin/rpc/chains/:chain_id/check
+func (h *AdminHandler) CheckChain(c *gin.Context) {
+	chainID, ok := chainIDParam(c)
+	if !ok {
+		return
+	}
+	statuses, err := h.rpcMgr.CheckChain(c.Request.Context(), chainID)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	response.Success(c, statuses)
+}
+
+// RemoveNode DELETE /admin/rpc/chains/:chain_id/nodes/:name
+// 先摘除再等待进行中的请求完成 (最多 removeNodeTimeout)，最后关闭连接
+func (h *AdminHandler) RemoveNode(c *gin.Context) {
+	chainID, ok := chainIDParam(c)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(c.Request.Context(), removeNodeTimeout)
+	defer cancel()
+
+	if err := h.rpcMgr.RemoveNode(ctx, chainID, c.Param("name")); err != nil {
+		adminError(c, err)
+		return
+	}
+	nodes, err := h.rpcMgr.ListNodes(chainID)
+	if err != nil {
+		adminError(c, err)
+		return
+	}
+	response.Success(c, nodes)
+}
+
+// ProviderSLO GET /admin/rpc/slo?from=&to=&chain_id=&latency_target_ms=
+// 按服务商统计时间范围内的可用率、错误率和延迟 (from / to 为 RFC3339，默认最近 7 天)
+func (h *AdminHandler) ProviderSLO(c *gin.Context) {
+	if h.history == nil {
+		response.Result(c, http.StatusServiceUnavailable, response.ERROR, "node history is disabled (mysql not configured)", nil)
+		return
+	}
+
+	// 1. 解析参数
+	q := data.SLOQuery{To: time.Now()}
+	var err error
+	if v := c.Query("to"); v != "" {
+		if q.To, err = time.Parse(time.RFC3339, v); err != nil {
+			response.Result(c, http.StatusBadRequest, response.ERROR, "invalid to (RFC3339)", nil)
+			return
+		}
+	}
+	q.From = q.To.Add(-defaultSLORange)
+	if v := c.Query("from"); v != "" {
+		if q.From, err = time.Parse(time.RFC3339, v); err != nil {
+			response.Result(c, http.StatusBadRequest, response.ERROR, "invalid from (RFC3339)", nil)
+			return
+		}
+	}
+	if !q.From.Before(q.To) {
+		response.Result(c, http.StatusBadRequest, response.ERROR, "from must be before to", nil)
+		return
+	}
+	q.ChainID, _ = strconv.ParseInt(c.Query("chain_id"), 10, 64)
+	q.LatencyTargetMs, _ = strconv.ParseFloat(c.Query("latency_target_ms"), 64)
+
+	// 2. 查询
+	slos, err := h.history.ProviderSLO(c.Request.Context(), q)
+	if err != nil {
+		response.Result(c, http.StatusInternalServerError, response.ERROR, err.Error(), nil)
+		return
+	}
+	response.Success(c, gin.H{
+		"from":      q.From,
+		"to":        q.To,
+		"chain_id":  q.ChainID,
+		"providers": slos,
+	})
+}
+
+// chainIDParam 解析路径中的 chain_id
+func chainIDParam(c *gin.Context) (int64, bool) {
+	chainID, err := strconv.ParseInt(c.Param("chain_id"), 10, 64)
+	if err != nil || chainID <= 0 {
+		response.Result(c, http.StatusBadRequest, response.ERROR, "invalid chain_id", nil)
+		return 0, false
+	}
+	return chainID, true
+}
+
+// adminError 管理操作错误 -> HTTP 状态码
+func adminError(c *gin.Context, err error) {
+	httpCode := http.StatusBadRequest
+	switch {
+	case errors.Is(err, data.ErrChainNotFound), errors.Is(err, data.ErrNodeNotFound):
+		httpCode = http.StatusNotFound
+	case errors.Is(err, data.ErrNodeExists), errors.Is(err, data.ErrLastNode):
+		httpCode = http.StatusConflict
+	}
+	response.Result(c, httpCode, response.ERROR, err.Error(), nil)
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zy99978455-otw/go-micro-template/internal/biz"
+)
+
+// BalanceHandler 余额查询
+type BalanceHandler struct {
+	uc *biz.ChainUsecase
+}
+
+// NewBalanceHandler 构造函数
+func NewBalanceHandler(uc *biz.ChainUsecase) *BalanceHandler {
+	return &BalanceHandler{uc: uc}
+}
+
+// GetBalance 处理 GET /api/v1/web3/balance?chain_id=&address=&tokens=&block=&quorum=
+// tokens 为逗号分隔的 ERC-20 地址 (也可以重复传 tokens=)；block 为区块号或 latest / safe / finalized 等标签
+// quorum=true 时余额走仲裁读 (多个独立服务商一致才返回，否则 503)
+func (h *BalanceHandler) GetBalance(c *gin.Context) {
+	// 1. 解析参数
+	var tokens []string
+	for _, v := range c.QueryArray("tokens") {
+		for _, t := range strings.Split(v, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				tokens = append(tokens, t)
+			}
+		}
+	}
+
+	quorum, _ := strconv.ParseBool(c.Query("quorum"))
+
+	// 2. 调用业务逻辑 (Biz)
+	balances, err := h.uc.GetBalances(c.Request.Context(), biz.BalanceQuery{
+		ChainID: queryChainID(c),
+		Address: c.Query("address"),
+		Tokens:  tokens,
+		Block:   c.Query("block"),
+		Quorum:  quorum,
+	})
+
+	// 3. 处理错误
+	if err != nil {
+		web3Error(c, err)
+		return
+	}
+
+	// 4. 返回成功响应
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": balances,
+	})
+}
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -29,7 +31,7 @@ func (h *ChainHandler) GetBlock(c *gin.Context) {
 	}
 
 	// 2. 调用业务逻辑 (Biz)
-	height, err := h.uc.GetCurrentHeight(c.Request.Context(), chainID)
+	heights, err := h.uc.GetBlockHeights(c.Request.Context(), chainID)
 	
 	// 3. 处理错误
 	if err != nil {
@@ -40,12 +42,68 @@ func (h *ChainHandler) GetBlock(c *gin.Context) {
 		return
 	}
 
-	// 4. 返回成功响应
+	// 4. 返回成功响应 (height 与 latest 相同，保留给老调用方)
 	c.JSON(http.StatusOK, gin.H{
 		"code": 200,
 		"data": gin.H{
-			"chain_id": chainID,
-			"height":   height,
+			"chain_id":      chainID,
+			"height":        heights.Latest,
+			"latest":        heights.Latest,
+			"safe":          heights.Safe,
+			"finalized":     heights.Finalized,
+			"confirmed":     heights.Confirmed,
+			"confirmations": heights.Confirmations,
+			"source":        heights.Source,
 		},
 	})
 }
+
+// GetBlockDetail 处理 GET /api/v1/web3/block/:id?chain_id=&full_tx=&page=&page_size=
+// id 为区块号 (十进制 / 0x 十六进制)、区块哈希或 latest / safe / finalized 等标签
+func (h *ChainHandler) GetBlockDetail(c *gin.Context) {
+	// 1. 解析参数
+	q := biz.BlockQuery{ChainID: queryChainID(c), Block: c.Param("id")}
+	q.FullTx, _ = strconv.ParseBool(c.Query("full_tx"))
+	for name, dst := range map[string]*int{"page": &q.Page, "page_size": &q.PageSize} {
+		v := c.Query(name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			web3Error(c, fmt.Errorf("%w: %s must be an integer", biz.ErrInvalidArgument, name))
+			return
+		}
+		*dst = n
+	}
+
+	// 2. 调用业务逻辑 (Biz)
+	block, err := h.uc.GetBlock(c.Request.Context(), q)
+	if err != nil {
+		web3Error(c, err)
+		return
+	}
+
+	// 3. 返回成功响应
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": block,
+	})
+}
+
+// web3Error 业务错误 -> HTTP 响应 (参数错误 400，不存在 404，其余 500)
+func web3Error(c *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, biz.ErrInvalidArgument):
+		code = http.StatusBadRequest
+	case errors.Is(err, biz.ErrNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, biz.ErrQuorumNotReached):
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{
+		"code": code,
+		"msg":  err.Error(),
+	})
+}
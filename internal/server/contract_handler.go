@@ -0,0 +1,78 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zy99978455-otw/go-micro-template/internal/biz"
+)
+
+// ContractHandler 合约注册表与只读方法调用
+type ContractHandler struct {
+	uc *biz.ChainUsecase
+}
+
+// NewContractHandler 构造函数
+func NewContractHandler(uc *biz.ChainUsecase) *ContractHandler {
+	return &ContractHandler{uc: uc}
+}
+
+// contractCallRequest POST /api/v1/web3/contracts/:name/call 的请求体
+type contractCallRequest struct {
+	ChainID int64           `json:"chain_id"` // 默认 1
+	Method  string          `json:"method" binding:"required"`
+	Args    json.RawMessage `json:"args"`   // JSON 数组，按 ABI 转换
+	Block   string          `json:"block"`  // 区块号或标签，默认 latest
+	Quorum  bool            `json:"quorum"` // 仲裁读 (多个独立服务商一致才返回)
+}
+
+// ListContracts 处理 GET /api/v1/web3/contracts
+func (h *ContractHandler) ListContracts(c *gin.Context) {
+	contracts, err := h.uc.ListContracts(c.Request.Context())
+	if err != nil {
+		web3Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": contracts,
+	})
+}
+
+// Call 处理 POST /api/v1/web3/contracts/:name/call
+// 例如 {"chain_id":1,"method":"balanceOf","args":["0x..."]}，返回按 ABI 解码的返回值
+func (h *ContractHandler) Call(c *gin.Context) {
+	// 1. 解析参数
+	var req contractCallRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": http.StatusBadRequest,
+			"msg":  err.Error(),
+		})
+		return
+	}
+	if req.ChainID == 0 {
+		req.ChainID = 1
+	}
+
+	// 2. 调用业务逻辑 (Biz)
+	result, err := h.uc.CallContract(c.Request.Context(), biz.ContractCallQuery{
+		ChainID:  req.ChainID,
+		Contract: c.Param("name"),
+		Method:   req.Method,
+		Args:     req.Args,
+		Block:    req.Block,
+		Quorum:   req.Quorum,
+	})
+	if err != nil {
+		web3Error(c, err)
+		return
+	}
+
+	// 3. 返回成功响应
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": result,
+	})
+}
@@ -0,0 +1,265 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/zy99978455-otw/go-micro-template/api/proto"
+	"github.com/zy99978455-otw/go-micro-template/internal/biz"
+	"github.com/zy99978455-otw/go-micro-template/internal/data"
+	"github.com/zy99978455-otw/go-micro-template/pkg/grpc_server"
+)
+
+// NewGRPCServer 组装 gRPC 服务，返回交给 grpc_server.Run 的注册回调
+func NewGRPCServer(dataModule *data.Data) grpc_server.RegisterFn {
+	// data -> biz -> service (与 HTTP 共用 biz 层)
+	chainUseCase := biz.NewChainUsecase(data.NewChainRepo(dataModule))
+	web3Service := NewWeb3Service(chainUseCase)
+
+	return func(s *grpc.Server) {
+		pb.RegisterWeb3ServiceServer(s, web3Service)
+	}
+}
+
+// Web3Service 实现 pb.Web3ServiceServer
+type Web3Service struct {
+	pb.UnimplementedWeb3ServiceServer
+	uc *biz.ChainUsecase
+}
+
+// NewWeb3Service 构造函数
+func NewWeb3Service(uc *biz.ChainUsecase) *Web3Service {
+	return &Web3Service{uc: uc}
+}
+
+// GetBlockHeight 返回 latest / safe / finalized / N 确认高度
+func (s *Web3Service) GetBlockHeight(ctx context.Context, req *pb.GetBlockHeightRequest) (*pb.GetBlockHeightResponse, error) {
+	chainID := grpcChainID(req.GetChainId())
+	heights, err := s.uc.GetBlockHeights(ctx, chainID)
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	return &pb.GetBlockHeightResponse{
+		ChainId:       chainID,
+		Height:        int64(heights.Latest),
+		Latest:        heights.Latest,
+		Safe:          heights.Safe,
+		Finalized:     heights.Finalized,
+		Confirmed:     heights.Confirmed,
+		Confirmations: heights.Confirmations,
+		Source:        heights.Source,
+	}, nil
+}
+
+// GetBalance 原生币与 ERC-20 余额
+func (s *Web3Service) GetBalance(ctx context.Context, req *pb.GetBalanceRequest) (*pb.GetBalanceResponse, error) {
+	balances, err := s.uc.GetBalances(ctx, biz.BalanceQuery{
+		ChainID: grpcChainID(req.GetChainId()),
+		Address: req.GetAddress(),
+		Tokens:  req.GetTokens(),
+		Block:   req.GetBlock(),
+		Quorum:  req.GetQuorum(),
+	})
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	resp := &pb.GetBalanceResponse{
+		ChainId: balances.ChainID,
+		Address: balances.Address,
+		Block:   balances.Block,
+		Native:  toPBBalance(*balances.Native),
+		Tokens:  make([]*pb.Balance, 0, len(balances.Tokens)),
+	}
+	for _, b := range balances.Tokens {
+		resp.Tokens = append(resp.Tokens, toPBBalance(b))
+	}
+	return resp, nil
+}
+
+func toPBBalance(b biz.Balance) *pb.Balance {
+	return &pb.Balance{
+		Token:     b.Token,
+		Name:      b.Name,
+		Symbol:    b.Symbol,
+		Decimals:  uint32(b.Decimals),
+		Raw:       b.Raw,
+		Formatted: b.Formatted,
+		Error:     b.Error,
+	}
+}
+
+// GetTransaction 交易及其回执
+func (s *Web3Service) GetTransaction(ctx context.Context, req *pb.GetTransactionRequest) (*pb.Transaction, error) {
+	tx, err := s.uc.GetTransaction(ctx, grpcChainID(req.GetChainId()), req.GetHash())
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return toPBTransaction(tx), nil
+}
+
+// GetTransactionReceipt 交易回执
+func (s *Web3Service) GetTransactionReceipt(ctx context.Context, req *pb.GetTransactionRequest) (*pb.Receipt, error) {
+	r, err := s.uc.GetReceipt(ctx, grpcChainID(req.GetChainId()), req.GetHash())
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+	return toPBReceipt(r), nil
+}
+
+// GetBlock 区块头与 (分页的) 交易列表
+func (s *Web3Service) GetBlock(ctx context.Context, req *pb.GetBlockRequest) (*pb.Block, error) {
+	block, err := s.uc.GetBlock(ctx, biz.BlockQuery{
+		ChainID:  grpcChainID(req.GetChainId()),
+		Block:    req.GetBlock(),
+		FullTx:   req.GetFullTx(),
+		Page:     int(req.GetPage()),
+		PageSize: int(req.GetPageSize()),
+	})
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	out := &pb.Block{
+		ChainId:       block.ChainID,
+		Number:        block.Number,
+		Hash:          block.Hash,
+		ParentHash:    block.ParentHash,
+		Timestamp:     block.Timestamp,
+		Miner:         block.Miner,
+		GasUsed:       block.GasUsed,
+		GasLimit:      block.GasLimit,
+		BaseFeePerGas: block.BaseFeePerGas,
+		BlobGasUsed:   block.BlobGasUsed,
+		ExcessBlobGas: block.ExcessBlobGas,
+		Size:          block.Size,
+		TxCount:       int32(block.TxCount),
+		Confirmations: block.Confirmations,
+		Finalized:     block.Finalized,
+		Page:          int32(block.Page),
+		PageSize:      int32(block.PageSize),
+		TxHashes:      block.TxHashes,
+	}
+	for i := range block.Transactions {
+		out.Transactions = append(out.Transactions, toPBTransaction(&block.Transactions[i]))
+	}
+	return out, nil
+}
+
+// CallContract 按合约别名 + 方法名调用只读方法
+func (s *Web3Service) CallContract(ctx context.Context, req *pb.CallContractRequest) (*pb.CallContractResponse, error) {
+	result, err := s.uc.CallContract(ctx, biz.ContractCallQuery{
+		ChainID:  grpcChainID(req.GetChainId()),
+		Contract: req.GetContract(),
+		Method:   req.GetMethod(),
+		Args:     json.RawMessage(req.GetArgsJson()),
+		Block:    req.GetBlock(),
+		Quorum:   req.GetQuorum(),
+	})
+	if err != nil {
+		return nil, grpcError(ctx, err)
+	}
+
+	out := &pb.CallContractResponse{
+		ChainId:  result.ChainID,
+		Contract: result.Contract,
+		Address:  result.Address,
+		Method:   result.Method,
+		Block:    result.Block,
+		Outputs:  make([]*pb.ContractOutput, 0, len(result.Outputs)),
+	}
+	for _, o := range result.Outputs {
+		value, _ := json.Marshal(o.Value)
+		out.Outputs = append(out.Outputs, &pb.ContractOutput{Name: o.Name, Type: o.Type, ValueJson: string(value)})
+	}
+	return out, nil
+}
+
+func toPBTransaction(tx *biz.Transaction) *pb.Transaction {
+	out := &pb.Transaction{
+		ChainId:              tx.ChainID,
+		Hash:                 tx.Hash,
+		Type:                 uint32(tx.Type),
+		From:                 tx.From,
+		To:                   tx.To,
+		Nonce:                tx.Nonce,
+		Value:                tx.Value,
+		Input:                tx.Input,
+		Gas:                  tx.Gas,
+		GasPrice:             tx.GasPrice,
+		MaxFeePerGas:         tx.MaxFeePerGas,
+		MaxPriorityFeePerGas: tx.MaxPriorityFeePerGas,
+		Pending:              tx.Pending,
+	}
+	if tx.Receipt != nil {
+		out.Receipt = toPBReceipt(tx.Receipt)
+	}
+	return out
+}
+
+func toPBReceipt(r *biz.Receipt) *pb.Receipt {
+	out := &pb.Receipt{
+		TxHash:            r.TxHash,
+		Status:            r.Status,
+		Success:           r.Success,
+		BlockNumber:       r.BlockNumber,
+		BlockHash:         r.BlockHash,
+		TransactionIndex:  uint32(r.TransactionIndex),
+		GasUsed:           r.GasUsed,
+		CumulativeGasUsed: r.CumulativeGasUsed,
+		EffectiveGasPrice: r.EffectiveGasPrice,
+		BlobGasUsed:       r.BlobGasUsed,
+		BlobGasPrice:      r.BlobGasPrice,
+		Fee:               r.Fee,
+		ContractAddress:   r.ContractAddress,
+		Logs:              make([]*pb.Log, 0, len(r.Logs)),
+		Confirmations:     r.Confirmations,
+		Finalized:         r.Finalized,
+	}
+	for _, l := range r.Logs {
+		pl := &pb.Log{
+			Index:   uint32(l.Index),
+			Address: l.Address,
+			Topics:  l.Topics,
+			Data:    l.Data,
+			Removed: l.Removed,
+		}
+		if d := l.Decoded; d != nil {
+			args, _ := json.Marshal(d.Args)
+			pl.Decoded = &pb.DecodedLog{Contract: d.Contract, Event: d.Event, Signature: d.Signature, ArgsJson: string(args)}
+		}
+		out.Logs = append(out.Logs, pl)
+	}
+	return out
+}
+
+// grpcChainID chain_id 默认 1，与 HTTP 接口一致
+func grpcChainID(chainID int64) int64 {
+	if chainID == 0 {
+		return 1
+	}
+	return chainID
+}
+
+// grpcError 业务错误 -> gRPC 状态码
+func grpcError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, biz.ErrInvalidArgument):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, biz.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, biz.ErrQuorumNotReached):
+		return status.Error(codes.Unavailable, err.Error())
+	case ctx.Err() == context.DeadlineExceeded:
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case ctx.Err() == context.Canceled:
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
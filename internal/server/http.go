@@ -1,13 +1,20 @@
 package server
 
 import (
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/zy99978455-otw/go-micro-template/internal/biz"
 	"github.com/zy99978455-otw/go-micro-template/internal/data"
+	"github.com/zy99978455-otw/go-micro-template/pkg/config"
+	"github.com/zy99978455-otw/go-micro-template/pkg/global"
 )
 
 // NewHTTPServer 初始化 HTTP 服务器
-func NewHTTPServer(dataModule *data.Data) *gin.Engine {
+func NewHTTPServer(conf *config.AppConfig, dataModule *data.Data) *gin.Engine {
 	
 	// 1. 组装 (Wiring)
 	// data -> biz
@@ -15,6 +22,10 @@ func NewHTTPServer(dataModule *data.Data) *gin.Engine {
 	// biz -> handler (直接在 server 包内实例化 handler)
 	chainUseCase := biz.NewChainUsecase(chainRepo)
 	chainHandler := NewChainHandler(chainUseCase)
+	balanceHandler := NewBalanceHandler(chainUseCase)
+	txHandler := NewTxHandler(chainUseCase)
+	contractHandler := NewContractHandler(chainUseCase)
+	adminHandler := NewAdminHandler(dataModule.GetRPCManager(), dataModule.GetNodeHistory())
 
 	// 2. 路由
 	r := gin.Default()
@@ -23,14 +34,55 @@ func NewHTTPServer(dataModule *data.Data) *gin.Engine {
     r.GET("/health", func(c *gin.Context) {
         c.JSON(200, gin.H{"status": "UP"})
     })
+
+	// 📈 Prometheus 指标 (RPC 层 + Go 运行时)
+	r.GET("/metrics", gin.WrapH(newMetricsHandler(dataModule)))
 	
 	v1 := r.Group("/api/v1")
 	{
 		web3 := v1.Group("/web3")
 		{
 			web3.GET("/block", chainHandler.GetBlock)
+			web3.GET("/block/:id", chainHandler.GetBlockDetail)
+			web3.GET("/balance", balanceHandler.GetBalance)
+			web3.GET("/tx/:hash", txHandler.GetTransaction)
+			web3.GET("/tx/:hash/receipt", txHandler.GetReceipt)
+			web3.GET("/contracts", contractHandler.ListContracts)
+			web3.POST("/contracts/:name/call", contractHandler.Call)
+		}
+	}
+
+	// 🔐 管理接口：未配置 server.admin.token 时不注册
+	if conf != nil && conf.Server.Admin.Token != "" {
+		admin := r.Group("/admin", adminAuth(conf.Server.Admin.Token))
+		{
+			admin.GET("/rpc/slo", adminHandler.ProviderSLO)
+
+			chain := admin.Group("/rpc/chains/:chain_id")
+			chain.GET("/nodes", adminHandler.ListNodes)
+			chain.POST("/nodes", adminHandler.AddNode)
+			chain.DELETE("/nodes/:name", adminHandler.RemoveNode)
+			chain.POST("/nodes/:name/drain", adminHandler.Drain)
+			chain.DELETE("/nodes/:name/drain", adminHandler.Undrain)
+			chain.POST("/nodes/:name/check", adminHandler.CheckNode)
+			chain.POST("/check", adminHandler.CheckChain)
 		}
+	} else if global.Log != nil {
+		global.Log.Info("🔒 [Admin] server.admin.token not set, admin API disabled")
 	}
 	
 	return r
 }
+
+// newMetricsHandler 独立的 Registry，避免与第三方库注册到默认 Registry 的指标冲突
+func newMetricsHandler(dataModule *data.Data) http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	if rpcMgr := dataModule.GetRPCManager(); rpcMgr != nil {
+		registry.MustRegister(rpcMgr)
+	}
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/zy99978455-otw/go-micro-template/internal/biz"
+)
+
+// TxHandler 交易与回执查询
+type TxHandler struct {
+	uc *biz.ChainUsecase
+}
+
+// NewTxHandler 构造函数
+func NewTxHandler(uc *biz.ChainUsecase) *TxHandler {
+	return &TxHandler{uc: uc}
+}
+
+// GetTransaction 处理 GET /api/v1/web3/tx/:hash?chain_id=
+// 返回交易、回执 (未上链时为空)、确认数和按 ABI 解码的日志
+func (h *TxHandler) GetTransaction(c *gin.Context) {
+	tx, err := h.uc.GetTransaction(c.Request.Context(), queryChainID(c), c.Param("hash"))
+	if err != nil {
+		web3Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": tx,
+	})
+}
+
+// GetReceipt 处理 GET /api/v1/web3/tx/:hash/receipt?chain_id=
+func (h *TxHandler) GetReceipt(c *gin.Context) {
+	receipt, err := h.uc.GetReceipt(c.Request.Context(), queryChainID(c), c.Param("hash"))
+	if err != nil {
+		web3Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 200,
+		"data": receipt,
+	})
+}
+
+// queryChainID 解析 ?chain_id=，默认 1
+func queryChainID(c *gin.Context) int64 {
+	chainID, _ := strconv.ParseInt(c.Query("chain_id"), 10, 64)
+	if chainID == 0 {
+		chainID = 1
+	}
+	return chainID
+}
@@ -1,10 +1,17 @@
 package config
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 // ServerConfig 服务端通用配置
 type ServerConfig struct {
 	Name        string      `mapstructure:"name" json:"name"`
 	Mode        string      `mapstructure:"mode" json:"mode"`
 	Port        int         `mapstructure:"port" json:"port"`
+	GrpcPort    int         `mapstructure:"grpc_port" json:"grpc_port"` // gRPC 端口 (0 = 不启动 gRPC 服务)
 	Version     string      `mapstructure:"version" json:"version"`
 	
 	// 手动指定注册 IP (解决 Docker 网络隔离问题)
@@ -12,6 +19,9 @@ type ServerConfig struct {
 
 	JwtInfo     JwtConfig   `mapstructure:"jwt" json:"jwt"`
 	ConsulInfo  ConsulConfig `mapstructure:"consul" json:"consul"`
+
+	// 管理接口 (/admin)，未配置 token 时不开放
+	Admin       AdminConfig  `mapstructure:"admin" json:"admin"`
 }
 // ================= Web2 基础设施 =================
 
@@ -32,11 +42,35 @@ type RedisConfig struct {
 	DB       int    `mapstructure:"db" json:"db"`
 }
 
+// CacheConfig 链数据缓存 (Redis)
+// 不可变数据 (按哈希的区块、已最终确认的区块/回执、合约代码、Token 元数据) 长期缓存，
+// 依赖链头的数据 (区块高度、未最终确认的数据) 短期缓存，重组时失效
+type CacheConfig struct {
+	Disable   bool          `mapstructure:"disable" json:"disable"`
+	Prefix    string        `mapstructure:"prefix" json:"prefix"`         // key 前缀 (默认 "chain")
+	HeadTTL   time.Duration `mapstructure:"head_ttl" json:"head_ttl"`     // 区块高度 / finalized 高度 (默认 2s)
+	RecentTTL time.Duration `mapstructure:"recent_ttl" json:"recent_ttl"` // 未最终确认的数据 (默认 12s)
+	TTL       time.Duration `mapstructure:"ttl" json:"ttl"`               // 不可变数据 (默认 168h)
+}
+
+// NodeHistoryConfig 节点健康历史 (MySQL)
+// 定期把每个节点的健康、延迟分位数和错误数写入 rpc_node_snapshots，用于按服务商统计可用率和延迟 SLO
+type NodeHistoryConfig struct {
+	Disable   bool          `mapstructure:"disable" json:"disable"`
+	Interval  time.Duration `mapstructure:"interval" json:"interval"`   // 快照间隔 (默认 1m)
+	Retention time.Duration `mapstructure:"retention" json:"retention"` // 保留时长，过期的快照自动清理 (默认 2160h = 90 天)
+}
+
 type ConsulConfig struct {
 	Host string `mapstructure:"host" json:"host"`
 	Port int    `mapstructure:"port" json:"port"`
 }
 
+// AdminConfig 管理接口配置
+type AdminConfig struct {
+	Token string `mapstructure:"token" json:"-"` // 请求头 Authorization: Bearer <token> 或 X-Admin-Token
+}
+
 type JwtConfig struct {
 	SigningKey string `mapstructure:"signing_key" json:"signing_key"`
 	Expire     int64  `mapstructure:"expire" json:"expire"` // 过期时间(秒)
@@ -48,16 +82,226 @@ type JwtConfig struct {
 type ChainConfig struct {
 	ChainID   int64  `mapstructure:"chain_id" json:"chain_id"`     // 链ID
 	ChainName string `mapstructure:"chain_name" json:"chain_name"` // 链名称 (e.g. "eth_mainnet", "bsc_testnet")
-	RpcUrl    string `mapstructure:"rpc_url" json:"rpc_url"`       // HTTP RPC 地址
-	WssUrl    string `mapstructure:"wss_url" json:"wss_url"`       // WebSocket 地址 (监听事件用)
-	ApiKey    string `mapstructure:"api_key" json:"api_key"`       // 如果用 Infura/Alchemy 需要 Key
+	RpcUrl    string `mapstructure:"rpc_url" json:"rpc_url"`       // HTTP RPC 地址 (单节点简写，配置了 nodes 时忽略)
+	WssUrl    string `mapstructure:"wss_url" json:"wss_url"`       // WebSocket 地址 (监听事件用，单节点简写)
+	ApiKey    string `mapstructure:"api_key" json:"api_key"`       // 如果用 Infura/Alchemy 需要 Key (nodes 未单独配置时继承)
+	Symbol    string `mapstructure:"symbol" json:"symbol"`         // 原生币符号 (默认 ETH)
+
+	// 创世区块哈希 (可选)：配置后启动/重连时会校验节点的 0 号区块
+	GenesisHash string `mapstructure:"genesis_hash" json:"genesis_hash"`
+
+	// 多节点配置：同一条链下的多个 RPC 端点 (主备 + 权重)
+	Nodes []NodeConfig `mapstructure:"nodes" json:"nodes"`
+	// 节点选择策略: latency (默认) / weighted_round_robin / least_inflight / highest_block / p2c_ewma
+	Balancer string `mapstructure:"balancer" json:"balancer"`
+	// 故障转移时最多尝试的节点数 (默认 3)
+	MaxAttempts int `mapstructure:"max_attempts" json:"max_attempts"`
+
+	// 落后检测：节点高度落后全链最高高度超过 max_block_lag 即降级 (0 = 默认值)
+	MaxBlockLag uint64 `mapstructure:"max_block_lag" json:"max_block_lag"`
+	// 节点高度连续 stall_intervals 次检查不增长 (且落后于最高高度) 即降级 (0 = 默认值)
+	StallIntervals int `mapstructure:"stall_intervals" json:"stall_intervals"`
+
+	// 节点级熔断器参数
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker" json:"circuit_breaker"`
+	// 健康检查策略
+	HealthCheck HealthCheckConfig `mapstructure:"health_check" json:"health_check"`
+	// JSON-RPC 批量与请求合并
+	Batch BatchConfig `mapstructure:"batch" json:"batch"`
+	// 对冲请求 (读请求慢时向第二个节点再发一次，取先返回的)
+	Hedging HedgingConfig `mapstructure:"hedging" json:"hedging"`
+	// 仲裁读 (同一调用发给多个独立服务商，多数一致才接受)
+	Quorum QuorumConfig `mapstructure:"quorum" json:"quorum"`
+	// 链头跟踪与重组检测
+	HeadTracker HeadTrackerConfig `mapstructure:"head_tracker" json:"head_tracker"`
+	// 确认策略 (safe / finalized / N 确认高度)
+	Finality FinalityConfig `mapstructure:"finality" json:"finality"`
+}
+
+// FinalityConfig 确认策略
+// 默认使用节点的 safe / finalized 标签，节点不支持时退化为 latest - confirmations
+type FinalityConfig struct {
+	Confirmations uint64 `mapstructure:"confirmations" json:"confirmations"` // 确认数 (默认 12)
+	DisableTags   bool   `mapstructure:"disable_tags" json:"disable_tags"`   // 链没有 safe / finalized 标签 (PoW 或侧链)：直接按确认数推算
+}
+
+// HeadTrackerConfig 链头跟踪配置
+// 有 wss_url 时订阅 newHeads，否则按 poll_interval 轮询最新区块头
+type HeadTrackerConfig struct {
+	Disable      bool          `mapstructure:"disable" json:"disable"`
+	Window       int           `mapstructure:"window" json:"window"`               // 保留最近多少个区块的哈希 (默认 128，决定能检测到的最大重组深度)
+	PollInterval time.Duration `mapstructure:"poll_interval" json:"poll_interval"` // 没有 WSS 时的轮询间隔 (默认 3s)
+}
+
+// QuorumConfig 仲裁读配置
+type QuorumConfig struct {
+	Nodes          int  `mapstructure:"nodes" json:"nodes"`                     // 参与仲裁的节点数 N (每个服务商最多一个，默认 3)
+	Required       int  `mapstructure:"required" json:"required"`               // 至少 M 个一致才接受 (默认 N/2+1)
+	DisablePenalty bool `mapstructure:"disable_penalty" json:"disable_penalty"` // 不惩罚与多数不一致的节点 (默认计入熔断失败)
+}
+
+// HedgingConfig 对冲请求配置 (默认关闭)
+type HedgingConfig struct {
+	Enable        bool          `mapstructure:"enable" json:"enable"`
+	Percentile    float64       `mapstructure:"percentile" json:"percentile"`         // 按方法统计的延迟分位数作为对冲延迟 (默认 0.95)
+	MinDelay      time.Duration `mapstructure:"min_delay" json:"min_delay"`           // 对冲延迟下限 (默认 10ms)
+	MaxDelay      time.Duration `mapstructure:"max_delay" json:"max_delay"`           // 对冲延迟上限，样本不足时也用它 (默认 1s)
+	BudgetPercent float64       `mapstructure:"budget_percent" json:"budget_percent"` // 对冲请求最多占总请求的百分比 (默认 10)
+	Methods       []string      `mapstructure:"methods" json:"methods"`               // 允许对冲的 JSON-RPC 方法 (为空表示所有读方法)
+}
+
+// BatchConfig JSON-RPC 批量配置 (默认开启)
+type BatchConfig struct {
+	Disable         bool          `mapstructure:"disable" json:"disable"`                   // 关闭攒批
+	DisableCoalesce bool          `mapstructure:"disable_coalesce" json:"disable_coalesce"` // 关闭相同请求合并
+	Window          time.Duration `mapstructure:"window" json:"window"`                     // 攒批窗口 (默认 2ms)
+	MaxSize         int           `mapstructure:"max_size" json:"max_size"`                 // 单批上限 (默认 20，节点可用 max_batch_size 覆盖)
+}
+
+// HealthCheckConfig 健康检查配置 (0 值字段使用默认值)
+type HealthCheckConfig struct {
+	Interval     time.Duration `mapstructure:"interval" json:"interval"`           // 检查间隔 (默认 30s)
+	Timeout      time.Duration `mapstructure:"timeout" json:"timeout"`             // 单次检查超时 (默认 5s)
+	Jitter       time.Duration `mapstructure:"jitter" json:"jitter"`               // 每次间隔额外加 [0, jitter) 的随机抖动
+	InitialProbe *bool         `mapstructure:"initial_probe" json:"initial_probe"` // 启动时立即检查一次 (默认 true)
+	Probe        string        `mapstructure:"probe" json:"probe"`                 // block_number (默认) / syncing / peer_count / custom
+	Method       string        `mapstructure:"method" json:"method"`               // probe = custom 时调用的 JSON-RPC 方法
+	Params       []interface{} `mapstructure:"params" json:"params"`               // probe = custom 时的参数
+	MinPeers     uint64        `mapstructure:"min_peers" json:"min_peers"`         // probe = peer_count 时的最少对等节点数 (默认 1)
+}
+
+// CircuitBreakerConfig 熔断器配置 (0 值字段使用默认值)
+type CircuitBreakerConfig struct {
+	ConsecutiveFailures int           `mapstructure:"consecutive_failures" json:"consecutive_failures"` // 连续失败多少次熔断 (默认 5)
+	ErrorRate           float64       `mapstructure:"error_rate" json:"error_rate"`                     // 窗口内错误率阈值 (默认 0.5)
+	MinRequests         int           `mapstructure:"min_requests" json:"min_requests"`                 // 计算错误率的最少样本数 (默认 20)
+	WindowSize          int           `mapstructure:"window_size" json:"window_size"`                   // 滑动窗口大小 (默认 100)
+	OpenTimeout         time.Duration `mapstructure:"open_timeout" json:"open_timeout"`                 // 首次熔断冷却时间 (默认 10s)
+	MaxOpenTimeout      time.Duration `mapstructure:"max_open_timeout" json:"max_open_timeout"`         // 冷却时间上限 (默认 5m)
+	HalfOpenRequests    int           `mapstructure:"half_open_requests" json:"half_open_requests"`     // 半开状态试探请求数 (默认 3)
+}
+
+// 节点优先级
+const (
+	NodePriorityPrimary = "primary" // 主节点：优先使用
+	NodePriorityBackup  = "backup"  // 备用节点：主节点全部不可用时才使用
+)
+
+// ApiKeyPlaceholder URL 中的 Key 占位符，例如 "https://mainnet.infura.io/v3/{api_key}"
+const ApiKeyPlaceholder = "{api_key}"
+
+// NodeConfig 单个 RPC 端点配置
+type NodeConfig struct {
+	Name     string            `mapstructure:"name" json:"name"`         // 节点名称 (同链内唯一，为空时自动生成)
+	RpcUrl   string            `mapstructure:"rpc_url" json:"rpc_url"`   // HTTP RPC 地址
+	WssUrl   string            `mapstructure:"wss_url" json:"wss_url"`   // WebSocket 地址 (SubscriptionManager 使用，多个时自动切换)
+	ApiKey   string            `mapstructure:"api_key" json:"api_key"`   // 节点独立的 Key
+	Weight   int               `mapstructure:"weight" json:"weight"`     // 权重 (默认 1)
+	Priority string            `mapstructure:"priority" json:"priority"` // primary / backup (默认 primary)
+	Labels   map[string]string `mapstructure:"labels" json:"labels"`     // 自定义标签 (e.g. provider: infura)
+
+	MaxBatchSize int `mapstructure:"max_batch_size" json:"max_batch_size"` // 服务商的 batch 上限 (1 = 不支持 batch)
+
+	// 服务商限流与额度
+	RateLimit RateLimitConfig `mapstructure:"rate_limit" json:"rate_limit"`
+	// 节点能力 (未声明的项在连接时自动探测)
+	Capabilities CapabilitiesConfig `mapstructure:"capabilities" json:"capabilities"`
+}
+
+// CapabilitiesConfig 节点能力声明 (nil 表示自动探测)
+type CapabilitiesConfig struct {
+	Archive     *bool  `mapstructure:"archive" json:"archive"`             // 归档节点 (可查询任意历史状态)
+	Trace       *bool  `mapstructure:"trace" json:"trace"`                 // 开放 trace_* 命名空间
+	Debug       *bool  `mapstructure:"debug" json:"debug"`                 // 开放 debug_* 命名空间
+	MaxLogRange uint64 `mapstructure:"max_log_range" json:"max_log_range"` // eth_getLogs 单次最大区块跨度 (0 = 不限)
+}
+
+// RateLimitConfig 节点限流与计费配置 (0 值表示不限)
+// CU 用量保存在 Redis (rpc:quota:{chain_id}:{node}:{日期/月份})，重启后继续累计，同名节点在多个实例间共享额度；
+// 未配置 Redis 时只在内存中计数，重启后从 0 开始
+type RateLimitConfig struct {
+	RPS           float64           `mapstructure:"rps" json:"rps"`                       // 令牌桶速率 (请求/秒)
+	Burst         int               `mapstructure:"burst" json:"burst"`                   // 令牌桶容量 (默认 rps+1)
+	DailyBudget   uint64            `mapstructure:"daily_budget" json:"daily_budget"`     // 每日 CU 额度 (UTC)
+	MonthlyBudget uint64            `mapstructure:"monthly_budget" json:"monthly_budget"` // 每月 CU 额度 (UTC)
+	DefaultCost   uint64            `mapstructure:"default_cost" json:"default_cost"`     // 未列出方法的 CU (默认 1)
+	MethodCosts   map[string]uint64 `mapstructure:"method_costs" json:"method_costs"`     // 方法 -> CU (key 不区分大小写)
+}
+
+// Endpoints 返回该链的全部节点配置 (已填充默认值)
+// 兼容旧配置：没有配置 nodes 时，用 rpc_url / wss_url / api_key 生成一个主节点
+func (c ChainConfig) Endpoints() []NodeConfig {
+	nodes := c.Nodes
+	if len(nodes) == 0 && c.RpcUrl != "" {
+		nodes = []NodeConfig{{RpcUrl: c.RpcUrl, WssUrl: c.WssUrl}}
+	}
+
+	endpoints := make([]NodeConfig, 0, len(nodes))
+	for i, n := range nodes {
+		if n.Name == "" {
+			n.Name = fmt.Sprintf("%s-%d", c.displayName(), i)
+		}
+		if n.ApiKey == "" {
+			n.ApiKey = c.ApiKey
+		}
+		if n.Weight <= 0 {
+			n.Weight = 1
+		}
+		// 只认 backup，其余一律视为主节点
+		if strings.ToLower(n.Priority) == NodePriorityBackup {
+			n.Priority = NodePriorityBackup
+		} else {
+			n.Priority = NodePriorityPrimary
+		}
+		endpoints = append(endpoints, n)
+	}
+	return endpoints
+}
+
+// Validate 启动时校验链配置：同一条链内节点名称 (含自动生成的名称) 不能重复
+// 节点名称是熔断、额度 (Redis key)、管理接口和指标的标识，重名会互相覆盖
+func (c ChainConfig) Validate() error {
+	seen := make(map[string]bool)
+	for _, n := range c.Endpoints() {
+		if seen[n.Name] {
+			return fmt.Errorf("chain %d: duplicate node name %q", c.ChainID, n.Name)
+		}
+		seen[n.Name] = true
+	}
+	return nil
+}
+
+func (c ChainConfig) displayName() string {
+	if c.ChainName != "" {
+		return c.ChainName
+	}
+	return fmt.Sprintf("chain%d", c.ChainID)
+}
+
+// ResolvedRpcUrl 返回替换了 Key 占位符的 HTTP 地址
+func (n NodeConfig) ResolvedRpcUrl() string {
+	return strings.ReplaceAll(n.RpcUrl, ApiKeyPlaceholder, n.ApiKey)
+}
+
+// ResolvedWssUrl 返回替换了 Key 占位符的 WebSocket 地址
+func (n NodeConfig) ResolvedWssUrl() string {
+	return strings.ReplaceAll(n.WssUrl, ApiKeyPlaceholder, n.ApiKey)
 }
 
 // ContractConfig 智能合约配置 (DApp 常用)
+// 同一协议在不同链上地址不同时用 deployments 按链配置；address 作为未单独配置的链的默认地址
 type ContractConfig struct {
-	Name    string `mapstructure:"name" json:"name"`       // 合约别名
-	Address string `mapstructure:"address" json:"address"` // 合约地址
-	AbiJson string `mapstructure:"abi_json" json:"abi_json"` // ABI 内容或路径
+	Name        string                     `mapstructure:"name" json:"name"`               // 合约别名
+	Address     string                     `mapstructure:"address" json:"address"`         // 默认合约地址 (可选)
+	AbiJson     string                     `mapstructure:"abi_json" json:"abi_json"`       // ABI 内容或路径
+	Deployments []ContractDeploymentConfig `mapstructure:"deployments" json:"deployments"` // 按链的部署信息
+}
+
+// ContractDeploymentConfig 合约在某条链上的部署
+type ContractDeploymentConfig struct {
+	ChainID     int64  `mapstructure:"chain_id" json:"chain_id"`
+	Address     string `mapstructure:"address" json:"address"`
+	DeployBlock uint64 `mapstructure:"deploy_block" json:"deploy_block"` // 部署区块 (可选，事件扫描的起点)
 }
 
 // ================= 总入口 =================
@@ -66,6 +310,8 @@ type AppConfig struct {
 	Server   ServerConfig   `mapstructure:"server" json:"server"`
 	Mysql    MysqlConfig    `mapstructure:"mysql" json:"mysql"`
 	Redis    RedisConfig    `mapstructure:"redis" json:"redis"`
+	Cache    CacheConfig    `mapstructure:"cache" json:"cache"`
+	NodeHistory NodeHistoryConfig `mapstructure:"node_history" json:"node_history"`
 	
 	// Web3 特有：支持配置多个链 (例如同时监听 ETH 和 BSC)
 	Chains   []ChainConfig  `mapstructure:"chains" json:"chains"`
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChainConfigEndpoints(t *testing.T) {
+	tests := []struct {
+		name  string
+		chain ChainConfig
+		want  []NodeConfig
+	}{
+		{
+			name:  "no nodes and no rpc_url",
+			chain: ChainConfig{ChainID: 1},
+			want:  []NodeConfig{},
+		},
+		{
+			name:  "legacy single node from rpc_url",
+			chain: ChainConfig{ChainID: 1, ChainName: "eth_mainnet", RpcUrl: "https://rpc", WssUrl: "wss://rpc", ApiKey: "k"},
+			want:  []NodeConfig{{Name: "eth_mainnet-0", RpcUrl: "https://rpc", WssUrl: "wss://rpc", ApiKey: "k", Weight: 1, Priority: NodePriorityPrimary}},
+		},
+		{
+			name:  "generated name falls back to chain id",
+			chain: ChainConfig{ChainID: 56, RpcUrl: "https://bsc"},
+			want:  []NodeConfig{{Name: "chain56-0", RpcUrl: "https://bsc", Weight: 1, Priority: NodePriorityPrimary}},
+		},
+		{
+			name: "nodes ignore rpc_url and inherit api_key",
+			chain: ChainConfig{ChainID: 1, ChainName: "eth", RpcUrl: "https://ignored", ApiKey: "chain-key", Nodes: []NodeConfig{
+				{Name: "infura", RpcUrl: "https://a", Weight: 5, Priority: "BACKUP"},
+				{RpcUrl: "https://b", ApiKey: "own-key", Weight: -1, Priority: "weird"},
+			}},
+			want: []NodeConfig{
+				{Name: "infura", RpcUrl: "https://a", ApiKey: "chain-key", Weight: 5, Priority: NodePriorityBackup},
+				{Name: "eth-1", RpcUrl: "https://b", ApiKey: "own-key", Weight: 1, Priority: NodePriorityPrimary},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.chain.Endpoints(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Endpoints() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChainConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		chain   ChainConfig
+		wantErr bool
+	}{
+		{"legacy single node", ChainConfig{ChainID: 1, RpcUrl: "https://rpc"}, false},
+		{"unique names", ChainConfig{ChainID: 1, Nodes: []NodeConfig{{Name: "a", RpcUrl: "https://a"}, {Name: "b", RpcUrl: "https://b"}}}, false},
+		{"duplicate names", ChainConfig{ChainID: 1, Nodes: []NodeConfig{{Name: "a", RpcUrl: "https://a"}, {Name: "a", RpcUrl: "https://b"}}}, true},
+		{"explicit name collides with generated one", ChainConfig{ChainID: 1, ChainName: "eth", Nodes: []NodeConfig{{Name: "eth-1", RpcUrl: "https://a"}, {RpcUrl: "https://b"}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.chain.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNodeConfigResolvedUrls(t *testing.T) {
+	n := NodeConfig{RpcUrl: "https://mainnet.infura.io/v3/" + ApiKeyPlaceholder, WssUrl: "wss://mainnet.infura.io/ws/v3/" + ApiKeyPlaceholder, ApiKey: "abc"}
+	if got := n.ResolvedRpcUrl(); got != "https://mainnet.infura.io/v3/abc" {
+		t.Errorf("ResolvedRpcUrl() = %s", got)
+	}
+	if got := n.ResolvedWssUrl(); got != "wss://mainnet.infura.io/ws/v3/abc" {
+		t.Errorf("ResolvedWssUrl() = %s", got)
+	}
+}
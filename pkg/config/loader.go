@@ -26,6 +26,12 @@ func NewConfig(path string) (*AppConfig, error) {
 
 	// 🔥 删掉了 global.AppConfig = &conf
 
+	for _, chain := range conf.Chains {
+		if err := chain.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+	}
+
 	fmt.Printf("✅ 配置加载成功! App Name: %s, Port: %d\n", conf.Server.Name, conf.Server.Port)
 	if len(conf.Chains) > 0 {
 		fmt.Printf(">>> 监测到 Web3 配置: 已加载 %d 条链信息 (ChainID: %d)\n", len(conf.Chains), conf.Chains[0].ChainID)
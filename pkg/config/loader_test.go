@@ -0,0 +1,56 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewConfigRejectsDuplicateNodeNames(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name: "unique node names",
+			yaml: `
+chains:
+  - chain_id: 1
+    nodes:
+      - { name: a, rpc_url: "https://a" }
+      - { name: b, rpc_url: "https://b" }
+`,
+		},
+		{
+			name: "duplicate node names",
+			yaml: `
+chains:
+  - chain_id: 1
+    nodes:
+      - { name: a, rpc_url: "https://a" }
+      - { name: a, rpc_url: "https://b" }
+`,
+			wantErr: `duplicate node name "a"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := NewConfig(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("NewConfig() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}